@@ -26,27 +26,9 @@ var File_aclgate_v1_openapi_proto protoreflect.FileDescriptor
 const file_aclgate_v1_openapi_proto_rawDesc = "" +
 	"\n" +
 	"\x18aclgate/v1/openapi.proto\x12\n" +
-	"aclgate.v1\x1a.protoc-gen-openapiv2/options/annotations.protoB\xc3\t\x92A\xb6\b\x12\xbb\x04\n" +
-	"\fACL Gate API\x12\x9e\x03Access Control Management API\n" +
-	"\n" +
-	"This API provides features for managing and verifying user permissions for resources.\n" +
-	"\n" +
-	"## Main Features\n" +
-	"- Single/Bulk permission check\n" +
-	"- Grant/Revoke/Update permissions\n" +
-	"- List permissions\n" +
-	"- Audit log query\n" +
-	"\n" +
-	"## Authentication\n" +
-	"All API calls require a valid JWT token.\n" +
-	"\n" +
-	"## Error Codes\n" +
-	"- 400: Bad Request\n" +
-	"- 401: Unauthorized\n" +
-	"- 403: Forbidden\n" +
-	"- 404: Not Found\n" +
-	"- 500: Internal Server Error\"O\n" +
-	"\x14ACL Gate API Support\x12!https://github.com/carped99/gosdk\x1a\x14support@carped99.com*2\n" +
+	"aclgate.v1\x1a.protoc-gen-openapiv2/options/annotations.protoB\xd6\x06\x92A\xc9\x05\x12\xce\x01\n" +
+	"\fACL Gate API\x12UThis API provides features for managing and verifying user permissions for resources.\",\n" +
+	"\aACL API\x12!https://github.com/carped99/gosdk*2\n" +
 	"\vMIT License\x12#https://opensource.org/licenses/MIT2\x050.0.4*\x02\x01\x022\x10application/json:\x10application/jsonR:\n" +
 	"\x03400\x123\n" +
 	"\vBad Request\x12$\n" +
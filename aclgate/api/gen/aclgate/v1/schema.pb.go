@@ -9,7 +9,6 @@ package aclgatev1
 import (
 	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
 	_ "github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-openapiv2/options"
-	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -24,6 +23,63 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Consistency preference for read operations
+type ConsistencyPreference int32
+
+const (
+	// Server default (equivalent to MINIMIZE_LATENCY)
+	ConsistencyPreference_CONSISTENCY_PREFERENCE_UNSPECIFIED ConsistencyPreference = 0
+	// Evaluate against the fastest available snapshot, which may be stale
+	ConsistencyPreference_CONSISTENCY_PREFERENCE_MINIMIZE_LATENCY ConsistencyPreference = 1
+	// Evaluate against a snapshot at least as fresh as the given token
+	ConsistencyPreference_CONSISTENCY_PREFERENCE_AT_LEAST_AS_FRESH ConsistencyPreference = 2
+	// Evaluate against the most recent snapshot
+	ConsistencyPreference_CONSISTENCY_PREFERENCE_FULLY_CONSISTENT ConsistencyPreference = 3
+)
+
+// Enum value maps for ConsistencyPreference.
+var (
+	ConsistencyPreference_name = map[int32]string{
+		0: "CONSISTENCY_PREFERENCE_UNSPECIFIED",
+		1: "CONSISTENCY_PREFERENCE_MINIMIZE_LATENCY",
+		2: "CONSISTENCY_PREFERENCE_AT_LEAST_AS_FRESH",
+		3: "CONSISTENCY_PREFERENCE_FULLY_CONSISTENT",
+	}
+	ConsistencyPreference_value = map[string]int32{
+		"CONSISTENCY_PREFERENCE_UNSPECIFIED":       0,
+		"CONSISTENCY_PREFERENCE_MINIMIZE_LATENCY":  1,
+		"CONSISTENCY_PREFERENCE_AT_LEAST_AS_FRESH": 2,
+		"CONSISTENCY_PREFERENCE_FULLY_CONSISTENT":  3,
+	}
+)
+
+func (x ConsistencyPreference) Enum() *ConsistencyPreference {
+	p := new(ConsistencyPreference)
+	*p = x
+	return p
+}
+
+func (x ConsistencyPreference) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ConsistencyPreference) Descriptor() protoreflect.EnumDescriptor {
+	return file_aclgate_v1_schema_proto_enumTypes[0].Descriptor()
+}
+
+func (ConsistencyPreference) Type() protoreflect.EnumType {
+	return &file_aclgate_v1_schema_proto_enumTypes[0]
+}
+
+func (x ConsistencyPreference) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ConsistencyPreference.Descriptor instead.
+func (ConsistencyPreference) EnumDescriptor() ([]byte, []int) {
+	return file_aclgate_v1_schema_proto_rawDescGZIP(), []int{0}
+}
+
 // Subject (user, group, etc.)
 type Subject struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -236,15 +292,69 @@ func (x *Tuple) GetRelation() *Relation {
 	return nil
 }
 
+// Consistency requirement of a read operation
+type Consistency struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Preference    ConsistencyPreference  `protobuf:"varint,1,opt,name=preference,proto3,enum=aclgate.v1.ConsistencyPreference" json:"preference,omitempty"`
+	Token         string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Consistency) Reset() {
+	*x = Consistency{}
+	mi := &file_aclgate_v1_schema_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Consistency) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Consistency) ProtoMessage() {}
+
+func (x *Consistency) ProtoReflect() protoreflect.Message {
+	mi := &file_aclgate_v1_schema_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Consistency.ProtoReflect.Descriptor instead.
+func (*Consistency) Descriptor() ([]byte, []int) {
+	return file_aclgate_v1_schema_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Consistency) GetPreference() ConsistencyPreference {
+	if x != nil {
+		return x.Preference
+	}
+	return ConsistencyPreference_CONSISTENCY_PREFERENCE_UNSPECIFIED
+}
+
+func (x *Consistency) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
 var File_aclgate_v1_schema_proto protoreflect.FileDescriptor
 
 const file_aclgate_v1_schema_proto_rawDesc = "" +
 	"\n" +
 	"\x17aclgate/v1/schema.proto\x12\n" +
-	"aclgate.v1\x1a\x1bbuf/validate/validate.proto\x1a\x1cgoogle/api/annotations.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\"\x8d\x02\n" +
-	"\aSubject\x12]\n" +
-	"\x04type\x18\x01 \x01(\tBI\x92A12/Subject type (e.g., user, group, role, service)\xbaH\x12r\x102\x0e^[^\\s]{1,254}$R\x04type\x12Z\n" +
-	"\x02id\x18\x02 \x01(\tBJ\x92A220Subject identifier (UUID, email, username, etc.)\xbaH\x12r\x102\x0e^[^\\s]{1,256}$R\x02id:G\x92AD\n" +
+	"aclgate.v1\x1a\x1bbuf/validate/validate.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\"\x8c\x02\n" +
+	"\aSubject\x12`\n" +
+	"\x04type\x18\x01 \x01(\tBL\x92A12/Subject type (e.g., user, group, role, service)\xbaH\x15r\x132\x11^[^:#@\\s]{1,254}$R\x04type\x12V\n" +
+	"\x02id\x18\x02 \x01(\tBF\x92A220Subject identifier (UUID, email, username, etc.)\xbaH\x0er\f2\n" +
+	"^[^#:\\s]+$R\x02id:G\x92AD\n" +
 	"B*\aSubject27Entity that holds permissions (user, group, role, etc.)\"\x81\x02\n" +
 	"\bResource\x12d\n" +
 	"\x04type\x18\x01 \x01(\tBP\x92A523Resource type (e.g., document, database, api, file)\xbaH\x15r\x132\x11^[^:#@\\s]{1,254}$R\x04type\x12R\n" +
@@ -258,7 +368,18 @@ const file_aclgate_v1_schema_proto_rawDesc = "" +
 	"\asubject\x18\x01 \x01(\v2\x13.aclgate.v1.SubjectB5\x92A,2\x1eSubject holding the permission\xd2\x01\x04type\xd2\x01\x02id\xbaH\x03\xc8\x01\x01R\asubject\x12q\n" +
 	"\bresource\x18\x02 \x01(\v2\x14.aclgate.v1.ResourceB?\x92A62(Resource to which the permission applies\xd2\x01\x04type\xd2\x01\x02id\xbaH\x03\xc8\x01\x01R\bresource\x12W\n" +
 	"\brelation\x18\x03 \x01(\v2\x14.aclgate.v1.RelationB%\x92A\x1c2\x13Permission relation\xd2\x01\x04name\xbaH\x03\xc8\x01\x01R\brelation:e\x92Ab\n" +
-	"`*\x10Permission Tuple2LDefinition of permission as a combination of subject, resource, and relationB\x88\x01\n" +
+	"`*\x10Permission Tuple2LDefinition of permission as a combination of subject, resource, and relation\"\xb3\x02\n" +
+	"\vConsistency\x12\x82\x01\n" +
+	"\n" +
+	"preference\x18\x01 \x01(\x0e2!.aclgate.v1.ConsistencyPreferenceB?\x92A422Consistency preference (default: MINIMIZE_LATENCY)\xbaH\x05\x82\x01\x02\x10\x01R\n" +
+	"preference\x12`\n" +
+	"\x05token\x18\x02 \x01(\tBJ\x92AG2EConsistency token returned by Mutate (required for AT_LEAST_AS_FRESH)R\x05token:=\x92A:\n" +
+	"8*\vConsistency2)Freshness requirement of a read operation*\xc7\x01\n" +
+	"\x15ConsistencyPreference\x12&\n" +
+	"\"CONSISTENCY_PREFERENCE_UNSPECIFIED\x10\x00\x12+\n" +
+	"'CONSISTENCY_PREFERENCE_MINIMIZE_LATENCY\x10\x01\x12,\n" +
+	"(CONSISTENCY_PREFERENCE_AT_LEAST_AS_FRESH\x10\x02\x12+\n" +
+	"'CONSISTENCY_PREFERENCE_FULLY_CONSISTENT\x10\x03B\x88\x01\n" +
 	"\x0ecom.aclgate.v1B\vSchemaProtoP\x01Z aclgate/api/aclgate/v1;aclgatev1\xa2\x02\x03AXX\xaa\x02\n" +
 	"Aclgate.V1\xca\x02\n" +
 	"Aclgate\\V1\xe2\x02\x16Aclgate\\V1\\GPBMetadata\xea\x02\vAclgate::V1b\x06proto3"
@@ -275,22 +396,26 @@ func file_aclgate_v1_schema_proto_rawDescGZIP() []byte {
 	return file_aclgate_v1_schema_proto_rawDescData
 }
 
-var file_aclgate_v1_schema_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_aclgate_v1_schema_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_aclgate_v1_schema_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_aclgate_v1_schema_proto_goTypes = []any{
-	(*Subject)(nil),  // 0: aclgate.v1.Subject
-	(*Resource)(nil), // 1: aclgate.v1.Resource
-	(*Relation)(nil), // 2: aclgate.v1.Relation
-	(*Tuple)(nil),    // 3: aclgate.v1.Tuple
+	(ConsistencyPreference)(0), // 0: aclgate.v1.ConsistencyPreference
+	(*Subject)(nil),            // 1: aclgate.v1.Subject
+	(*Resource)(nil),           // 2: aclgate.v1.Resource
+	(*Relation)(nil),           // 3: aclgate.v1.Relation
+	(*Tuple)(nil),              // 4: aclgate.v1.Tuple
+	(*Consistency)(nil),        // 5: aclgate.v1.Consistency
 }
 var file_aclgate_v1_schema_proto_depIdxs = []int32{
-	0, // 0: aclgate.v1.Tuple.subject:type_name -> aclgate.v1.Subject
-	1, // 1: aclgate.v1.Tuple.resource:type_name -> aclgate.v1.Resource
-	2, // 2: aclgate.v1.Tuple.relation:type_name -> aclgate.v1.Relation
-	3, // [3:3] is the sub-list for method output_type
-	3, // [3:3] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	1, // 0: aclgate.v1.Tuple.subject:type_name -> aclgate.v1.Subject
+	2, // 1: aclgate.v1.Tuple.resource:type_name -> aclgate.v1.Resource
+	3, // 2: aclgate.v1.Tuple.relation:type_name -> aclgate.v1.Relation
+	0, // 3: aclgate.v1.Consistency.preference:type_name -> aclgate.v1.ConsistencyPreference
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_aclgate_v1_schema_proto_init() }
@@ -303,13 +428,14 @@ func file_aclgate_v1_schema_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_aclgate_v1_schema_proto_rawDesc), len(file_aclgate_v1_schema_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   4,
+			NumEnums:      1,
+			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_aclgate_v1_schema_proto_goTypes,
 		DependencyIndexes: file_aclgate_v1_schema_proto_depIdxs,
+		EnumInfos:         file_aclgate_v1_schema_proto_enumTypes,
 		MessageInfos:      file_aclgate_v1_schema_proto_msgTypes,
 	}.Build()
 	File_aclgate_v1_schema_proto = out.File
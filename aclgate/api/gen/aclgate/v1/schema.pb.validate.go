@@ -525,3 +525,106 @@ var _ interface {
 	Cause() error
 	ErrorName() string
 } = TupleValidationError{}
+
+// Validate checks the field values on Consistency with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Consistency) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Consistency with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in ConsistencyMultiError, or
+// nil if none found.
+func (m *Consistency) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Consistency) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Preference
+
+	// no validation rules for Token
+
+	if len(errors) > 0 {
+		return ConsistencyMultiError(errors)
+	}
+
+	return nil
+}
+
+// ConsistencyMultiError is an error wrapping multiple validation errors
+// returned by Consistency.ValidateAll() if the designated constraints aren't met.
+type ConsistencyMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ConsistencyMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ConsistencyMultiError) AllErrors() []error { return m }
+
+// ConsistencyValidationError is the validation error returned by
+// Consistency.Validate if the designated constraints aren't met.
+type ConsistencyValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ConsistencyValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ConsistencyValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ConsistencyValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ConsistencyValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ConsistencyValidationError) ErrorName() string { return "ConsistencyValidationError" }
+
+// Error satisfies the builtin error interface
+func (e ConsistencyValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sConsistency.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ConsistencyValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ConsistencyValidationError{}
@@ -25,10 +25,125 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Kind of change applied to a tuple
+type TupleOperation int32
+
+const (
+	// Unknown operation
+	TupleOperation_TUPLE_OPERATION_UNSPECIFIED TupleOperation = 0
+	// Tuple was written
+	TupleOperation_TUPLE_OPERATION_WRITE TupleOperation = 1
+	// Tuple was deleted
+	TupleOperation_TUPLE_OPERATION_DELETE TupleOperation = 2
+)
+
+// Enum value maps for TupleOperation.
+var (
+	TupleOperation_name = map[int32]string{
+		0: "TUPLE_OPERATION_UNSPECIFIED",
+		1: "TUPLE_OPERATION_WRITE",
+		2: "TUPLE_OPERATION_DELETE",
+	}
+	TupleOperation_value = map[string]int32{
+		"TUPLE_OPERATION_UNSPECIFIED": 0,
+		"TUPLE_OPERATION_WRITE":       1,
+		"TUPLE_OPERATION_DELETE":      2,
+	}
+)
+
+func (x TupleOperation) Enum() *TupleOperation {
+	p := new(TupleOperation)
+	*p = x
+	return p
+}
+
+func (x TupleOperation) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TupleOperation) Descriptor() protoreflect.EnumDescriptor {
+	return file_aclgate_v1_service_proto_enumTypes[0].Descriptor()
+}
+
+func (TupleOperation) Type() protoreflect.EnumType {
+	return &file_aclgate_v1_service_proto_enumTypes[0]
+}
+
+func (x TupleOperation) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TupleOperation.Descriptor instead.
+func (TupleOperation) EnumDescriptor() ([]byte, []int) {
+	return file_aclgate_v1_service_proto_rawDescGZIP(), []int{0}
+}
+
+// Kind of node in a userset tree
+type UsersetNodeType int32
+
+const (
+	// Unknown node type
+	UsersetNodeType_USERSET_NODE_TYPE_UNSPECIFIED UsersetNodeType = 0
+	// Userset granted directly to subjects
+	UsersetNodeType_USERSET_NODE_TYPE_LEAF UsersetNodeType = 1
+	// Subjects in any child
+	UsersetNodeType_USERSET_NODE_TYPE_UNION UsersetNodeType = 2
+	// Subjects in every child
+	UsersetNodeType_USERSET_NODE_TYPE_INTERSECTION UsersetNodeType = 3
+	// Subjects in the first child but in none of the others
+	UsersetNodeType_USERSET_NODE_TYPE_EXCLUSION UsersetNodeType = 4
+)
+
+// Enum value maps for UsersetNodeType.
+var (
+	UsersetNodeType_name = map[int32]string{
+		0: "USERSET_NODE_TYPE_UNSPECIFIED",
+		1: "USERSET_NODE_TYPE_LEAF",
+		2: "USERSET_NODE_TYPE_UNION",
+		3: "USERSET_NODE_TYPE_INTERSECTION",
+		4: "USERSET_NODE_TYPE_EXCLUSION",
+	}
+	UsersetNodeType_value = map[string]int32{
+		"USERSET_NODE_TYPE_UNSPECIFIED":  0,
+		"USERSET_NODE_TYPE_LEAF":         1,
+		"USERSET_NODE_TYPE_UNION":        2,
+		"USERSET_NODE_TYPE_INTERSECTION": 3,
+		"USERSET_NODE_TYPE_EXCLUSION":    4,
+	}
+)
+
+func (x UsersetNodeType) Enum() *UsersetNodeType {
+	p := new(UsersetNodeType)
+	*p = x
+	return p
+}
+
+func (x UsersetNodeType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (UsersetNodeType) Descriptor() protoreflect.EnumDescriptor {
+	return file_aclgate_v1_service_proto_enumTypes[1].Descriptor()
+}
+
+func (UsersetNodeType) Type() protoreflect.EnumType {
+	return &file_aclgate_v1_service_proto_enumTypes[1]
+}
+
+func (x UsersetNodeType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use UsersetNodeType.Descriptor instead.
+func (UsersetNodeType) EnumDescriptor() ([]byte, []int) {
+	return file_aclgate_v1_service_proto_rawDescGZIP(), []int{1}
+}
+
 // Single permission check request
 type CheckRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Tuple         *Tuple                 `protobuf:"bytes,1,opt,name=tuple,proto3" json:"tuple,omitempty"`
+	Consistency   *Consistency           `protobuf:"bytes,2,opt,name=consistency,proto3" json:"consistency,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -70,6 +185,13 @@ func (x *CheckRequest) GetTuple() *Tuple {
 	return nil
 }
 
+func (x *CheckRequest) GetConsistency() *Consistency {
+	if x != nil {
+		return x.Consistency
+	}
+	return nil
+}
+
 // Single permission check response
 type CheckResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -127,6 +249,7 @@ func (x *CheckResponse) GetReason() string {
 type BatchCheckRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Items         []*CheckRequest        `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Consistency   *Consistency           `protobuf:"bytes,2,opt,name=consistency,proto3" json:"consistency,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -168,6 +291,13 @@ func (x *BatchCheckRequest) GetItems() []*CheckRequest {
 	return nil
 }
 
+func (x *BatchCheckRequest) GetConsistency() *Consistency {
+	if x != nil {
+		return x.Consistency
+	}
+	return nil
+}
+
 // Bulk permission check response
 type BatchCheckResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -321,10 +451,11 @@ func (x *MutateRequest) GetDeletes() []*Tuple {
 
 // Permission mutation response
 type MutateResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Success          bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	ConsistencyToken string                 `protobuf:"bytes,2,opt,name=consistency_token,json=consistencyToken,proto3" json:"consistency_token,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *MutateResponse) Reset() {
@@ -364,6 +495,13 @@ func (x *MutateResponse) GetSuccess() bool {
 	return false
 }
 
+func (x *MutateResponse) GetConsistencyToken() string {
+	if x != nil {
+		return x.ConsistencyToken
+	}
+	return ""
+}
+
 // Real-time permission check request
 type StreamCheckRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -484,6 +622,7 @@ type ListResourcesRequest struct {
 	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
 	Subject       *Subject               `protobuf:"bytes,2,opt,name=subject,proto3" json:"subject,omitempty"`
 	Relation      *Relation              `protobuf:"bytes,3,opt,name=relation,proto3" json:"relation,omitempty"`
+	Consistency   *Consistency           `protobuf:"bytes,4,opt,name=consistency,proto3" json:"consistency,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -539,6 +678,13 @@ func (x *ListResourcesRequest) GetRelation() *Relation {
 	return nil
 }
 
+func (x *ListResourcesRequest) GetConsistency() *Consistency {
+	if x != nil {
+		return x.Consistency
+	}
+	return nil
+}
+
 // Response for listing accessible resources
 type ListResourcesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -590,6 +736,7 @@ type ListSubjectsRequest struct {
 	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
 	Resource      *Resource              `protobuf:"bytes,2,opt,name=resource,proto3" json:"resource,omitempty"`
 	Relation      *Relation              `protobuf:"bytes,3,opt,name=relation,proto3" json:"relation,omitempty"`
+	Consistency   *Consistency           `protobuf:"bytes,4,opt,name=consistency,proto3" json:"consistency,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -645,6 +792,13 @@ func (x *ListSubjectsRequest) GetRelation() *Relation {
 	return nil
 }
 
+func (x *ListSubjectsRequest) GetConsistency() *Consistency {
+	if x != nil {
+		return x.Consistency
+	}
+	return nil
+}
+
 // Response for listing subjects with access
 type ListSubjectsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -696,7 +850,7 @@ type AuditRequest struct {
 	Resource      *Resource              `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
 	Subject       *Subject               `protobuf:"bytes,2,opt,name=subject,proto3" json:"subject,omitempty"`
 	Relation      *Relation              `protobuf:"bytes,3,opt,name=relation,proto3" json:"relation,omitempty"`
-	PageSize      int32                  `protobuf:"varint,4,opt,name=pageSize,proto3" json:"pageSize,omitempty"`
+	Size          int32                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
 	Cursor        string                 `protobuf:"bytes,5,opt,name=cursor,proto3" json:"cursor,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -753,9 +907,9 @@ func (x *AuditRequest) GetRelation() *Relation {
 	return nil
 }
 
-func (x *AuditRequest) GetPageSize() int32 {
+func (x *AuditRequest) GetSize() int32 {
 	if x != nil {
-		return x.PageSize
+		return x.Size
 	}
 	return 0
 }
@@ -897,21 +1051,389 @@ func (x *AuditResponse) GetLogs() []*AuditLog {
 	return nil
 }
 
+// Request for watching relationship changes
+type WatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ResourceType  string                 `protobuf:"bytes,1,opt,name=resource_type,json=resourceType,proto3" json:"resource_type,omitempty"`
+	Subject       *Subject               `protobuf:"bytes,2,opt,name=subject,proto3" json:"subject,omitempty"`
+	Cursor        string                 `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_aclgate_v1_service_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_aclgate_v1_service_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_aclgate_v1_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *WatchRequest) GetResourceType() string {
+	if x != nil {
+		return x.ResourceType
+	}
+	return ""
+}
+
+func (x *WatchRequest) GetSubject() *Subject {
+	if x != nil {
+		return x.Subject
+	}
+	return nil
+}
+
+func (x *WatchRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+// Single tuple change
+type TupleChange struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Operation        TupleOperation         `protobuf:"varint,1,opt,name=operation,proto3,enum=aclgate.v1.TupleOperation" json:"operation,omitempty"`
+	Tuple            *Tuple                 `protobuf:"bytes,2,opt,name=tuple,proto3" json:"tuple,omitempty"`
+	Timestamp        *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	ConsistencyToken string                 `protobuf:"bytes,4,opt,name=consistency_token,json=consistencyToken,proto3" json:"consistency_token,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *TupleChange) Reset() {
+	*x = TupleChange{}
+	mi := &file_aclgate_v1_service_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TupleChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TupleChange) ProtoMessage() {}
+
+func (x *TupleChange) ProtoReflect() protoreflect.Message {
+	mi := &file_aclgate_v1_service_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TupleChange.ProtoReflect.Descriptor instead.
+func (*TupleChange) Descriptor() ([]byte, []int) {
+	return file_aclgate_v1_service_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *TupleChange) GetOperation() TupleOperation {
+	if x != nil {
+		return x.Operation
+	}
+	return TupleOperation_TUPLE_OPERATION_UNSPECIFIED
+}
+
+func (x *TupleChange) GetTuple() *Tuple {
+	if x != nil {
+		return x.Tuple
+	}
+	return nil
+}
+
+func (x *TupleChange) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *TupleChange) GetConsistencyToken() string {
+	if x != nil {
+		return x.ConsistencyToken
+	}
+	return ""
+}
+
+// Response for watching relationship changes
+type WatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Changes       []*TupleChange         `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+	Cursor        string                 `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchResponse) Reset() {
+	*x = WatchResponse{}
+	mi := &file_aclgate_v1_service_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchResponse) ProtoMessage() {}
+
+func (x *WatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_aclgate_v1_service_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchResponse.ProtoReflect.Descriptor instead.
+func (*WatchResponse) Descriptor() ([]byte, []int) {
+	return file_aclgate_v1_service_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *WatchResponse) GetChanges() []*TupleChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *WatchResponse) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+// Request for expanding a permission
+type ExpandRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Resource      *Resource              `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	Relation      *Relation              `protobuf:"bytes,2,opt,name=relation,proto3" json:"relation,omitempty"`
+	Consistency   *Consistency           `protobuf:"bytes,3,opt,name=consistency,proto3" json:"consistency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExpandRequest) Reset() {
+	*x = ExpandRequest{}
+	mi := &file_aclgate_v1_service_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpandRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpandRequest) ProtoMessage() {}
+
+func (x *ExpandRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_aclgate_v1_service_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpandRequest.ProtoReflect.Descriptor instead.
+func (*ExpandRequest) Descriptor() ([]byte, []int) {
+	return file_aclgate_v1_service_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ExpandRequest) GetResource() *Resource {
+	if x != nil {
+		return x.Resource
+	}
+	return nil
+}
+
+func (x *ExpandRequest) GetRelation() *Relation {
+	if x != nil {
+		return x.Relation
+	}
+	return nil
+}
+
+func (x *ExpandRequest) GetConsistency() *Consistency {
+	if x != nil {
+		return x.Consistency
+	}
+	return nil
+}
+
+// Node of a userset tree
+type UsersetNode struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          UsersetNodeType        `protobuf:"varint,1,opt,name=type,proto3,enum=aclgate.v1.UsersetNodeType" json:"type,omitempty"`
+	Resource      *Resource              `protobuf:"bytes,2,opt,name=resource,proto3" json:"resource,omitempty"`
+	Relation      *Relation              `protobuf:"bytes,3,opt,name=relation,proto3" json:"relation,omitempty"`
+	Subjects      []*Subject             `protobuf:"bytes,4,rep,name=subjects,proto3" json:"subjects,omitempty"`
+	Children      []*UsersetNode         `protobuf:"bytes,5,rep,name=children,proto3" json:"children,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UsersetNode) Reset() {
+	*x = UsersetNode{}
+	mi := &file_aclgate_v1_service_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsersetNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsersetNode) ProtoMessage() {}
+
+func (x *UsersetNode) ProtoReflect() protoreflect.Message {
+	mi := &file_aclgate_v1_service_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsersetNode.ProtoReflect.Descriptor instead.
+func (*UsersetNode) Descriptor() ([]byte, []int) {
+	return file_aclgate_v1_service_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *UsersetNode) GetType() UsersetNodeType {
+	if x != nil {
+		return x.Type
+	}
+	return UsersetNodeType_USERSET_NODE_TYPE_UNSPECIFIED
+}
+
+func (x *UsersetNode) GetResource() *Resource {
+	if x != nil {
+		return x.Resource
+	}
+	return nil
+}
+
+func (x *UsersetNode) GetRelation() *Relation {
+	if x != nil {
+		return x.Relation
+	}
+	return nil
+}
+
+func (x *UsersetNode) GetSubjects() []*Subject {
+	if x != nil {
+		return x.Subjects
+	}
+	return nil
+}
+
+func (x *UsersetNode) GetChildren() []*UsersetNode {
+	if x != nil {
+		return x.Children
+	}
+	return nil
+}
+
+// Response for expanding a permission
+type ExpandResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tree          *UsersetNode           `protobuf:"bytes,1,opt,name=tree,proto3" json:"tree,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExpandResponse) Reset() {
+	*x = ExpandResponse{}
+	mi := &file_aclgate_v1_service_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpandResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpandResponse) ProtoMessage() {}
+
+func (x *ExpandResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_aclgate_v1_service_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpandResponse.ProtoReflect.Descriptor instead.
+func (*ExpandResponse) Descriptor() ([]byte, []int) {
+	return file_aclgate_v1_service_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ExpandResponse) GetTree() *UsersetNode {
+	if x != nil {
+		return x.Tree
+	}
+	return nil
+}
+
 var File_aclgate_v1_service_proto protoreflect.FileDescriptor
 
 const file_aclgate_v1_service_proto_rawDesc = "" +
 	"\n" +
 	"\x18aclgate/v1/service.proto\x12\n" +
-	"aclgate.v1\x1a\x1bbuf/validate/validate.proto\x1a\x1cgoogle/api/annotations.proto\x1a\x17aclgate/v1/schema.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\"\xcf\x01\n" +
+	"aclgate.v1\x1a\x1bbuf/validate/validate.proto\x1a\x1cgoogle/api/annotations.proto\x1a\x17aclgate/v1/schema.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\"\xb3\x02\n" +
 	"\fCheckRequest\x12m\n" +
-	"\x05tuple\x18\x01 \x01(\v2\x11.aclgate.v1.TupleBD\x92A;2\x19Permission tuple to check\xd2\x01\asubject\xd2\x01\bresource\xd2\x01\brelation\xbaH\x03\xc8\x01\x01R\x05tuple:P\x92AM\n" +
+	"\x05tuple\x18\x01 \x01(\v2\x11.aclgate.v1.TupleBD\x92A;2\x19Permission tuple to check\xd2\x01\asubject\xd2\x01\bresource\xd2\x01\brelation\xbaH\x03\xc8\x01\x01R\x05tuple\x12b\n" +
+	"\vconsistency\x18\x02 \x01(\v2\x17.aclgate.v1.ConsistencyB'\x92A$2\"Consistency requirement (optional)R\vconsistency:P\x92AM\n" +
 	"K*\x1fSingle Permission Check Request2(Request for checking a single permission\"\xe6\x01\n" +
 	"\rCheckResponse\x12@\n" +
 	"\aallowed\x18\x01 \x01(\bB&\x92A#2!Whether the permission is grantedR\aallowed\x12G\n" +
 	"\x06reason\x18\x02 \x01(\tB/\x92A,2*Explanation of the permission check resultR\x06reason:J\x92AG\n" +
-	"E* Single Permission Check Response2!Result of single permission check\"\xd2\x01\n" +
+	"E* Single Permission Check Response2!Result of single permission check\"\xcc\x02\n" +
 	"\x11BatchCheckRequest\x12l\n" +
-	"\x05items\x18\x01 \x03(\v2\x18.aclgate.v1.CheckRequestB<\x92A12)List of permission check requests (min 1)\xa0\x01d\xa8\x01\x01\xbaH\x05\x92\x01\x02\b\x01R\x05items:O\x92AL\n" +
+	"\x05items\x18\x01 \x03(\v2\x18.aclgate.v1.CheckRequestB<\x92A12)List of permission check requests (min 1)\xa0\x01d\xa8\x01\x01\xbaH\x05\x92\x01\x02\b\x01R\x05items\x12x\n" +
+	"\vconsistency\x18\x02 \x01(\v2\x17.aclgate.v1.ConsistencyB=\x92A:28Consistency requirement applied to every item (optional)R\vconsistency:O\x92AL\n" +
 	"J*\x1dBulk Permission Check Request2)Request for checking multiple permissions\"\xc9\x01\n" +
 	"\x12BatchCheckResponse\x12e\n" +
 	"\aresults\x18\x01 \x03(\v2\x1c.aclgate.v1.BatchCheckResultB-\x92A*2(Result for each permission check requestR\aresults:L\x92AI\n" +
@@ -923,9 +1445,10 @@ const file_aclgate_v1_service_proto_rawDesc = "" +
 	"\rMutateRequest\x12N\n" +
 	"\x06writes\x18\x01 \x03(\v2\x11.aclgate.v1.TupleB#\x92A 2\x1ePermissions to grant or updateR\x06writes\x12G\n" +
 	"\adeletes\x18\x02 \x03(\v2\x11.aclgate.v1.TupleB\x1a\x92A\x172\x15Permissions to revokeR\adeletes:[\x92AX\n" +
-	"V*\x1bPermission Mutation Request27Request for granting, updating, or revoking permissions\"\xa2\x01\n" +
+	"V*\x1bPermission Mutation Request27Request for granting, updating, or revoking permissions\"\x98\x02\n" +
 	"\x0eMutateResponse\x12B\n" +
-	"\asuccess\x18\x01 \x01(\bB(\x92A%2#Whether the mutation was successfulR\asuccess:L\x92AI\n" +
+	"\asuccess\x18\x01 \x01(\bB(\x92A%2#Whether the mutation was successfulR\asuccess\x12t\n" +
+	"\x11consistency_token\x18\x02 \x01(\tBG\x92AD2BConsistency token identifying the snapshot containing the mutationR\x10consistencyToken:L\x92AI\n" +
 	"G*\x1cPermission Mutation Response2'Result of permission mutation operation\"\x96\x03\n" +
 	"\x12StreamCheckRequest\x12m\n" +
 	"\x05tuple\x18\x01 \x01(\v2\x11.aclgate.v1.TupleBD\x92A;2\x19Permission tuple to check\xd2\x01\asubject\xd2\x01\bresource\xd2\x01\brelation\xbaH\x03\xc8\x01\x01R\x05tuple\x12\x81\x01\n" +
@@ -938,28 +1461,30 @@ const file_aclgate_v1_service_proto_rawDesc = "" +
 	"\aallowed\x18\x01 \x01(\bB&\x92A#2!Whether the permission is grantedR\aallowed\x12G\n" +
 	"\x06reason\x18\x02 \x01(\tB/\x92A,2*Explanation of the permission check resultR\x06reason\x121\n" +
 	"\x05error\x18\x03 \x01(\tB\x1b\x92A\x182\x16Error message (if any)R\x05error:P\x92AM\n" +
-	"K*#Real-time Permission Check Response2$Result of real-time permission check\"\xf9\x02\n" +
+	"K*#Real-time Permission Check Response2$Result of real-time permission check\"\xdd\x03\n" +
 	"\x14ListResourcesRequest\x12U\n" +
 	"\x04type\x18\x01 \x01(\tBA\x92A&2$Type of resource to query (optional)\xbaH\x15r\x132\x11^[^:#@\\s]{1,254}$R\x04type\x12V\n" +
 	"\asubject\x18\x02 \x01(\v2\x13.aclgate.v1.SubjectB'\x92A\x1e2\x10Subject to query\xd2\x01\x04type\xd2\x01\x02id\xbaH\x03\xc8\x01\x01R\asubject\x12`\n" +
-	"\brelation\x18\x03 \x01(\v2\x14.aclgate.v1.RelationB.\x92A%2\x1cPermission relation to query\xd2\x01\x04name\xbaH\x03\xc8\x01\x01R\brelation:P\x92AM\n" +
+	"\brelation\x18\x03 \x01(\v2\x14.aclgate.v1.RelationB.\x92A%2\x1cPermission relation to query\xd2\x01\x04name\xbaH\x03\xc8\x01\x01R\brelation\x12b\n" +
+	"\vconsistency\x18\x04 \x01(\v2\x17.aclgate.v1.ConsistencyB'\x92A$2\"Consistency requirement (optional)R\vconsistency:P\x92AM\n" +
 	"K*\x16List Resources Request21Request to list resources accessible by a subject\"\xbb\x01\n" +
 	"\x15ListResourcesResponse\x12U\n" +
 	"\tresources\x18\x01 \x03(\v2\x14.aclgate.v1.ResourceB!\x92A\x1e2\x1cList of accessible resourcesR\tresources:K\x92AH\n" +
-	"F*\x17List Resources Response2+List of resources accessible by the subject\"\xff\x02\n" +
+	"F*\x17List Resources Response2+List of resources accessible by the subject\"\xe3\x03\n" +
 	"\x13ListSubjectsRequest\x12T\n" +
 	"\x04type\x18\x01 \x01(\tB@\x92A%2#Type of subject to query (optional)\xbaH\x15r\x132\x11^[^:#@\\s]{1,254}$R\x04type\x12Z\n" +
 	"\bresource\x18\x02 \x01(\v2\x14.aclgate.v1.ResourceB(\x92A\x1f2\x11Resource to query\xd2\x01\x04type\xd2\x01\x02id\xbaH\x03\xc8\x01\x01R\bresource\x12`\n" +
-	"\brelation\x18\x03 \x01(\v2\x14.aclgate.v1.RelationB.\x92A%2\x1cPermission relation to query\xd2\x01\x04name\xbaH\x03\xc8\x01\x01R\brelation:T\x92AQ\n" +
+	"\brelation\x18\x03 \x01(\v2\x14.aclgate.v1.RelationB.\x92A%2\x1cPermission relation to query\xd2\x01\x04name\xbaH\x03\xc8\x01\x01R\brelation\x12b\n" +
+	"\vconsistency\x18\x04 \x01(\v2\x17.aclgate.v1.ConsistencyB'\x92A$2\"Consistency requirement (optional)R\vconsistency:T\x92AQ\n" +
 	"O*\x15List Subjects Request26Request to list subjects who have access to a resource\"\xbb\x01\n" +
 	"\x14ListSubjectsResponse\x12R\n" +
 	"\bsubjects\x18\x01 \x03(\v2\x13.aclgate.v1.SubjectB!\x92A\x1e2\x1cList of subjects with accessR\bsubjects:O\x92AL\n" +
-	"J*\x16List Subjects Response20List of subjects who have access to the resource\"\xe7\x03\n" +
+	"J*\x16List Subjects Response20List of subjects who have access to the resource\"\xdf\x03\n" +
 	"\fAuditRequest\x12S\n" +
 	"\bresource\x18\x01 \x01(\v2\x14.aclgate.v1.ResourceB!\x92A\x1e2\x1cResource to query (optional)R\bresource\x12O\n" +
 	"\asubject\x18\x02 \x01(\v2\x13.aclgate.v1.SubjectB \x92A\x1d2\x1bSubject to query (optional)R\asubject\x12^\n" +
-	"\brelation\x18\x03 \x01(\v2\x14.aclgate.v1.RelationB,\x92A)2'Permission relation to query (optional)R\brelation\x12U\n" +
-	"\bpageSize\x18\x04 \x01(\x05B9\x92A62\"Page size (default: 20, max: 1000)Y\x00\x00\x00\x00\x00@\x8f@i\x00\x00\x00\x00\x00\x00\xf0?R\bpageSize\x12.\n" +
+	"\brelation\x18\x03 \x01(\v2\x14.aclgate.v1.RelationB,\x92A)2'Permission relation to query (optional)R\brelation\x12M\n" +
+	"\x04size\x18\x04 \x01(\x05B9\x92A62\"Page size (default: 20, max: 1000)Y\x00\x00\x00\x00\x00@\x8f@i\x00\x00\x00\x00\x00\x00\xf0?R\x04size\x12.\n" +
 	"\x06cursor\x18\x05 \x01(\tB\x16\x92A\x132\x11Pagination cursorR\x06cursor:J\x92AG\n" +
 	"E*\x17Audit Log Query Request2*Request to query permission change history\"\xff\x03\n" +
 	"\bAuditLog\x12>\n" +
@@ -972,7 +1497,47 @@ const file_aclgate_v1_service_proto_rawDesc = "" +
 	"6*\x0fAudit Log Entry2#Entry for permission change history\"\x95\x01\n" +
 	"\rAuditResponse\x12H\n" +
 	"\x04logs\x18\x01 \x03(\v2\x14.aclgate.v1.AuditLogB\x1e\x92A\x1b2\x19List of audit log entriesR\x04logs::\x92A7\n" +
-	"5*\x18Audit Log Query Response2\x19List of audit log entries2\xac\"\n" +
+	"5*\x18Audit Log Query Response2\x19List of audit log entries\"\xe7\x02\n" +
+	"\fWatchRequest\x12^\n" +
+	"\rresource_type\x18\x01 \x01(\tB9\x92A624Only stream changes of this resource type (optional)R\fresourceType\x12b\n" +
+	"\asubject\x18\x02 \x01(\v2\x13.aclgate.v1.SubjectB3\x92A02.Only stream changes of this subject (optional)R\asubject\x12N\n" +
+	"\x06cursor\x18\x03 \x01(\tB6\x92A321Resume after this cursor (optional, default: now)R\x06cursor:C\x92A@\n" +
+	">*\rWatch Request2-Request to stream tuple changes from a cursor\"\xbe\x03\n" +
+	"\vTupleChange\x12]\n" +
+	"\toperation\x18\x01 \x01(\x0e2\x1a.aclgate.v1.TupleOperationB#\x92A 2\x1eOperation applied to the tupleR\toperation\x12O\n" +
+	"\x05tuple\x18\x02 \x01(\v2\x11.aclgate.v1.TupleB&\x92A#2!Permission tuple that was changedR\x05tuple\x12Q\n" +
+	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampB\x17\x92A\x142\x12Time of the changeR\ttimestamp\x12i\n" +
+	"\x11consistency_token\x18\x04 \x01(\tB<\x92A927Consistency token of the snapshot containing the changeR\x10consistencyToken:A\x92A>\n" +
+	"<*\fTuple Change2,Write or delete of a single permission tuple\"\xf9\x01\n" +
+	"\rWatchResponse\x12U\n" +
+	"\achanges\x18\x01 \x03(\v2\x17.aclgate.v1.TupleChangeB\"\x92A\x1f2\x1dTuple changes in commit orderR\achanges\x12D\n" +
+	"\x06cursor\x18\x02 \x01(\tB,\x92A)2'Cursor positioned after the last changeR\x06cursor:K\x92AH\n" +
+	"F*\x0eWatch Response24Batch of tuple changes and the cursor to resume from\"\x8c\x03\n" +
+	"\rExpandRequest\x12[\n" +
+	"\bresource\x18\x01 \x01(\v2\x14.aclgate.v1.ResourceB)\x92A 2\x12Resource to expand\xd2\x01\x04type\xd2\x01\x02id\xbaH\x03\xc8\x01\x01R\bresource\x12a\n" +
+	"\brelation\x18\x02 \x01(\v2\x14.aclgate.v1.RelationB/\x92A&2\x1dPermission relation to expand\xd2\x01\x04name\xbaH\x03\xc8\x01\x01R\brelation\x12b\n" +
+	"\vconsistency\x18\x03 \x01(\v2\x17.aclgate.v1.ConsistencyB'\x92A$2\"Consistency requirement (optional)R\vconsistency:W\x92AT\n" +
+	"R*\x0eExpand Request2@Request to expand a relation on a resource into its userset tree\"\xc6\x04\n" +
+	"\vUsersetNode\x12?\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x1b.aclgate.v1.UsersetNodeTypeB\x0e\x92A\v2\tNode typeR\x04type\x12d\n" +
+	"\bresource\x18\x02 \x01(\v2\x14.aclgate.v1.ResourceB2\x92A/2-Resource of the userset expanded by this nodeR\bresource\x12d\n" +
+	"\brelation\x18\x03 \x01(\v2\x14.aclgate.v1.RelationB2\x92A/2-Relation of the userset expanded by this nodeR\brelation\x12a\n" +
+	"\bsubjects\x18\x04 \x03(\v2\x13.aclgate.v1.SubjectB0\x92A-2+Subjects granted directly (leaf nodes only)R\bsubjects\x12v\n" +
+	"\bchildren\x18\x05 \x03(\v2\x17.aclgate.v1.UsersetNodeBA\x92A>2<Child nodes (for exclusion, the first child is the base set)R\bchildren:O\x92AL\n" +
+	"J*\fUserset Node2:Node of the tree explaining which subjects hold a relation\"\x9b\x01\n" +
+	"\x0eExpandResponse\x12J\n" +
+	"\x04tree\x18\x01 \x01(\v2\x17.aclgate.v1.UsersetNodeB\x1d\x92A\x1a2\x18Root of the userset treeR\x04tree:=\x92A:\n" +
+	"8*\x0fExpand Response2%Userset tree of the expanded relation*h\n" +
+	"\x0eTupleOperation\x12\x1f\n" +
+	"\x1bTUPLE_OPERATION_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15TUPLE_OPERATION_WRITE\x10\x01\x12\x1a\n" +
+	"\x16TUPLE_OPERATION_DELETE\x10\x02*\xb2\x01\n" +
+	"\x0fUsersetNodeType\x12!\n" +
+	"\x1dUSERSET_NODE_TYPE_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16USERSET_NODE_TYPE_LEAF\x10\x01\x12\x1b\n" +
+	"\x17USERSET_NODE_TYPE_UNION\x10\x02\x12\"\n" +
+	"\x1eUSERSET_NODE_TYPE_INTERSECTION\x10\x03\x12\x1f\n" +
+	"\x1bUSERSET_NODE_TYPE_EXCLUSION\x10\x042\xa0/\n" +
 	"\x0eAclGateService\x12\xbf\x04\n" +
 	"\x05Check\x12\x18.aclgate.v1.CheckRequest\x1a\x19.aclgate.v1.CheckResponse\"\x80\x04\x92A\xe6\x03\n" +
 	"\x15Permission Management\x12\x17Single permission check\x1a\xeb\x02Checks if a specific subject has a specific permission on a resource.\n" +
@@ -1118,7 +1683,61 @@ const file_aclgate_v1_service_proto_rawDesc = "" +
 	"```JE\n" +
 	"\x03200\x12>\n" +
 	"\x19Audit log query succeeded\x12!\n" +
-	"\x1f\x1a\x1d#/definitions/v1AuditResponse\x82\xd3\xe4\x93\x02\x10\x12\x0e/acls/v1/audit\x1ao\x92Al\x122Service for permission verification and management\x1a6\n" +
+	"\x1f\x1a\x1d#/definitions/v1AuditResponse\x82\xd3\xe4\x93\x02\x10\x12\x0e/acls/v1/audit\x12\xf5\x05\n" +
+	"\x05Watch\x12\x18.aclgate.v1.WatchRequest\x1a\x19.aclgate.v1.WatchResponse\"\xb4\x05\x92A\x9a\x05\n" +
+	"\x15Permission Management\x12\x1aWatch relationship changes\x1a\xe4\x04Streams tuple write and delete events, optionally filtered by resource type or subject. Each response carries a cursor; pass it back to resume the stream after a reconnect.\n" +
+	"\n" +
+	"## Example\n" +
+	"```\n" +
+	"GET /acls/v1/watch?resourceType=document&cursor=c2VxOjEyMzQ\n" +
+	"```\n" +
+	"\n" +
+	"## Response Example\n" +
+	"```json\n" +
+	"{\n" +
+	"  \"changes\": [\n" +
+	"    {\n" +
+	"      \"operation\": \"TUPLE_OPERATION_WRITE\",\n" +
+	"      \"tuple\": {\n" +
+	"        \"subject\": {\"type\": \"user\", \"id\": \"user123\"},\n" +
+	"        \"resource\": {\"type\": \"document\", \"id\": \"doc123\"},\n" +
+	"        \"relation\": {\"name\": \"can_read\"}\n" +
+	"      },\n" +
+	"      \"timestamp\": \"2024-01-15T10:30:00Z\"\n" +
+	"    }\n" +
+	"  ],\n" +
+	"  \"cursor\": \"c2VxOjEyMzU\"\n" +
+	"}\n" +
+	"```\x82\xd3\xe4\x93\x02\x10\x12\x0e/acls/v1/watch0\x01\x12\xf9\x06\n" +
+	"\x06Expand\x12\x19.aclgate.v1.ExpandRequest\x1a\x1a.aclgate.v1.ExpandResponse\"\xb7\x06\x92A\x9c\x06\n" +
+	"\x15Permission Management\x12\x16Expand permission tree\x1a\x9d\x05Expands a relation on a resource into the userset tree that grants it, showing union, intersection and exclusion nodes down to the leaf subjects.\n" +
+	"\n" +
+	"## Example\n" +
+	"```\n" +
+	"GET /acls/v1/expand?resource.type=document&resource.id=doc123&relation.name=can_read\n" +
+	"```\n" +
+	"\n" +
+	"## Response Example\n" +
+	"```json\n" +
+	"{\n" +
+	"  \"tree\": {\n" +
+	"    \"type\": \"USERSET_NODE_TYPE_UNION\",\n" +
+	"    \"resource\": {\"type\": \"document\", \"id\": \"doc123\"},\n" +
+	"    \"relation\": {\"name\": \"can_read\"},\n" +
+	"    \"children\": [\n" +
+	"      {\n" +
+	"        \"type\": \"USERSET_NODE_TYPE_LEAF\",\n" +
+	"        \"resource\": {\"type\": \"document\", \"id\": \"doc123\"},\n" +
+	"        \"relation\": {\"name\": \"owner\"},\n" +
+	"        \"subjects\": [{\"type\": \"user\", \"id\": \"user123\"}]\n" +
+	"      }\n" +
+	"    ]\n" +
+	"  }\n" +
+	"}\n" +
+	"```JK\n" +
+	"\x03200\x12D\n" +
+	"\x1ePermission expansion succeeded\x12\"\n" +
+	" \x1a\x1e#/definitions/v1ExpandResponse\x82\xd3\xe4\x93\x02\x11\x12\x0f/acls/v1/expand\x1ao\x92Al\x122Service for permission verification and management\x1a6\n" +
 	"\x11GitHub Repository\x12!https://github.com/carped99/gosdkB\x89\x01\n" +
 	"\x0ecom.aclgate.v1B\fServiceProtoP\x01Z aclgate/api/aclgate/v1;aclgatev1\xa2\x02\x03AXX\xaa\x02\n" +
 	"Aclgate.V1\xca\x02\n" +
@@ -1136,71 +1755,103 @@ func file_aclgate_v1_service_proto_rawDescGZIP() []byte {
 	return file_aclgate_v1_service_proto_rawDescData
 }
 
-var file_aclgate_v1_service_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_aclgate_v1_service_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_aclgate_v1_service_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
 var file_aclgate_v1_service_proto_goTypes = []any{
-	(*CheckRequest)(nil),          // 0: aclgate.v1.CheckRequest
-	(*CheckResponse)(nil),         // 1: aclgate.v1.CheckResponse
-	(*BatchCheckRequest)(nil),     // 2: aclgate.v1.BatchCheckRequest
-	(*BatchCheckResponse)(nil),    // 3: aclgate.v1.BatchCheckResponse
-	(*BatchCheckResult)(nil),      // 4: aclgate.v1.BatchCheckResult
-	(*MutateRequest)(nil),         // 5: aclgate.v1.MutateRequest
-	(*MutateResponse)(nil),        // 6: aclgate.v1.MutateResponse
-	(*StreamCheckRequest)(nil),    // 7: aclgate.v1.StreamCheckRequest
-	(*StreamCheckResponse)(nil),   // 8: aclgate.v1.StreamCheckResponse
-	(*ListResourcesRequest)(nil),  // 9: aclgate.v1.ListResourcesRequest
-	(*ListResourcesResponse)(nil), // 10: aclgate.v1.ListResourcesResponse
-	(*ListSubjectsRequest)(nil),   // 11: aclgate.v1.ListSubjectsRequest
-	(*ListSubjectsResponse)(nil),  // 12: aclgate.v1.ListSubjectsResponse
-	(*AuditRequest)(nil),          // 13: aclgate.v1.AuditRequest
-	(*AuditLog)(nil),              // 14: aclgate.v1.AuditLog
-	(*AuditResponse)(nil),         // 15: aclgate.v1.AuditResponse
-	nil,                           // 16: aclgate.v1.StreamCheckRequest.ContextEntry
-	(*Tuple)(nil),                 // 17: aclgate.v1.Tuple
-	(*Subject)(nil),               // 18: aclgate.v1.Subject
-	(*Relation)(nil),              // 19: aclgate.v1.Relation
-	(*Resource)(nil),              // 20: aclgate.v1.Resource
-	(*timestamppb.Timestamp)(nil), // 21: google.protobuf.Timestamp
+	(TupleOperation)(0),           // 0: aclgate.v1.TupleOperation
+	(UsersetNodeType)(0),          // 1: aclgate.v1.UsersetNodeType
+	(*CheckRequest)(nil),          // 2: aclgate.v1.CheckRequest
+	(*CheckResponse)(nil),         // 3: aclgate.v1.CheckResponse
+	(*BatchCheckRequest)(nil),     // 4: aclgate.v1.BatchCheckRequest
+	(*BatchCheckResponse)(nil),    // 5: aclgate.v1.BatchCheckResponse
+	(*BatchCheckResult)(nil),      // 6: aclgate.v1.BatchCheckResult
+	(*MutateRequest)(nil),         // 7: aclgate.v1.MutateRequest
+	(*MutateResponse)(nil),        // 8: aclgate.v1.MutateResponse
+	(*StreamCheckRequest)(nil),    // 9: aclgate.v1.StreamCheckRequest
+	(*StreamCheckResponse)(nil),   // 10: aclgate.v1.StreamCheckResponse
+	(*ListResourcesRequest)(nil),  // 11: aclgate.v1.ListResourcesRequest
+	(*ListResourcesResponse)(nil), // 12: aclgate.v1.ListResourcesResponse
+	(*ListSubjectsRequest)(nil),   // 13: aclgate.v1.ListSubjectsRequest
+	(*ListSubjectsResponse)(nil),  // 14: aclgate.v1.ListSubjectsResponse
+	(*AuditRequest)(nil),          // 15: aclgate.v1.AuditRequest
+	(*AuditLog)(nil),              // 16: aclgate.v1.AuditLog
+	(*AuditResponse)(nil),         // 17: aclgate.v1.AuditResponse
+	(*WatchRequest)(nil),          // 18: aclgate.v1.WatchRequest
+	(*TupleChange)(nil),           // 19: aclgate.v1.TupleChange
+	(*WatchResponse)(nil),         // 20: aclgate.v1.WatchResponse
+	(*ExpandRequest)(nil),         // 21: aclgate.v1.ExpandRequest
+	(*UsersetNode)(nil),           // 22: aclgate.v1.UsersetNode
+	(*ExpandResponse)(nil),        // 23: aclgate.v1.ExpandResponse
+	nil,                           // 24: aclgate.v1.StreamCheckRequest.ContextEntry
+	(*Tuple)(nil),                 // 25: aclgate.v1.Tuple
+	(*Consistency)(nil),           // 26: aclgate.v1.Consistency
+	(*Subject)(nil),               // 27: aclgate.v1.Subject
+	(*Relation)(nil),              // 28: aclgate.v1.Relation
+	(*Resource)(nil),              // 29: aclgate.v1.Resource
+	(*timestamppb.Timestamp)(nil), // 30: google.protobuf.Timestamp
 }
 var file_aclgate_v1_service_proto_depIdxs = []int32{
-	17, // 0: aclgate.v1.CheckRequest.tuple:type_name -> aclgate.v1.Tuple
-	0,  // 1: aclgate.v1.BatchCheckRequest.items:type_name -> aclgate.v1.CheckRequest
-	4,  // 2: aclgate.v1.BatchCheckResponse.results:type_name -> aclgate.v1.BatchCheckResult
-	0,  // 3: aclgate.v1.BatchCheckResult.request:type_name -> aclgate.v1.CheckRequest
-	17, // 4: aclgate.v1.MutateRequest.writes:type_name -> aclgate.v1.Tuple
-	17, // 5: aclgate.v1.MutateRequest.deletes:type_name -> aclgate.v1.Tuple
-	17, // 6: aclgate.v1.StreamCheckRequest.tuple:type_name -> aclgate.v1.Tuple
-	16, // 7: aclgate.v1.StreamCheckRequest.context:type_name -> aclgate.v1.StreamCheckRequest.ContextEntry
-	18, // 8: aclgate.v1.ListResourcesRequest.subject:type_name -> aclgate.v1.Subject
-	19, // 9: aclgate.v1.ListResourcesRequest.relation:type_name -> aclgate.v1.Relation
-	20, // 10: aclgate.v1.ListResourcesResponse.resources:type_name -> aclgate.v1.Resource
-	20, // 11: aclgate.v1.ListSubjectsRequest.resource:type_name -> aclgate.v1.Resource
-	19, // 12: aclgate.v1.ListSubjectsRequest.relation:type_name -> aclgate.v1.Relation
-	18, // 13: aclgate.v1.ListSubjectsResponse.subjects:type_name -> aclgate.v1.Subject
-	20, // 14: aclgate.v1.AuditRequest.resource:type_name -> aclgate.v1.Resource
-	18, // 15: aclgate.v1.AuditRequest.subject:type_name -> aclgate.v1.Subject
-	19, // 16: aclgate.v1.AuditRequest.relation:type_name -> aclgate.v1.Relation
-	17, // 17: aclgate.v1.AuditLog.tuple:type_name -> aclgate.v1.Tuple
-	21, // 18: aclgate.v1.AuditLog.timestamp:type_name -> google.protobuf.Timestamp
-	14, // 19: aclgate.v1.AuditResponse.logs:type_name -> aclgate.v1.AuditLog
-	0,  // 20: aclgate.v1.AclGateService.Check:input_type -> aclgate.v1.CheckRequest
-	2,  // 21: aclgate.v1.AclGateService.BatchCheck:input_type -> aclgate.v1.BatchCheckRequest
-	5,  // 22: aclgate.v1.AclGateService.Mutate:input_type -> aclgate.v1.MutateRequest
-	7,  // 23: aclgate.v1.AclGateService.StreamCheck:input_type -> aclgate.v1.StreamCheckRequest
-	9,  // 24: aclgate.v1.AclGateService.ListResources:input_type -> aclgate.v1.ListResourcesRequest
-	11, // 25: aclgate.v1.AclGateService.ListSubjects:input_type -> aclgate.v1.ListSubjectsRequest
-	13, // 26: aclgate.v1.AclGateService.Audit:input_type -> aclgate.v1.AuditRequest
-	1,  // 27: aclgate.v1.AclGateService.Check:output_type -> aclgate.v1.CheckResponse
-	3,  // 28: aclgate.v1.AclGateService.BatchCheck:output_type -> aclgate.v1.BatchCheckResponse
-	6,  // 29: aclgate.v1.AclGateService.Mutate:output_type -> aclgate.v1.MutateResponse
-	8,  // 30: aclgate.v1.AclGateService.StreamCheck:output_type -> aclgate.v1.StreamCheckResponse
-	10, // 31: aclgate.v1.AclGateService.ListResources:output_type -> aclgate.v1.ListResourcesResponse
-	12, // 32: aclgate.v1.AclGateService.ListSubjects:output_type -> aclgate.v1.ListSubjectsResponse
-	15, // 33: aclgate.v1.AclGateService.Audit:output_type -> aclgate.v1.AuditResponse
-	27, // [27:34] is the sub-list for method output_type
-	20, // [20:27] is the sub-list for method input_type
-	20, // [20:20] is the sub-list for extension type_name
-	20, // [20:20] is the sub-list for extension extendee
-	0,  // [0:20] is the sub-list for field type_name
+	25, // 0: aclgate.v1.CheckRequest.tuple:type_name -> aclgate.v1.Tuple
+	26, // 1: aclgate.v1.CheckRequest.consistency:type_name -> aclgate.v1.Consistency
+	2,  // 2: aclgate.v1.BatchCheckRequest.items:type_name -> aclgate.v1.CheckRequest
+	26, // 3: aclgate.v1.BatchCheckRequest.consistency:type_name -> aclgate.v1.Consistency
+	6,  // 4: aclgate.v1.BatchCheckResponse.results:type_name -> aclgate.v1.BatchCheckResult
+	2,  // 5: aclgate.v1.BatchCheckResult.request:type_name -> aclgate.v1.CheckRequest
+	25, // 6: aclgate.v1.MutateRequest.writes:type_name -> aclgate.v1.Tuple
+	25, // 7: aclgate.v1.MutateRequest.deletes:type_name -> aclgate.v1.Tuple
+	25, // 8: aclgate.v1.StreamCheckRequest.tuple:type_name -> aclgate.v1.Tuple
+	24, // 9: aclgate.v1.StreamCheckRequest.context:type_name -> aclgate.v1.StreamCheckRequest.ContextEntry
+	27, // 10: aclgate.v1.ListResourcesRequest.subject:type_name -> aclgate.v1.Subject
+	28, // 11: aclgate.v1.ListResourcesRequest.relation:type_name -> aclgate.v1.Relation
+	26, // 12: aclgate.v1.ListResourcesRequest.consistency:type_name -> aclgate.v1.Consistency
+	29, // 13: aclgate.v1.ListResourcesResponse.resources:type_name -> aclgate.v1.Resource
+	29, // 14: aclgate.v1.ListSubjectsRequest.resource:type_name -> aclgate.v1.Resource
+	28, // 15: aclgate.v1.ListSubjectsRequest.relation:type_name -> aclgate.v1.Relation
+	26, // 16: aclgate.v1.ListSubjectsRequest.consistency:type_name -> aclgate.v1.Consistency
+	27, // 17: aclgate.v1.ListSubjectsResponse.subjects:type_name -> aclgate.v1.Subject
+	29, // 18: aclgate.v1.AuditRequest.resource:type_name -> aclgate.v1.Resource
+	27, // 19: aclgate.v1.AuditRequest.subject:type_name -> aclgate.v1.Subject
+	28, // 20: aclgate.v1.AuditRequest.relation:type_name -> aclgate.v1.Relation
+	25, // 21: aclgate.v1.AuditLog.tuple:type_name -> aclgate.v1.Tuple
+	30, // 22: aclgate.v1.AuditLog.timestamp:type_name -> google.protobuf.Timestamp
+	16, // 23: aclgate.v1.AuditResponse.logs:type_name -> aclgate.v1.AuditLog
+	27, // 24: aclgate.v1.WatchRequest.subject:type_name -> aclgate.v1.Subject
+	0,  // 25: aclgate.v1.TupleChange.operation:type_name -> aclgate.v1.TupleOperation
+	25, // 26: aclgate.v1.TupleChange.tuple:type_name -> aclgate.v1.Tuple
+	30, // 27: aclgate.v1.TupleChange.timestamp:type_name -> google.protobuf.Timestamp
+	19, // 28: aclgate.v1.WatchResponse.changes:type_name -> aclgate.v1.TupleChange
+	29, // 29: aclgate.v1.ExpandRequest.resource:type_name -> aclgate.v1.Resource
+	28, // 30: aclgate.v1.ExpandRequest.relation:type_name -> aclgate.v1.Relation
+	26, // 31: aclgate.v1.ExpandRequest.consistency:type_name -> aclgate.v1.Consistency
+	1,  // 32: aclgate.v1.UsersetNode.type:type_name -> aclgate.v1.UsersetNodeType
+	29, // 33: aclgate.v1.UsersetNode.resource:type_name -> aclgate.v1.Resource
+	28, // 34: aclgate.v1.UsersetNode.relation:type_name -> aclgate.v1.Relation
+	27, // 35: aclgate.v1.UsersetNode.subjects:type_name -> aclgate.v1.Subject
+	22, // 36: aclgate.v1.UsersetNode.children:type_name -> aclgate.v1.UsersetNode
+	22, // 37: aclgate.v1.ExpandResponse.tree:type_name -> aclgate.v1.UsersetNode
+	2,  // 38: aclgate.v1.AclGateService.Check:input_type -> aclgate.v1.CheckRequest
+	4,  // 39: aclgate.v1.AclGateService.BatchCheck:input_type -> aclgate.v1.BatchCheckRequest
+	7,  // 40: aclgate.v1.AclGateService.Mutate:input_type -> aclgate.v1.MutateRequest
+	9,  // 41: aclgate.v1.AclGateService.StreamCheck:input_type -> aclgate.v1.StreamCheckRequest
+	11, // 42: aclgate.v1.AclGateService.ListResources:input_type -> aclgate.v1.ListResourcesRequest
+	13, // 43: aclgate.v1.AclGateService.ListSubjects:input_type -> aclgate.v1.ListSubjectsRequest
+	15, // 44: aclgate.v1.AclGateService.Audit:input_type -> aclgate.v1.AuditRequest
+	18, // 45: aclgate.v1.AclGateService.Watch:input_type -> aclgate.v1.WatchRequest
+	21, // 46: aclgate.v1.AclGateService.Expand:input_type -> aclgate.v1.ExpandRequest
+	3,  // 47: aclgate.v1.AclGateService.Check:output_type -> aclgate.v1.CheckResponse
+	5,  // 48: aclgate.v1.AclGateService.BatchCheck:output_type -> aclgate.v1.BatchCheckResponse
+	8,  // 49: aclgate.v1.AclGateService.Mutate:output_type -> aclgate.v1.MutateResponse
+	10, // 50: aclgate.v1.AclGateService.StreamCheck:output_type -> aclgate.v1.StreamCheckResponse
+	12, // 51: aclgate.v1.AclGateService.ListResources:output_type -> aclgate.v1.ListResourcesResponse
+	14, // 52: aclgate.v1.AclGateService.ListSubjects:output_type -> aclgate.v1.ListSubjectsResponse
+	17, // 53: aclgate.v1.AclGateService.Audit:output_type -> aclgate.v1.AuditResponse
+	20, // 54: aclgate.v1.AclGateService.Watch:output_type -> aclgate.v1.WatchResponse
+	23, // 55: aclgate.v1.AclGateService.Expand:output_type -> aclgate.v1.ExpandResponse
+	47, // [47:56] is the sub-list for method output_type
+	38, // [38:47] is the sub-list for method input_type
+	38, // [38:38] is the sub-list for extension type_name
+	38, // [38:38] is the sub-list for extension extendee
+	0,  // [0:38] is the sub-list for field type_name
 }
 
 func init() { file_aclgate_v1_service_proto_init() }
@@ -1214,13 +1865,14 @@ func file_aclgate_v1_service_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_aclgate_v1_service_proto_rawDesc), len(file_aclgate_v1_service_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   17,
+			NumEnums:      2,
+			NumMessages:   23,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_aclgate_v1_service_proto_goTypes,
 		DependencyIndexes: file_aclgate_v1_service_proto_depIdxs,
+		EnumInfos:         file_aclgate_v1_service_proto_enumTypes,
 		MessageInfos:      file_aclgate_v1_service_proto_msgTypes,
 	}.Build()
 	File_aclgate_v1_service_proto = out.File
@@ -26,6 +26,8 @@ const (
 	AclGateService_ListResources_FullMethodName = "/aclgate.v1.AclGateService/ListResources"
 	AclGateService_ListSubjects_FullMethodName  = "/aclgate.v1.AclGateService/ListSubjects"
 	AclGateService_Audit_FullMethodName         = "/aclgate.v1.AclGateService/Audit"
+	AclGateService_Watch_FullMethodName         = "/aclgate.v1.AclGateService/Watch"
+	AclGateService_Expand_FullMethodName        = "/aclgate.v1.AclGateService/Expand"
 )
 
 // AclGateServiceClient is the client API for AclGateService service.
@@ -48,6 +50,10 @@ type AclGateServiceClient interface {
 	ListSubjects(ctx context.Context, in *ListSubjectsRequest, opts ...grpc.CallOption) (*ListSubjectsResponse, error)
 	// Query audit logs
 	Audit(ctx context.Context, in *AuditRequest, opts ...grpc.CallOption) (*AuditResponse, error)
+	// Watch relationship changes
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchResponse], error)
+	// Expand a permission into its userset tree
+	Expand(ctx context.Context, in *ExpandRequest, opts ...grpc.CallOption) (*ExpandResponse, error)
 }
 
 type aclGateServiceClient struct {
@@ -131,6 +137,35 @@ func (c *aclGateServiceClient) Audit(ctx context.Context, in *AuditRequest, opts
 	return out, nil
 }
 
+func (c *aclGateServiceClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AclGateService_ServiceDesc.Streams[1], AclGateService_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, WatchResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AclGateService_WatchClient = grpc.ServerStreamingClient[WatchResponse]
+
+func (c *aclGateServiceClient) Expand(ctx context.Context, in *ExpandRequest, opts ...grpc.CallOption) (*ExpandResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExpandResponse)
+	err := c.cc.Invoke(ctx, AclGateService_Expand_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AclGateServiceServer is the server API for AclGateService service.
 // All implementations must embed UnimplementedAclGateServiceServer
 // for forward compatibility.
@@ -151,6 +186,10 @@ type AclGateServiceServer interface {
 	ListSubjects(context.Context, *ListSubjectsRequest) (*ListSubjectsResponse, error)
 	// Query audit logs
 	Audit(context.Context, *AuditRequest) (*AuditResponse, error)
+	// Watch relationship changes
+	Watch(*WatchRequest, grpc.ServerStreamingServer[WatchResponse]) error
+	// Expand a permission into its userset tree
+	Expand(context.Context, *ExpandRequest) (*ExpandResponse, error)
 	mustEmbedUnimplementedAclGateServiceServer()
 }
 
@@ -182,6 +221,12 @@ func (UnimplementedAclGateServiceServer) ListSubjects(context.Context, *ListSubj
 func (UnimplementedAclGateServiceServer) Audit(context.Context, *AuditRequest) (*AuditResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Audit not implemented")
 }
+func (UnimplementedAclGateServiceServer) Watch(*WatchRequest, grpc.ServerStreamingServer[WatchResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedAclGateServiceServer) Expand(context.Context, *ExpandRequest) (*ExpandResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Expand not implemented")
+}
 func (UnimplementedAclGateServiceServer) mustEmbedUnimplementedAclGateServiceServer() {}
 func (UnimplementedAclGateServiceServer) testEmbeddedByValue()                        {}
 
@@ -318,6 +363,35 @@ func _AclGateService_Audit_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AclGateService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AclGateServiceServer).Watch(m, &grpc.GenericServerStream[WatchRequest, WatchResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AclGateService_WatchServer = grpc.ServerStreamingServer[WatchResponse]
+
+func _AclGateService_Expand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExpandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AclGateServiceServer).Expand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AclGateService_Expand_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AclGateServiceServer).Expand(ctx, req.(*ExpandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AclGateService_ServiceDesc is the grpc.ServiceDesc for AclGateService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -349,6 +423,10 @@ var AclGateService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Audit",
 			Handler:    _AclGateService_Audit_Handler,
 		},
+		{
+			MethodName: "Expand",
+			Handler:    _AclGateService_Expand_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -357,6 +435,11 @@ var AclGateService_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "Watch",
+			Handler:       _AclGateService_Watch_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "aclgate/v1/service.proto",
 }
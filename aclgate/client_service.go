@@ -16,8 +16,8 @@ type ClientService interface {
 	// BatchCheck verifies multiple permissions at once
 	BatchCheck(ctx context.Context, reqs []*CheckRequest) ([]*BatchCheckResult, error)
 
-	// Mutate adds or removes permissions (advanced usage)
-	Mutate(ctx context.Context, writes, deletes []*Tuple) (bool, error)
+	// Mutate adds or removes permissions (advanced usage); the response carries the consistency token
+	Mutate(ctx context.Context, writes, deletes []*Tuple) (*MutateResponse, error)
 
 	// ListResources retrieves resources based on filters
 	ListResources(ctx context.Context, req *ListResourcesRequest) (*ListResourcesResponse, error)
@@ -27,6 +27,12 @@ type ClientService interface {
 
 	// Audit retrieves audit logs based on filters
 	Audit(ctx context.Context, req *AuditRequest) (*AuditResponse, error)
+
+	// Expand returns the userset tree granting a relation on a resource
+	Expand(ctx context.Context, req *ExpandRequest) (*ExpandResponse, error)
+
+	// Watch streams tuple changes matching the filter
+	Watch(ctx context.Context, filter *WatchFilter) (WatchStream, error)
 }
 
 // clientServiceImpl implements the ClientService interface
@@ -36,7 +42,10 @@ type clientServiceImpl struct {
 
 // Check verifying if the given user has the required permission
 func (s *clientServiceImpl) Check(ctx context.Context, req *CheckRequest) (bool, error) {
-	protoReq := &v1.CheckRequest{Tuple: toProtoTuple(req.Tuple)}
+	protoReq := &v1.CheckRequest{
+		Tuple:       toProtoTuple(req.Tuple),
+		Consistency: toProtoConsistency(resolveConsistency(ctx, req.Consistency)),
+	}
 	resp, err := s.client.Check(ctx, protoReq)
 	if err != nil {
 		return false, fmt.Errorf("failed to check permission: %w", err)
@@ -52,10 +61,16 @@ func (s *clientServiceImpl) BatchCheck(ctx context.Context, reqs []*CheckRequest
 
 	items := make([]*v1.CheckRequest, 0, len(reqs))
 	for _, r := range reqs {
-		items = append(items, &v1.CheckRequest{Tuple: toProtoTuple(r.Tuple)})
+		items = append(items, &v1.CheckRequest{
+			Tuple:       toProtoTuple(r.Tuple),
+			Consistency: toProtoConsistency(r.Consistency),
+		})
 	}
 
-	resp, err := s.client.BatchCheck(ctx, &v1.BatchCheckRequest{Items: items})
+	resp, err := s.client.BatchCheck(ctx, &v1.BatchCheckRequest{
+		Items:       items,
+		Consistency: toProtoConsistency(resolveConsistency(ctx, nil)),
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -76,7 +91,7 @@ func (s *clientServiceImpl) BatchCheck(ctx context.Context, reqs []*CheckRequest
 }
 
 // Mutate adds or removes permissions
-func (s *clientServiceImpl) Mutate(ctx context.Context, writes, deletes []*Tuple) (bool, error) {
+func (s *clientServiceImpl) Mutate(ctx context.Context, writes, deletes []*Tuple) (*MutateResponse, error) {
 	protoWrites := make([]*v1.Tuple, 0, len(writes))
 	for _, t := range writes {
 		protoWrites = append(protoWrites, toProtoTuple(t))
@@ -92,9 +107,12 @@ func (s *clientServiceImpl) Mutate(ctx context.Context, writes, deletes []*Tuple
 		Deletes: protoDeletes,
 	})
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	return resp.GetSuccess(), nil
+	return &MutateResponse{
+		Success:          resp.GetSuccess(),
+		ConsistencyToken: resp.GetConsistencyToken(),
+	}, nil
 }
 
 // ListResources retrieves resources based on filters
@@ -104,9 +122,10 @@ func (s *clientServiceImpl) ListResources(ctx context.Context, req *ListResource
 	}
 
 	protoReq := &v1.ListResourcesRequest{
-		Type:     req.Type,
-		Subject:  &v1.Subject{Type: req.Subject.Type, Id: req.Subject.ID},
-		Relation: &v1.Relation{Name: req.Relation.Name},
+		Type:        req.Type,
+		Subject:     &v1.Subject{Type: req.Subject.Type, Id: req.Subject.ID},
+		Relation:    &v1.Relation{Name: req.Relation.Name},
+		Consistency: toProtoConsistency(resolveConsistency(ctx, req.Consistency)),
 	}
 
 	resp, err := s.client.ListResources(ctx, protoReq)
@@ -129,9 +148,10 @@ func (s *clientServiceImpl) ListSubjects(ctx context.Context, req *ListSubjectsR
 	}
 
 	protoReq := &v1.ListSubjectsRequest{
-		Type:     req.Type,
-		Resource: &v1.Resource{Type: req.Resource.Type, Id: req.Resource.ID},
-		Relation: &v1.Relation{Name: req.Relation.Name},
+		Type:        req.Type,
+		Resource:    &v1.Resource{Type: req.Resource.Type, Id: req.Resource.ID},
+		Relation:    &v1.Relation{Name: req.Relation.Name},
+		Consistency: toProtoConsistency(resolveConsistency(ctx, req.Consistency)),
 	}
 
 	resp, err := s.client.ListSubjects(ctx, protoReq)
@@ -157,7 +177,7 @@ func (s *clientServiceImpl) Audit(ctx context.Context, req *AuditRequest) (*Audi
 		Resource: &v1.Resource{Type: req.Resource.Type, Id: req.Resource.ID},
 		Subject:  &v1.Subject{Type: req.Subject.Type, Id: req.Subject.ID},
 		Relation: &v1.Relation{Name: req.Relation.Name},
-		PageSize: req.PageSize,
+		Size:     req.PageSize,
 		Cursor:   req.Cursor,
 	}
 
@@ -185,6 +205,52 @@ func (s *clientServiceImpl) Audit(ctx context.Context, req *AuditRequest) (*Audi
 	return &AuditResponse{Logs: logs}, nil
 }
 
+// Expand returns the userset tree granting a relation on a resource
+func (s *clientServiceImpl) Expand(ctx context.Context, req *ExpandRequest) (*ExpandResponse, error) {
+	if req == nil || req.Resource == nil || req.Relation == nil {
+		return nil, ErrInvalidRequest
+	}
+
+	protoReq := &v1.ExpandRequest{
+		Resource:    &v1.Resource{Type: req.Resource.Type, Id: req.Resource.ID},
+		Relation:    &v1.Relation{Name: req.Relation.Name},
+		Consistency: toProtoConsistency(resolveConsistency(ctx, req.Consistency)),
+	}
+
+	resp, err := s.client.Expand(ctx, protoReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand permission: %w", err)
+	}
+
+	tree, err := toDomainUsersetNode(resp.GetTree())
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert userset tree: %w", err)
+	}
+
+	return &ExpandResponse{Tree: tree}, nil
+}
+
+// Watch streams tuple changes matching the filter
+func (s *clientServiceImpl) Watch(ctx context.Context, filter *WatchFilter) (WatchStream, error) {
+	protoReq := &v1.WatchRequest{}
+	if filter != nil {
+		protoReq.ResourceType = filter.ResourceType
+		protoReq.Cursor = filter.Cursor
+		if filter.Subject != nil {
+			protoReq.Subject = &v1.Subject{Type: filter.Subject.Type, Id: filter.Subject.ID}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := s.client.Watch(ctx, protoReq)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to watch: %w", err)
+	}
+
+	return &watchStream{stream: stream, cancel: cancel}, nil
+}
+
 func NewClientService(cc grpc.ClientConnInterface) (ClientService, error) {
 	client := v1.NewAclGateServiceClient(cc)
 	return &clientServiceImpl{client: client}, nil
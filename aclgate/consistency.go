@@ -0,0 +1,145 @@
+package aclgate
+
+import (
+	"context"
+
+	v1 "github.com/carped99/gosdk/aclgate/api/gen/aclgate/v1"
+)
+
+// ConsistencyTokenHeader is the HTTP header (and lower-cased gRPC metadata key)
+// used to carry a consistency token across service hops
+const ConsistencyTokenHeader = "X-Aclgate-Consistency-Token"
+
+// consistencyTokenMetadataKey is the gRPC metadata form of ConsistencyTokenHeader
+const consistencyTokenMetadataKey = "x-aclgate-consistency-token"
+
+var (
+	// consistencyTokenKey is the key used to store the consistency token in context
+	consistencyTokenKey = contextKey{name: "acl_consistency_token"}
+
+	// consistencyKey is the key used to store the default Consistency in context
+	consistencyKey = contextKey{name: "acl_consistency"}
+)
+
+// ConsistencyPreference selects how fresh the data evaluated by a read must be
+type ConsistencyPreference int
+
+const (
+	// ConsistencyUnspecified leaves the choice to the server (minimize latency)
+	ConsistencyUnspecified ConsistencyPreference = iota
+	// ConsistencyMinimizeLatency evaluates against the fastest available snapshot, which may be stale
+	ConsistencyMinimizeLatency
+	// ConsistencyAtLeastAsFresh evaluates against a snapshot at least as fresh as the token
+	ConsistencyAtLeastAsFresh
+	// ConsistencyFullyConsistent evaluates against the most recent snapshot
+	ConsistencyFullyConsistent
+)
+
+// Consistency represents the consistency requirement of a read operation
+type Consistency struct {
+	Preference ConsistencyPreference
+	Token      string
+}
+
+// MinimizeLatency returns a Consistency that favors latency over freshness
+func MinimizeLatency() *Consistency {
+	return &Consistency{Preference: ConsistencyMinimizeLatency}
+}
+
+// AtLeastAsFresh returns a Consistency that requires a snapshot at least as fresh as token
+func AtLeastAsFresh(token string) *Consistency {
+	return &Consistency{Preference: ConsistencyAtLeastAsFresh, Token: token}
+}
+
+// FullyConsistent returns a Consistency that requires the most recent snapshot
+func FullyConsistent() *Consistency {
+	return &Consistency{Preference: ConsistencyFullyConsistent}
+}
+
+// NewConsistencyTokenContext creates a new context carrying the consistency token.
+// Reads made with the returned context default to AtLeastAsFresh(token).
+func NewConsistencyTokenContext(ctx context.Context, token string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if token == "" {
+		return ctx
+	}
+
+	return context.WithValue(ctx, consistencyTokenKey, token)
+}
+
+// ConsistencyTokenFromContext retrieves the consistency token from the context
+func ConsistencyTokenFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+
+	token, ok := ctx.Value(consistencyTokenKey).(string)
+	return token, ok && token != ""
+}
+
+// NewConsistencyContext creates a new context carrying the default Consistency for reads
+func NewConsistencyContext(ctx context.Context, consistency *Consistency) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if consistency == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, consistencyKey, consistency)
+}
+
+// ConsistencyFromContext retrieves the default Consistency from the context
+func ConsistencyFromContext(ctx context.Context) (*Consistency, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	consistency, ok := ctx.Value(consistencyKey).(*Consistency)
+	return consistency, ok && consistency != nil
+}
+
+// resolveConsistency picks the consistency of a read: the explicit value first,
+// then the Consistency in context, then the consistency token in context
+func resolveConsistency(ctx context.Context, explicit *Consistency) *Consistency {
+	if explicit != nil {
+		return explicit
+	}
+
+	if consistency, ok := ConsistencyFromContext(ctx); ok {
+		return consistency
+	}
+
+	if token, ok := ConsistencyTokenFromContext(ctx); ok {
+		return AtLeastAsFresh(token)
+	}
+
+	return nil
+}
+
+func toProtoConsistency(c *Consistency) *v1.Consistency {
+	if c == nil {
+		return nil
+	}
+
+	var preference v1.ConsistencyPreference
+	switch c.Preference {
+	case ConsistencyMinimizeLatency:
+		preference = v1.ConsistencyPreference_CONSISTENCY_PREFERENCE_MINIMIZE_LATENCY
+	case ConsistencyAtLeastAsFresh:
+		preference = v1.ConsistencyPreference_CONSISTENCY_PREFERENCE_AT_LEAST_AS_FRESH
+	case ConsistencyFullyConsistent:
+		preference = v1.ConsistencyPreference_CONSISTENCY_PREFERENCE_FULLY_CONSISTENT
+	default:
+		preference = v1.ConsistencyPreference_CONSISTENCY_PREFERENCE_UNSPECIFIED
+	}
+
+	return &v1.Consistency{
+		Preference: preference,
+		Token:      c.Token,
+	}
+}
@@ -122,9 +122,14 @@ func BatchCheck(ctx context.Context, reqs []*CheckRequest) ([]*BatchCheckResult,
 
 // Mutate is a helper function to mutate permissions using the service from context
 func Mutate(ctx context.Context, writes, deletes []*Tuple) (bool, error) {
+	return success(MutateWithResponse(ctx, writes, deletes))
+}
+
+// MutateWithResponse is like Mutate, and returns the consistency token of the mutation
+func MutateWithResponse(ctx context.Context, writes, deletes []*Tuple) (*MutateResponse, error) {
 	service, err := FromContext(ctx)
 	if err != nil {
-		return false, fmt.Errorf("failed to get service from context: %w", err)
+		return nil, fmt.Errorf("failed to get service from context: %w", err)
 	}
 	return service.Mutate(ctx, writes, deletes)
 }
@@ -167,29 +172,32 @@ func CanDelete(ctx context.Context, resource *Resource, subject *Subject) (bool,
 
 // Write is a helper function to write permissions using the service from context
 func Write(ctx context.Context, tuples []*Tuple) (bool, error) {
-	service, err := FromContext(ctx)
-	if err != nil {
-		return false, fmt.Errorf("failed to get service from context: %w", err)
-	}
-	return service.Mutate(ctx, tuples, nil)
+	return success(WriteWithResponse(ctx, tuples))
+}
+
+// WriteWithResponse is like Write, and returns the consistency token of the mutation
+func WriteWithResponse(ctx context.Context, tuples []*Tuple) (*MutateResponse, error) {
+	return MutateWithResponse(ctx, tuples, nil)
 }
 
 // Delete is a helper function to delete permissions using the service from context
 func Delete(ctx context.Context, tuples []*Tuple) (bool, error) {
-	service, err := FromContext(ctx)
-	if err != nil {
-		return false, fmt.Errorf("failed to get service from context: %w", err)
-	}
-	return service.Mutate(ctx, nil, tuples)
+	return success(DeleteWithResponse(ctx, tuples))
+}
+
+// DeleteWithResponse is like Delete, and returns the consistency token of the mutation
+func DeleteWithResponse(ctx context.Context, tuples []*Tuple) (*MutateResponse, error) {
+	return MutateWithResponse(ctx, nil, tuples)
 }
 
 // DeleteResource is a helper function to delete a resource using the service from context
 func DeleteResource(ctx context.Context, resource *Resource) (bool, error) {
-	service, err := FromContext(ctx)
-	if err != nil {
-		return false, fmt.Errorf("failed to get service from context: %w", err)
-	}
-	return service.Mutate(ctx, nil, []*Tuple{
+	return success(DeleteResourceWithResponse(ctx, resource))
+}
+
+// DeleteResourceWithResponse is like DeleteResource, and returns the consistency token of the mutation
+func DeleteResourceWithResponse(ctx context.Context, resource *Resource) (*MutateResponse, error) {
+	return MutateWithResponse(ctx, nil, []*Tuple{
 		{
 			Resource: resource,
 			Subject:  nil,
@@ -200,11 +208,12 @@ func DeleteResource(ctx context.Context, resource *Resource) (bool, error) {
 
 // DeleteSubject is a helper function to delete a subject using the service from context
 func DeleteSubject(ctx context.Context, subject *Subject) (bool, error) {
-	service, err := FromContext(ctx)
-	if err != nil {
-		return false, fmt.Errorf("failed to get service from context: %w", err)
-	}
-	return service.Mutate(ctx, nil, []*Tuple{
+	return success(DeleteSubjectWithResponse(ctx, subject))
+}
+
+// DeleteSubjectWithResponse is like DeleteSubject, and returns the consistency token of the mutation
+func DeleteSubjectWithResponse(ctx context.Context, subject *Subject) (*MutateResponse, error) {
+	return MutateWithResponse(ctx, nil, []*Tuple{
 		{
 			Resource: nil,
 			Subject:  subject,
@@ -212,3 +221,11 @@ func DeleteSubject(ctx context.Context, subject *Subject) (bool, error) {
 		},
 	})
 }
+
+// success returns whether a mutation succeeded, for the helpers predating MutateResponse
+func success(resp *MutateResponse, err error) (bool, error) {
+	if err != nil {
+		return false, err
+	}
+	return resp != nil && resp.Success, nil
+}
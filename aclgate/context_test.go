@@ -0,0 +1,113 @@
+package aclgate
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenService answers Mutate with a fixed response and records the tuples
+type tokenService struct {
+	ClientService
+
+	resp    *MutateResponse
+	err     error
+	writes  []*Tuple
+	deletes []*Tuple
+}
+
+func (s *tokenService) Mutate(_ context.Context, writes, deletes []*Tuple) (*MutateResponse, error) {
+	s.writes, s.deletes = writes, deletes
+	return s.resp, s.err
+}
+
+func TestMutateHelpers(t *testing.T) {
+	tuple, err := NewTuple("document", "doc-1", "user", "alice", "viewer")
+	require.NoError(t, err)
+
+	t.Run("helpers report success", func(t *testing.T) {
+		// Given
+		service := &tokenService{resp: &MutateResponse{Success: true, ConsistencyToken: "token-1"}}
+		ctx := NewContext(context.Background(), service)
+
+		// When
+		written, writeErr := Write(ctx, []*Tuple{tuple})
+		deleted, deleteErr := Delete(ctx, []*Tuple{tuple})
+
+		// Then
+		require.NoError(t, writeErr)
+		require.NoError(t, deleteErr)
+		assert.True(t, written)
+		assert.True(t, deleted)
+		assert.Equal(t, []*Tuple{tuple}, service.deletes)
+	})
+
+	t.Run("with response variants return the consistency token", func(t *testing.T) {
+		// Given
+		service := &tokenService{resp: &MutateResponse{Success: true, ConsistencyToken: "token-1"}}
+		ctx := NewContext(context.Background(), service)
+
+		// When
+		resp, err := WriteWithResponse(ctx, []*Tuple{tuple})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, "token-1", resp.ConsistencyToken)
+		assert.Equal(t, []*Tuple{tuple}, service.writes)
+		assert.Empty(t, service.deletes)
+	})
+
+	t.Run("delete resource and subject", func(t *testing.T) {
+		// Given
+		service := &tokenService{resp: &MutateResponse{Success: true}}
+		ctx := NewContext(context.Background(), service)
+
+		// When
+		_, resourceErr := DeleteResource(ctx, tuple.Resource)
+		resource := service.deletes
+		_, subjectErr := DeleteSubjectWithResponse(ctx, tuple.Subject)
+
+		// Then
+		require.NoError(t, resourceErr)
+		require.NoError(t, subjectErr)
+		assert.Equal(t, []*Tuple{{Resource: tuple.Resource}}, resource)
+		assert.Equal(t, []*Tuple{{Subject: tuple.Subject}}, service.deletes)
+	})
+
+	t.Run("mutation not applied", func(t *testing.T) {
+		// Given
+		ctx := NewContext(context.Background(), &tokenService{resp: &MutateResponse{}})
+
+		// When
+		ok, err := Mutate(ctx, []*Tuple{tuple}, nil)
+
+		// Then
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		// Given
+		mutateErr := errors.New("unavailable")
+		ctx := NewContext(context.Background(), &tokenService{err: mutateErr})
+
+		// When
+		ok, err := Mutate(ctx, []*Tuple{tuple}, nil)
+
+		// Then
+		assert.ErrorIs(t, err, mutateErr)
+		assert.False(t, ok)
+	})
+
+	t.Run("no service in context", func(t *testing.T) {
+		// When
+		resp, err := MutateWithResponse(context.Background(), []*Tuple{tuple}, nil)
+
+		// Then
+		assert.ErrorIs(t, err, ErrServiceNotFound)
+		assert.Nil(t, resp)
+	})
+}
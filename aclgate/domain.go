@@ -1,25 +1,28 @@
 package aclgate
 
-import (
-	"regexp"
-)
-
-var (
-	resourceTypeRegex = regexp.MustCompile(`^[^:#@\s]{1,254}$`)
-	resourceIDRegex   = regexp.MustCompile(`^[^#:\s]+$`)
-	subjectTypeRegex  = regexp.MustCompile(`^[^:#@\s]{1,254}$`)
-	subjectIDRegex    = regexp.MustCompile(`^[^:#\s]+$`)
-	relationNameRegex = regexp.MustCompile(`^[^:#@\s]{1,50}$`)
-)
-
 type Tuple struct {
 	Resource *Resource
 	Subject  *Subject
 	Relation *Relation
 }
 
+// Validate reports every invalid field of the tuple as events.ValidationErrors.
+// The rules are shared with the events package.
+func (t *Tuple) Validate() error {
+	return ToEventTuple(t).Validate()
+}
+
 type CheckRequest struct {
-	Tuple *Tuple
+	Tuple       *Tuple
+	Consistency *Consistency
+}
+
+// MutateResponse represents the result of a permission mutation
+type MutateResponse struct {
+	Success bool
+	// ConsistencyToken identifies the snapshot containing the mutation.
+	// Pass it to NewConsistencyTokenContext to read your own writes.
+	ConsistencyToken string
 }
 
 type BatchCheckResult struct {
@@ -28,35 +31,28 @@ type BatchCheckResult struct {
 	Error   error
 }
 
-// NewTuple creates a new Tuple with the given parameters
+// NewTuple creates a new Tuple with the given parameters.
+// All invalid fields are reported together as events.ValidationErrors.
 func NewTuple(resourceType, resourceId, subjectType, subjectId, relationName string) (*Tuple, error) {
-	resource, err := NewResource(resourceType, resourceId)
-	if err != nil {
-		return nil, err
+	tuple := &Tuple{
+		Resource: &Resource{Type: resourceType, ID: resourceId},
+		Subject:  &Subject{Type: subjectType, ID: subjectId},
+		Relation: &Relation{Name: relationName},
 	}
 
-	subject, err := NewSubject(subjectType, subjectId)
-	if err != nil {
+	if err := tuple.Validate(); err != nil {
 		return nil, err
 	}
 
-	relation, err := NewRelation(relationName)
-	if err != nil {
-		return nil, err
-	}
-
-	return &Tuple{
-		Resource: resource,
-		Subject:  subject,
-		Relation: relation,
-	}, nil
+	return tuple, nil
 }
 
 // ListResourcesRequest represents a request to list permissions
 type ListResourcesRequest struct {
-	Type     string
-	Subject  *Subject
-	Relation *Relation
+	Type        string
+	Subject     *Subject
+	Relation    *Relation
+	Consistency *Consistency
 }
 
 type ListResourcesResponse struct {
@@ -64,11 +60,12 @@ type ListResourcesResponse struct {
 }
 
 type ListSubjectsRequest struct {
-	Type     string
-	Resource *Resource
-	Relation *Relation
-	Limit    int32
-	Offset   int32
+	Type        string
+	Resource    *Resource
+	Relation    *Relation
+	Limit       int32
+	Offset      int32
+	Consistency *Consistency
 }
 
 // ListSubjectsResponse represents a response containing a list of permissions
@@ -102,59 +99,44 @@ type AuditLog struct {
 
 // Resource represents a resource in the ACL system
 type Resource struct {
-	Type string
-	ID   string
+	Type string `json:"type"`
+	ID   string `json:"id"`
 }
 
 // Subject represents a subject in the ACL system
 type Subject struct {
-	Type string
-	ID   string
+	Type string `json:"type"`
+	ID   string `json:"id"`
 }
 
 // Relation represents a relation in the ACL system
 type Relation struct {
-	Name string
+	Name string `json:"name"`
 }
 
 // NewResource creates a new Resource
 func NewResource(resourceType, resourceId string) (*Resource, error) {
-	if !resourceTypeRegex.MatchString(resourceType) {
-		return nil, ErrInvalidResourceType
-	}
-
-	if !resourceIDRegex.MatchString(resourceId) {
-		return nil, ErrInvalidResourceId
+	resource := &Resource{Type: resourceType, ID: resourceId}
+	if err := ToEventResource(resource).Validate(); err != nil {
+		return nil, err
 	}
-
-	return &Resource{
-		Type: resourceType,
-		ID:   resourceId,
-	}, nil
+	return resource, nil
 }
 
 // NewSubject creates a new Subject
 func NewSubject(subjectType, subjectId string) (*Subject, error) {
-	if !subjectTypeRegex.MatchString(subjectType) {
-		return nil, ErrInvalidSubjectType
-	}
-
-	if !subjectIDRegex.MatchString(subjectId) {
-		return nil, ErrInvalidSubjectId
+	subject := &Subject{Type: subjectType, ID: subjectId}
+	if err := ToEventSubject(subject).Validate(); err != nil {
+		return nil, err
 	}
-	return &Subject{
-		Type: subjectType,
-		ID:   subjectId,
-	}, nil
+	return subject, nil
 }
 
 // NewRelation creates a new Relation
 func NewRelation(name string) (*Relation, error) {
-	if !relationNameRegex.MatchString(name) {
-		return nil, ErrInvalidRelationName
+	relation := &Relation{Name: name}
+	if err := ToEventRelation(relation).Validate(); err != nil {
+		return nil, err
 	}
-
-	return &Relation{
-		Name: name,
-	}, nil
+	return relation, nil
 }
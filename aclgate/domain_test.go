@@ -0,0 +1,59 @@
+package aclgate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/carped99/gosdk/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTuple(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     [5]string
+		expected []error
+	}{
+		{name: "valid", args: [5]string{"document", "doc-1", "user", "alice@example.com", "viewer"}},
+		{name: "invalid resource type", args: [5]string{"doc@x", "doc-1", "user", "alice", "viewer"}, expected: []error{ErrInvalidResourceType}},
+		{name: "relation too long", args: [5]string{"document", "doc-1", "user", "alice", strings.Repeat("r", 51)}, expected: []error{ErrInvalidRelationName}},
+		{
+			name:     "every field invalid",
+			args:     [5]string{"", "", "", "", ""},
+			expected: []error{ErrInvalidResourceType, ErrInvalidResourceId, ErrInvalidSubjectType, ErrInvalidSubjectId, ErrInvalidRelationName},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			tuple, err := NewTuple(tt.args[0], tt.args[1], tt.args[2], tt.args[3], tt.args[4])
+
+			// Then
+			if tt.expected == nil {
+				require.NoError(t, err)
+				assert.NoError(t, ToEventTuple(tuple).ValidateComplete())
+				return
+			}
+			var errs events.ValidationErrors
+			require.True(t, errors.As(err, &errs))
+			assert.Len(t, errs, len(tt.expected))
+			for _, expected := range tt.expected {
+				assert.ErrorIs(t, err, expected)
+			}
+		})
+	}
+}
+
+func TestEventTupleConversion(t *testing.T) {
+	tuples := []*Tuple{
+		{Resource: &Resource{Type: "document", ID: "doc-1"}, Subject: &Subject{Type: "user", ID: "alice"}, Relation: &Relation{Name: "viewer"}},
+		{Subject: &Subject{Type: "user", ID: "bob"}},
+		nil,
+	}
+
+	assert.Equal(t, tuples, FromEventTuples(ToEventTuples(tuples)))
+	assert.Nil(t, ToEventTuples(nil))
+}
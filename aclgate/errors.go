@@ -3,20 +3,27 @@ package aclgate
 import (
 	"errors"
 	"fmt"
+
+	"github.com/carped99/gosdk/events"
 )
 
 // Common ACL errors
 var (
-	ErrInvalidRequest      = errors.New("invalid request")
-	ErrServiceNotFound     = errors.New("acl service not found in context")
-	ErrPermissionDenied    = errors.New("permission denied")
-	ErrResourceNotFound    = errors.New("resource not found")
-	ErrSubjectNotFound     = errors.New("subject not found")
-	ErrInvalidResourceType = errors.New("invalid resource type")
-	ErrInvalidResourceId   = errors.New("invalid resource id")
-	ErrInvalidSubjectType  = errors.New("invalid subject type")
-	ErrInvalidSubjectId    = errors.New("invalid subject id")
-	ErrInvalidRelationName = errors.New("invalid relation name")
+	ErrInvalidRequest   = errors.New("invalid request")
+	ErrServiceNotFound  = errors.New("acl service not found in context")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrResourceNotFound = errors.New("resource not found")
+	ErrSubjectNotFound  = errors.New("subject not found")
+)
+
+// Validation errors shared with the events package.
+// They are wrapped by the events.FieldError entries of a validation failure.
+var (
+	ErrInvalidResourceType = events.ErrInvalidResourceType
+	ErrInvalidResourceId   = events.ErrInvalidResourceID
+	ErrInvalidSubjectType  = events.ErrInvalidSubjectType
+	ErrInvalidSubjectId    = events.ErrInvalidSubjectID
+	ErrInvalidRelationName = events.ErrInvalidRelationName
 )
 
 // AclError represents an ACL-specific error
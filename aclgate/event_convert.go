@@ -0,0 +1,105 @@
+package aclgate
+
+import (
+	"github.com/carped99/gosdk/events"
+)
+
+// ToEventTuple converts a Tuple to an events.AclTuple; missing parts stay nil
+func ToEventTuple(t *Tuple) *events.AclTuple {
+	if t == nil {
+		return nil
+	}
+
+	return &events.AclTuple{
+		Resource: ToEventResource(t.Resource),
+		Subject:  ToEventSubject(t.Subject),
+		Relation: ToEventRelation(t.Relation),
+	}
+}
+
+// FromEventTuple converts an events.AclTuple to a Tuple; missing parts stay nil
+func FromEventTuple(t *events.AclTuple) *Tuple {
+	if t == nil {
+		return nil
+	}
+
+	return &Tuple{
+		Resource: FromEventResource(t.Resource),
+		Subject:  FromEventSubject(t.Subject),
+		Relation: FromEventRelation(t.Relation),
+	}
+}
+
+// ToEventTuples converts Tuples to events.AclTuples, preserving nil entries
+func ToEventTuples(values []*Tuple) []*events.AclTuple {
+	if values == nil {
+		return nil
+	}
+
+	result := make([]*events.AclTuple, 0, len(values))
+	for _, it := range values {
+		result = append(result, ToEventTuple(it))
+	}
+	return result
+}
+
+// FromEventTuples converts events.AclTuples to Tuples, preserving nil entries
+func FromEventTuples(values []*events.AclTuple) []*Tuple {
+	if values == nil {
+		return nil
+	}
+
+	result := make([]*Tuple, 0, len(values))
+	for _, it := range values {
+		result = append(result, FromEventTuple(it))
+	}
+	return result
+}
+
+// ToEventResource converts a Resource to an events.AclResource
+func ToEventResource(r *Resource) *events.AclResource {
+	if r == nil {
+		return nil
+	}
+	return &events.AclResource{Type: r.Type, ID: r.ID}
+}
+
+// FromEventResource converts an events.AclResource to a Resource
+func FromEventResource(r *events.AclResource) *Resource {
+	if r == nil {
+		return nil
+	}
+	return &Resource{Type: r.Type, ID: r.ID}
+}
+
+// ToEventSubject converts a Subject to an events.AclSubject
+func ToEventSubject(s *Subject) *events.AclSubject {
+	if s == nil {
+		return nil
+	}
+	return &events.AclSubject{Type: s.Type, ID: s.ID}
+}
+
+// FromEventSubject converts an events.AclSubject to a Subject
+func FromEventSubject(s *events.AclSubject) *Subject {
+	if s == nil {
+		return nil
+	}
+	return &Subject{Type: s.Type, ID: s.ID}
+}
+
+// ToEventRelation converts a Relation to an events.AclRelation
+func ToEventRelation(r *Relation) *events.AclRelation {
+	if r == nil {
+		return nil
+	}
+	return &events.AclRelation{Name: r.Name}
+}
+
+// FromEventRelation converts an events.AclRelation to a Relation
+func FromEventRelation(r *events.AclRelation) *Relation {
+	if r == nil {
+		return nil
+	}
+	return &Relation{Name: r.Name}
+}
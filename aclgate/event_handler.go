@@ -0,0 +1,449 @@
+package aclgate
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/carped99/gosdk/events"
+	"github.com/carped99/gosdk/outbox"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrUnsupportedEvent is returned for messages that do not carry an ACL event
+	ErrUnsupportedEvent = errors.New("unsupported acl event")
+	// ErrMutationNotApplied is returned when the gateway reports an unsuccessful mutation
+	ErrMutationNotApplied = errors.New("acl mutation not applied")
+)
+
+const (
+	defaultEventMaxAttempts   = 5
+	defaultEventMinBackoff    = 200 * time.Millisecond
+	defaultEventMaxBackoff    = 10 * time.Second
+	defaultDeduplicationLimit = 10000
+)
+
+// DeduplicationStore remembers the EventIDs of handled messages
+type DeduplicationStore interface {
+	// Claim records the event as handled unless it already was, in one atomic step,
+	// and reports whether it was recorded by this call
+	Claim(ctx context.Context, eventID string) (bool, error)
+
+	// Release forgets a claimed event that could not be handled, so a redelivery handles it
+	Release(ctx context.Context, eventID string) error
+}
+
+// DeadLetterHandler receives messages that could not be applied.
+// Returning nil acknowledges the message so it is not handled again.
+type DeadLetterHandler func(ctx context.Context, msg *outbox.Message, cause error) error
+
+// memoryDeduplicationStore implements DeduplicationStore with a bounded LRU
+type memoryDeduplicationStore struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+// NewMemoryDeduplicationStore creates an in-memory DeduplicationStore remembering
+// the most recent capacity EventIDs
+func NewMemoryDeduplicationStore(capacity int) DeduplicationStore {
+	if capacity <= 0 {
+		capacity = defaultDeduplicationLimit
+	}
+	return &memoryDeduplicationStore{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element, capacity),
+	}
+}
+
+func (s *memoryDeduplicationStore) Claim(_ context.Context, eventID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[eventID]; ok {
+		s.order.MoveToFront(e)
+		return false, nil
+	}
+
+	s.entries[eventID] = s.order.PushFront(eventID)
+	if s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(string))
+	}
+	return true, nil
+}
+
+func (s *memoryDeduplicationStore) Release(_ context.Context, eventID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[eventID]; ok {
+		s.order.Remove(e)
+		delete(s.entries, eventID)
+	}
+	return nil
+}
+
+// eventHandlerConfig holds EventHandler configuration
+type eventHandlerConfig struct {
+	dedup       DeduplicationStore
+	deadLetter  DeadLetterHandler
+	maxAttempts int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	// tupleEventTypes maps the event types of raw events.AclTuples payloads to whether they write
+	tupleEventTypes map[string]bool
+	// cascadeRelations are listed to find the tuples of a deleted resource or subject
+	cascadeRelations []string
+}
+
+// EventHandlerOption configures an EventHandler
+type EventHandlerOption func(*eventHandlerConfig)
+
+// WithDeduplicationStore sets the store used to skip already handled EventIDs
+func WithDeduplicationStore(store DeduplicationStore) EventHandlerOption {
+	return func(c *eventHandlerConfig) {
+		if store != nil {
+			c.dedup = store
+		}
+	}
+}
+
+// WithDeadLetterHandler sets the handler receiving messages that could not be applied
+func WithDeadLetterHandler(handler DeadLetterHandler) EventHandlerOption {
+	return func(c *eventHandlerConfig) {
+		c.deadLetter = handler
+	}
+}
+
+// WithAclTuplesEventTypes applies messages carrying a raw events.AclTuples payload, as
+// published before the typed ACL events: tuples of writeType are written and tuples of
+// deleteType are deleted. An empty type is ignored.
+func WithAclTuplesEventTypes(writeType, deleteType string) EventHandlerOption {
+	return func(c *eventHandlerConfig) {
+		if c.tupleEventTypes == nil {
+			c.tupleEventTypes = make(map[string]bool)
+		}
+		if writeType != "" {
+			c.tupleEventTypes[writeType] = true
+		}
+		if deleteType != "" {
+			c.tupleEventTypes[deleteType] = false
+		}
+	}
+}
+
+// WithCascadeRelations applies AclResourceDeleted and AclSubjectDeleted events by deleting
+// the tuples of the resource or subject with each of the relations.
+// The tuples are found with ListSubjects and ListResources, since Mutate only deletes complete tuples.
+// Without relations, these events are unsupported.
+func WithCascadeRelations(relations ...string) EventHandlerOption {
+	return func(c *eventHandlerConfig) {
+		for _, relation := range relations {
+			if relation != "" {
+				c.cascadeRelations = append(c.cascadeRelations, relation)
+			}
+		}
+	}
+}
+
+// WithMaxAttempts sets how many times a transient failure is attempted before dead-lettering
+func WithMaxAttempts(attempts int) EventHandlerOption {
+	return func(c *eventHandlerConfig) {
+		if attempts > 0 {
+			c.maxAttempts = attempts
+		}
+	}
+}
+
+// WithRetryBackoff sets the exponential backoff bounds between attempts
+func WithRetryBackoff(min, max time.Duration) EventHandlerOption {
+	return func(c *eventHandlerConfig) {
+		if min > 0 {
+			c.minBackoff = min
+		}
+		if max >= c.minBackoff {
+			c.maxBackoff = max
+		}
+	}
+}
+
+// EventHandler applies ACL events published through the outbox to the gateway
+type EventHandler struct {
+	service ClientService
+	config  eventHandlerConfig
+}
+
+// NewEventHandler creates a new EventHandler
+func NewEventHandler(service ClientService, opts ...EventHandlerOption) (*EventHandler, error) {
+	if service == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+
+	config := eventHandlerConfig{
+		maxAttempts: defaultEventMaxAttempts,
+		minBackoff:  defaultEventMinBackoff,
+		maxBackoff:  defaultEventMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.dedup == nil {
+		config.dedup = NewMemoryDeduplicationStore(defaultDeduplicationLimit)
+	}
+
+	return &EventHandler{service: service, config: config}, nil
+}
+
+// Handle applies the ACL event carried by msg.
+// Messages that were already handled, or are being handled, are skipped. Invalid messages
+// and messages still failing after the configured attempts are passed to the dead-letter handler.
+// A message whose error is returned is released, so its redelivery is handled again.
+func (h *EventHandler) Handle(ctx context.Context, msg *outbox.Message) error {
+	if msg == nil {
+		return ErrInvalidRequest
+	}
+
+	claimed, err := h.config.dedup.Claim(ctx, msg.EventID)
+	if err != nil {
+		return fmt.Errorf("failed to claim event %s: %w", msg.EventID, err)
+	}
+	if !claimed {
+		return nil
+	}
+
+	if err := h.handle(ctx, msg); err != nil {
+		if releaseErr := h.config.dedup.Release(context.WithoutCancel(ctx), msg.EventID); releaseErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to release event %s: %w", msg.EventID, releaseErr))
+		}
+		return err
+	}
+	return nil
+}
+
+func (h *EventHandler) handle(ctx context.Context, msg *outbox.Message) error {
+	mutation, err := h.decodeAclEvent(msg)
+	if err == nil {
+		err = h.mutateWithRetry(ctx, mutation)
+	}
+	if err == nil {
+		return nil
+	}
+
+	if ctx.Err() != nil || h.config.deadLetter == nil {
+		return err
+	}
+	if dlqErr := h.config.deadLetter(ctx, msg, err); dlqErr != nil {
+		return fmt.Errorf("failed to dead-letter event %s: %w", msg.EventID, errors.Join(err, dlqErr))
+	}
+	return nil
+}
+
+func (h *EventHandler) mutateWithRetry(ctx context.Context, mutation aclMutation) error {
+	backoff := h.config.minBackoff
+
+	var err error
+	for attempt := 1; attempt <= h.config.maxAttempts; attempt++ {
+		if err = h.mutate(ctx, mutation); err == nil {
+			return nil
+		}
+
+		if !isTransientError(err) || attempt == h.config.maxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > h.config.maxBackoff {
+			backoff = h.config.maxBackoff
+		}
+	}
+	return err
+}
+
+// mutate resolves the tuples of the mutation and applies them
+func (h *EventHandler) mutate(ctx context.Context, mutation aclMutation) error {
+	writes, deletes, err := mutation(ctx)
+	if err != nil {
+		return err
+	}
+	if len(writes) == 0 && len(deletes) == 0 {
+		return nil
+	}
+
+	resp, err := h.service.Mutate(ctx, writes, deletes)
+	if err != nil {
+		return err
+	}
+	if resp == nil || !resp.Success {
+		return ErrMutationNotApplied
+	}
+	return nil
+}
+
+// isTransientError reports whether retrying the mutation may succeed
+func isTransientError(err error) bool {
+	if errors.Is(err, ErrMutationNotApplied) {
+		return true
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+
+	switch st.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// aclMutation resolves the tuples an ACL event writes and deletes.
+// It is called again on each attempt, so tuples read from the gateway are current.
+type aclMutation func(ctx context.Context) (writes, deletes []*Tuple, err error)
+
+// decodeAclEvent translates the ACL event carried by msg into a mutation
+func (h *EventHandler) decodeAclEvent(msg *outbox.Message) (aclMutation, error) {
+	if write, ok := h.config.tupleEventTypes[msg.EventType]; ok {
+		event, err := decodeAclEventPayload[*events.AclTuples](msg)
+		if err != nil {
+			return nil, err
+		}
+		if write {
+			return writeTuples(&events.AclTuplesWritten{Tuples: event.Tuples})
+		}
+		return deleteTuples(&events.AclTuplesDeleted{Tuples: event.Tuples})
+	}
+
+	switch msg.EventType {
+	case events.EventTypeAclTuplesWritten:
+		event, err := decodeAclEventPayload[*events.AclTuplesWritten](msg)
+		if err != nil {
+			return nil, err
+		}
+		return writeTuples(event)
+
+	case events.EventTypeAclTuplesDeleted:
+		event, err := decodeAclEventPayload[*events.AclTuplesDeleted](msg)
+		if err != nil {
+			return nil, err
+		}
+		return deleteTuples(event)
+
+	case events.EventTypeAclResourceDeleted:
+		if len(h.config.cascadeRelations) == 0 {
+			return nil, fmt.Errorf("%w: '%s' requires cascade relations", ErrUnsupportedEvent, msg.EventType)
+		}
+		event, err := decodeAclEventPayload[*events.AclResourceDeleted](msg)
+		if err != nil {
+			return nil, err
+		}
+		if err := event.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+		}
+		return h.deleteResourceTuples(FromEventResource(event.Resource)), nil
+
+	case events.EventTypeAclSubjectDeleted:
+		if len(h.config.cascadeRelations) == 0 {
+			return nil, fmt.Errorf("%w: '%s' requires cascade relations", ErrUnsupportedEvent, msg.EventType)
+		}
+		event, err := decodeAclEventPayload[*events.AclSubjectDeleted](msg)
+		if err != nil {
+			return nil, err
+		}
+		if err := event.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+		}
+		return h.deleteSubjectTuples(FromEventSubject(event.Subject)), nil
+
+	default:
+		return nil, fmt.Errorf("%w: '%s'", ErrUnsupportedEvent, msg.EventType)
+	}
+}
+
+func writeTuples(event *events.AclTuplesWritten) (aclMutation, error) {
+	if err := event.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+	}
+	writes := FromEventTuples(event.Tuples)
+	return func(context.Context) ([]*Tuple, []*Tuple, error) {
+		return writes, nil, nil
+	}, nil
+}
+
+func deleteTuples(event *events.AclTuplesDeleted) (aclMutation, error) {
+	if err := event.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+	}
+	deletes := FromEventTuples(event.Tuples)
+	return func(context.Context) ([]*Tuple, []*Tuple, error) {
+		return nil, deletes, nil
+	}, nil
+}
+
+// deleteResourceTuples deletes the tuples granting each cascade relation on the resource
+func (h *EventHandler) deleteResourceTuples(resource *Resource) aclMutation {
+	return func(ctx context.Context) ([]*Tuple, []*Tuple, error) {
+		var deletes []*Tuple
+		for _, name := range h.config.cascadeRelations {
+			relation := &Relation{Name: name}
+			resp, err := h.service.ListSubjects(ctx, &ListSubjectsRequest{
+				Resource:    resource,
+				Relation:    relation,
+				Consistency: FullyConsistent(),
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, subject := range resp.Subjects {
+				deletes = append(deletes, &Tuple{Resource: resource, Subject: subject, Relation: relation})
+			}
+		}
+		return nil, deletes, nil
+	}
+}
+
+// deleteSubjectTuples deletes the tuples granting the subject each cascade relation
+func (h *EventHandler) deleteSubjectTuples(subject *Subject) aclMutation {
+	return func(ctx context.Context) ([]*Tuple, []*Tuple, error) {
+		var deletes []*Tuple
+		for _, name := range h.config.cascadeRelations {
+			relation := &Relation{Name: name}
+			resp, err := h.service.ListResources(ctx, &ListResourcesRequest{
+				Subject:     subject,
+				Relation:    relation,
+				Consistency: FullyConsistent(),
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, resource := range resp.Resources {
+				deletes = append(deletes, &Tuple{Resource: resource, Subject: subject, Relation: relation})
+			}
+		}
+		return nil, deletes, nil
+	}
+}
+
+func decodeAclEventPayload[E any](msg *outbox.Message) (E, error) {
+	event, err := outbox.DecodePayload[E](msg)
+	if err != nil {
+		return event, fmt.Errorf("%w: failed to decode %s payload: %v", ErrInvalidRequest, msg.EventType, err)
+	}
+	return event, nil
+}
@@ -0,0 +1,329 @@
+package aclgate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/carped99/gosdk/events"
+	"github.com/carped99/gosdk/outbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mutateService records the Mutate calls of an EventHandler
+type mutateService struct {
+	ClientService
+
+	mu      sync.Mutex
+	errs    []error
+	writes  [][]*Tuple
+	deletes [][]*Tuple
+	block   chan struct{}
+
+	subjects  []*Subject
+	resources []*Resource
+	lists     []string
+}
+
+func (s *mutateService) ListSubjects(_ context.Context, req *ListSubjectsRequest) (*ListSubjectsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lists = append(s.lists, req.Relation.Name)
+	return &ListSubjectsResponse{Subjects: s.subjects}, nil
+}
+
+func (s *mutateService) ListResources(_ context.Context, req *ListResourcesRequest) (*ListResourcesResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lists = append(s.lists, req.Relation.Name)
+	return &ListResourcesResponse{Resources: s.resources}, nil
+}
+
+func (s *mutateService) Mutate(_ context.Context, writes, deletes []*Tuple) (*MutateResponse, error) {
+	if s.block != nil {
+		<-s.block
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes = append(s.writes, writes)
+	s.deletes = append(s.deletes, deletes)
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return nil, err
+	}
+	return &MutateResponse{Success: true}, nil
+}
+
+func (s *mutateService) calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.writes)
+}
+
+func newEventTuple() *events.AclTuple {
+	return &events.AclTuple{
+		Resource: &events.AclResource{Type: "document", ID: "doc-1"},
+		Subject:  &events.AclSubject{Type: "user", ID: "alice"},
+		Relation: &events.AclRelation{Name: "viewer"},
+	}
+}
+
+func TestEventHandler_Handle(t *testing.T) {
+	t.Run("writes tuples once per event", func(t *testing.T) {
+		// Given
+		service := &mutateService{}
+		handler, err := NewEventHandler(service)
+		require.NoError(t, err)
+		msg, err := events.NewAclTuplesWrittenMessage(newEventTuple())
+		require.NoError(t, err)
+
+		// When
+		require.NoError(t, handler.Handle(context.Background(), msg))
+		err = handler.Handle(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		require.Equal(t, 1, service.calls())
+		assert.Equal(t, FromEventTuples([]*events.AclTuple{newEventTuple()}), service.writes[0])
+		assert.Empty(t, service.deletes[0])
+	})
+
+	t.Run("concurrent deliveries apply the event once", func(t *testing.T) {
+		// Given
+		service := &mutateService{block: make(chan struct{})}
+		handler, err := NewEventHandler(service)
+		require.NoError(t, err)
+		msg, err := events.NewAclTuplesDeletedMessage(newEventTuple())
+		require.NoError(t, err)
+
+		// When
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, handler.Handle(context.Background(), msg))
+			}()
+		}
+		time.Sleep(20 * time.Millisecond)
+		close(service.block)
+		wg.Wait()
+
+		// Then
+		assert.Equal(t, 1, service.calls())
+	})
+
+	t.Run("retries transient failures", func(t *testing.T) {
+		// Given
+		service := &mutateService{errs: []error{status.Error(codes.Unavailable, "down")}}
+		handler, err := NewEventHandler(service, WithRetryBackoff(time.Millisecond, time.Millisecond))
+		require.NoError(t, err)
+		msg, err := events.NewAclTuplesWrittenMessage(newEventTuple())
+		require.NoError(t, err)
+
+		// When
+		err = handler.Handle(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, 2, service.calls())
+	})
+
+	t.Run("failed event is handled again when redelivered", func(t *testing.T) {
+		// Given
+		service := &mutateService{errs: []error{status.Error(codes.InvalidArgument, "rejected")}}
+		handler, err := NewEventHandler(service)
+		require.NoError(t, err)
+		msg, err := events.NewAclTuplesWrittenMessage(newEventTuple())
+		require.NoError(t, err)
+
+		// When
+		firstErr := handler.Handle(context.Background(), msg)
+		err = handler.Handle(context.Background(), msg)
+
+		// Then
+		assert.Error(t, firstErr)
+		require.NoError(t, err)
+		assert.Equal(t, 2, service.calls())
+	})
+
+	t.Run("raw tuples payload", func(t *testing.T) {
+		// Given
+		service := &mutateService{}
+		handler, err := NewEventHandler(service, WithAclTuplesEventTypes("permission.granted", "permission.revoked"))
+		require.NoError(t, err)
+
+		payload, err := json.Marshal(&events.AclTuples{Tuples: []*events.AclTuple{newEventTuple()}})
+		require.NoError(t, err)
+		newMessage := func(eventType string) *outbox.Message {
+			msg, err := outbox.NewMessageBuilder().
+				SetEventTopic("permissions").
+				SetEventDomain("iam").
+				SetEventType(eventType).
+				SetObjectType(events.ObjectTypeAclTuple).
+				SetPayload(payload).
+				Build()
+			require.NoError(t, err)
+			return msg
+		}
+
+		// When
+		require.NoError(t, handler.Handle(context.Background(), newMessage("permission.granted")))
+		err = handler.Handle(context.Background(), newMessage("permission.revoked"))
+
+		// Then
+		require.NoError(t, err)
+		require.Equal(t, 2, service.calls())
+		assert.Len(t, service.writes[0], 1)
+		assert.Len(t, service.deletes[1], 1)
+	})
+
+	t.Run("resource deleted without cascade relations is dead-lettered", func(t *testing.T) {
+		// Given
+		service := &mutateService{}
+		var deadLettered error
+		handler, err := NewEventHandler(service, WithDeadLetterHandler(func(_ context.Context, _ *outbox.Message, cause error) error {
+			deadLettered = cause
+			return nil
+		}))
+		require.NoError(t, err)
+		msg, err := events.NewAclResourceDeletedMessage(&events.AclResource{Type: "document", ID: "doc-1"})
+		require.NoError(t, err)
+
+		// When
+		err = handler.Handle(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		assert.ErrorIs(t, deadLettered, ErrUnsupportedEvent)
+		assert.Zero(t, service.calls())
+	})
+
+	t.Run("resource deleted deletes the tuples of each cascade relation", func(t *testing.T) {
+		// Given
+		alice, bob := &Subject{Type: "user", ID: "alice"}, &Subject{Type: "user", ID: "bob"}
+		service := &mutateService{subjects: []*Subject{alice, bob}}
+		handler, err := NewEventHandler(service, WithCascadeRelations("viewer", "editor"))
+		require.NoError(t, err)
+		msg, err := events.NewAclResourceDeletedMessage(&events.AclResource{Type: "document", ID: "doc-1"})
+		require.NoError(t, err)
+
+		// When
+		err = handler.Handle(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"viewer", "editor"}, service.lists)
+		require.Equal(t, 1, service.calls())
+		resource := &Resource{Type: "document", ID: "doc-1"}
+		assert.Equal(t, []*Tuple{
+			{Resource: resource, Subject: alice, Relation: &Relation{Name: "viewer"}},
+			{Resource: resource, Subject: bob, Relation: &Relation{Name: "viewer"}},
+			{Resource: resource, Subject: alice, Relation: &Relation{Name: "editor"}},
+			{Resource: resource, Subject: bob, Relation: &Relation{Name: "editor"}},
+		}, service.deletes[0])
+	})
+
+	t.Run("subject deleted deletes the tuples of each cascade relation", func(t *testing.T) {
+		// Given
+		doc := &Resource{Type: "document", ID: "doc-1"}
+		service := &mutateService{resources: []*Resource{doc}}
+		handler, err := NewEventHandler(service, WithCascadeRelations("viewer"))
+		require.NoError(t, err)
+		msg, err := events.NewAclSubjectDeletedMessage(&events.AclSubject{Type: "user", ID: "alice"})
+		require.NoError(t, err)
+
+		// When
+		err = handler.Handle(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		require.Equal(t, 1, service.calls())
+		assert.Equal(t, []*Tuple{
+			{Resource: doc, Subject: &Subject{Type: "user", ID: "alice"}, Relation: &Relation{Name: "viewer"}},
+		}, service.deletes[0])
+	})
+
+	t.Run("deleted subject without tuples is not mutated", func(t *testing.T) {
+		// Given
+		service := &mutateService{}
+		handler, err := NewEventHandler(service, WithCascadeRelations("viewer"))
+		require.NoError(t, err)
+		msg, err := events.NewAclSubjectDeletedMessage(&events.AclSubject{Type: "user", ID: "alice"})
+		require.NoError(t, err)
+
+		// When
+		err = handler.Handle(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		assert.Zero(t, service.calls())
+	})
+
+	t.Run("partial deleted tuple is invalid", func(t *testing.T) {
+		// Given
+		service := &mutateService{}
+		handler, err := NewEventHandler(service, WithAclTuplesEventTypes("", "permission.revoked"))
+		require.NoError(t, err)
+		partial := &events.AclTuple{Subject: &events.AclSubject{Type: "user", ID: "alice"}}
+		_, err = events.NewAclTuplesDeletedMessage(partial)
+		require.ErrorIs(t, err, events.ErrMissingField)
+		msg, err := outbox.NewTypedMessage("permissions", "iam", "permission.revoked", &events.AclTuples{Tuples: []*events.AclTuple{partial}})
+		require.NoError(t, err)
+
+		// When
+		err = handler.Handle(context.Background(), msg)
+
+		// Then
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+		assert.ErrorIs(t, err, events.ErrMissingField)
+		assert.Zero(t, service.calls())
+	})
+
+	t.Run("unsupported event", func(t *testing.T) {
+		// Given
+		handler, err := NewEventHandler(&mutateService{})
+		require.NoError(t, err)
+		msg, err := outbox.NewMessageBuilder().
+			SetEventTopic("orders").
+			SetEventDomain("shop").
+			SetEventType("order.created").
+			SetObjectType("order").
+			Build()
+		require.NoError(t, err)
+
+		// When
+		err = handler.Handle(context.Background(), msg)
+
+		// Then
+		assert.True(t, errors.Is(err, ErrUnsupportedEvent))
+	})
+}
+
+func TestMemoryDeduplicationStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryDeduplicationStore(2)
+
+	claimed, err := store.Claim(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	claimed, _ = store.Claim(ctx, "a")
+	assert.False(t, claimed)
+
+	require.NoError(t, store.Release(ctx, "a"))
+	claimed, _ = store.Claim(ctx, "a")
+	assert.True(t, claimed)
+
+	_, _ = store.Claim(ctx, "b")
+	_, _ = store.Claim(ctx, "c")
+	claimed, _ = store.Claim(ctx, "a")
+	assert.True(t, claimed, "least recently claimed event is evicted")
+}
@@ -0,0 +1,246 @@
+package aclgate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	v1 "github.com/carped99/gosdk/aclgate/api/gen/aclgate/v1"
+)
+
+// UsersetNodeType represents the kind of node in a userset tree
+type UsersetNodeType int
+
+const (
+	UsersetNodeUnspecified UsersetNodeType = iota
+	// UsersetNodeLeaf lists the subjects granted the userset directly
+	UsersetNodeLeaf
+	// UsersetNodeUnion contains the subjects of any child
+	UsersetNodeUnion
+	// UsersetNodeIntersection contains the subjects of every child
+	UsersetNodeIntersection
+	// UsersetNodeExclusion contains the subjects of the first child but of none of the others
+	UsersetNodeExclusion
+)
+
+var usersetNodeTypeNames = map[UsersetNodeType]string{
+	UsersetNodeUnspecified:  "unspecified",
+	UsersetNodeLeaf:         "leaf",
+	UsersetNodeUnion:        "union",
+	UsersetNodeIntersection: "intersection",
+	UsersetNodeExclusion:    "exclusion",
+}
+
+// String returns the name of the node type
+func (t UsersetNodeType) String() string {
+	if name, ok := usersetNodeTypeNames[t]; ok {
+		return name
+	}
+	return usersetNodeTypeNames[UsersetNodeUnspecified]
+}
+
+// MarshalText renders the node type by name
+func (t UsersetNodeType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText parses a node type name
+func (t *UsersetNodeType) UnmarshalText(text []byte) error {
+	for k, name := range usersetNodeTypeNames {
+		if name == string(text) {
+			*t = k
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown userset node type: '%s'", text)
+}
+
+// ExpandRequest represents a request to expand a relation on a resource
+type ExpandRequest struct {
+	Resource    *Resource
+	Relation    *Relation
+	Consistency *Consistency
+}
+
+// ExpandResponse represents the userset tree of an expanded relation
+type ExpandResponse struct {
+	Tree *UsersetNode
+}
+
+// UsersetNode represents a node of a userset tree
+type UsersetNode struct {
+	Type     UsersetNodeType   `json:"type"`
+	Resource *Resource         `json:"resource,omitempty"`
+	Relation *Relation         `json:"relation,omitempty"`
+	Subjects []*UsersetSubject `json:"subjects,omitempty"`
+	Children []*UsersetNode    `json:"children,omitempty"`
+}
+
+// UsersetSubject represents a subject listed by a leaf node: a subject such as
+// "user:alice", or a userset such as "group:eng#member" with its Relation set
+type UsersetSubject struct {
+	Type     string `json:"type"`
+	ID       string `json:"id"`
+	Relation string `json:"relation,omitempty"`
+}
+
+// String returns the "type:id" or "type:id#relation" notation of the subject
+func (s *UsersetSubject) String() string {
+	if s.Relation == "" {
+		return s.Type + ":" + s.ID
+	}
+	return s.Type + ":" + s.ID + "#" + s.Relation
+}
+
+// Userset returns the "type:id#relation" notation of the userset expanded by the node
+func (n *UsersetNode) Userset() string {
+	var b strings.Builder
+	if n.Resource != nil {
+		b.WriteString(n.Resource.Type + ":" + n.Resource.ID)
+	}
+	if n.Relation != nil {
+		b.WriteString("#" + n.Relation.Name)
+	}
+	return b.String()
+}
+
+// String renders the tree for debugging
+func (n *UsersetNode) String() string {
+	var b strings.Builder
+	_ = n.Print(&b)
+	return b.String()
+}
+
+// Print writes the tree to w, one node or subject per line
+func (n *UsersetNode) Print(w io.Writer) error {
+	if n == nil {
+		return nil
+	}
+	if _, err := fmt.Fprintln(w, n.label()); err != nil {
+		return err
+	}
+	return n.printChildren(w, "")
+}
+
+// JSON renders the tree as indented JSON
+func (n *UsersetNode) JSON() ([]byte, error) {
+	return json.MarshalIndent(n, "", "  ")
+}
+
+func (n *UsersetNode) label() string {
+	if userset := n.Userset(); userset != "" {
+		return n.Type.String() + " " + userset
+	}
+	return n.Type.String()
+}
+
+func (n *UsersetNode) printChildren(w io.Writer, prefix string) error {
+	total := len(n.Subjects) + len(n.Children)
+	index := 0
+
+	branch := func() (string, string) {
+		index++
+		if index == total {
+			return prefix + "└── ", prefix + "    "
+		}
+		return prefix + "├── ", prefix + "│   "
+	}
+
+	for _, s := range n.Subjects {
+		line, _ := branch()
+		if _, err := fmt.Fprintln(w, line+s.String()); err != nil {
+			return err
+		}
+	}
+
+	for i, child := range n.Children {
+		line, next := branch()
+		label := child.label()
+		if n.Type == UsersetNodeExclusion && i > 0 {
+			label = "- " + label
+		}
+		if _, err := fmt.Fprintln(w, line+label); err != nil {
+			return err
+		}
+		if err := child.printChildren(w, next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toDomainUsersetNode(n *v1.UsersetNode) (*UsersetNode, error) {
+	if n == nil {
+		return nil, nil
+	}
+
+	resource, err := toDomainResource(n.GetResource())
+	if err != nil {
+		return nil, err
+	}
+
+	relation, err := toDomainRelation(n.GetRelation())
+	if err != nil {
+		return nil, err
+	}
+
+	subjects, err := toDomainUsersetSubjects(n.GetSubjects())
+	if err != nil {
+		return nil, err
+	}
+
+	children := make([]*UsersetNode, 0, len(n.GetChildren()))
+	for _, c := range n.GetChildren() {
+		child, err := toDomainUsersetNode(c)
+		if err != nil {
+			return nil, err
+		}
+		if child != nil {
+			children = append(children, child)
+		}
+	}
+
+	return &UsersetNode{
+		Type:     toDomainUsersetNodeType(n.GetType()),
+		Resource: resource,
+		Relation: relation,
+		Subjects: subjects,
+		Children: children,
+	}, nil
+}
+
+// toDomainUsersetSubjects maps leaf subjects, whose id carries "#relation" when they are usersets
+func toDomainUsersetSubjects(values []*v1.Subject) ([]*UsersetSubject, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	result := make([]*UsersetSubject, 0, len(values))
+	for _, it := range values {
+		if it == nil {
+			continue
+		}
+		id, relation, isUserset := strings.Cut(it.GetId(), "#")
+		if it.GetType() == "" || id == "" || (isUserset && relation == "") {
+			return nil, fmt.Errorf("invalid userset subject '%s:%s'", it.GetType(), it.GetId())
+		}
+		result = append(result, &UsersetSubject{Type: it.GetType(), ID: id, Relation: relation})
+	}
+	return result, nil
+}
+
+func toDomainUsersetNodeType(t v1.UsersetNodeType) UsersetNodeType {
+	switch t {
+	case v1.UsersetNodeType_USERSET_NODE_TYPE_LEAF:
+		return UsersetNodeLeaf
+	case v1.UsersetNodeType_USERSET_NODE_TYPE_UNION:
+		return UsersetNodeUnion
+	case v1.UsersetNodeType_USERSET_NODE_TYPE_INTERSECTION:
+		return UsersetNodeIntersection
+	case v1.UsersetNodeType_USERSET_NODE_TYPE_EXCLUSION:
+		return UsersetNodeExclusion
+	default:
+		return UsersetNodeUnspecified
+	}
+}
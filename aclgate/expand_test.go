@@ -0,0 +1,81 @@
+package aclgate
+
+import (
+	"testing"
+
+	v1 "github.com/carped99/gosdk/aclgate/api/gen/aclgate/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToDomainUsersetNode(t *testing.T) {
+	t.Run("nested userset", func(t *testing.T) {
+		// Given
+		tree := &v1.UsersetNode{
+			Type:     v1.UsersetNodeType_USERSET_NODE_TYPE_UNION,
+			Resource: &v1.Resource{Type: "document", Id: "readme"},
+			Relation: &v1.Relation{Name: "viewer"},
+			Children: []*v1.UsersetNode{
+				{
+					Type:     v1.UsersetNodeType_USERSET_NODE_TYPE_LEAF,
+					Resource: &v1.Resource{Type: "document", Id: "readme"},
+					Relation: &v1.Relation{Name: "viewer"},
+					Subjects: []*v1.Subject{
+						{Type: "user", Id: "alice"},
+						{Type: "group", Id: "eng#member"},
+					},
+				},
+				{
+					Type:     v1.UsersetNodeType_USERSET_NODE_TYPE_LEAF,
+					Resource: &v1.Resource{Type: "group", Id: "eng"},
+					Relation: &v1.Relation{Name: "member"},
+					Subjects: []*v1.Subject{{Type: "user", Id: "bob"}},
+				},
+			},
+		}
+
+		// When
+		node, err := toDomainUsersetNode(tree)
+
+		// Then
+		require.NoError(t, err)
+		require.Len(t, node.Children, 2)
+		assert.Equal(t, []*UsersetSubject{
+			{Type: "user", ID: "alice"},
+			{Type: "group", ID: "eng", Relation: "member"},
+		}, node.Children[0].Subjects)
+		assert.Equal(t, "group:eng#member", node.Children[1].Userset())
+		assert.Equal(t, `union document:readme#viewer
+├── leaf document:readme#viewer
+│   ├── user:alice
+│   └── group:eng#member
+└── leaf group:eng#member
+    └── user:bob
+`, node.String())
+	})
+
+	t.Run("invalid subject", func(t *testing.T) {
+		for _, subject := range []*v1.Subject{
+			{Type: "", Id: "alice"},
+			{Type: "group", Id: "#member"},
+			{Type: "group", Id: "eng#"},
+		} {
+			_, err := toDomainUsersetNode(&v1.UsersetNode{
+				Type:     v1.UsersetNodeType_USERSET_NODE_TYPE_LEAF,
+				Subjects: []*v1.Subject{subject},
+			})
+			assert.Error(t, err, subject.String())
+		}
+	})
+}
+
+func TestUsersetNodeType_Text(t *testing.T) {
+	var nodeType UsersetNodeType
+	require.NoError(t, nodeType.UnmarshalText([]byte("exclusion")))
+
+	text, err := nodeType.MarshalText()
+
+	require.NoError(t, err)
+	assert.Equal(t, "exclusion", string(text))
+	assert.Error(t, nodeType.UnmarshalText([]byte("unknown")))
+}
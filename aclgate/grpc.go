@@ -0,0 +1,70 @@
+package aclgate
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryClientInterceptor propagates the consistency token in context to outgoing gRPC metadata
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingConsistencyContext(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor propagates the consistency token in context to outgoing gRPC metadata
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingConsistencyContext(ctx), desc, cc, method, opts...)
+	}
+}
+
+// UnaryServerInterceptor extracts the consistency token from incoming gRPC metadata into the context
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(incomingConsistencyContext(ctx), req)
+	}
+}
+
+// StreamServerInterceptor extracts the consistency token from incoming gRPC metadata into the context
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &consistencyServerStream{
+			ServerStream: ss,
+			ctx:          incomingConsistencyContext(ss.Context()),
+		})
+	}
+}
+
+// consistencyServerStream overrides the context of a grpc.ServerStream
+type consistencyServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *consistencyServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func outgoingConsistencyContext(ctx context.Context) context.Context {
+	token, ok := ConsistencyTokenFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, consistencyTokenMetadataKey, token)
+}
+
+func incomingConsistencyContext(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	values := md.Get(consistencyTokenMetadataKey)
+	if len(values) == 0 {
+		return ctx
+	}
+	return NewConsistencyTokenContext(ctx, values[len(values)-1])
+}
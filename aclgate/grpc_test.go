@@ -0,0 +1,153 @@
+package aclgate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// contextServerStream is a grpc.ServerStream with a fixed context
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want []string
+	}{
+		{name: "token in context", ctx: NewConsistencyTokenContext(context.Background(), "token-1"), want: []string{"token-1"}},
+		{name: "no token", ctx: context.Background()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			var outgoing metadata.MD
+			invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+				outgoing, _ = metadata.FromOutgoingContext(ctx)
+				return nil
+			}
+
+			// When
+			err := UnaryClientInterceptor()(tt.ctx, "/aclgate.v1.AclService/Check", nil, nil, nil, invoker)
+
+			// Then
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, outgoing.Get(ConsistencyTokenHeader))
+		})
+	}
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	// Given
+	ctx := NewConsistencyTokenContext(context.Background(), "token-1")
+	var outgoing metadata.MD
+	streamer := func(ctx context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+		outgoing, _ = metadata.FromOutgoingContext(ctx)
+		return nil, nil
+	}
+
+	// When
+	_, err := StreamClientInterceptor()(ctx, &grpc.StreamDesc{}, nil, "/aclgate.v1.AclService/Watch", streamer)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"token-1"}, outgoing.Get(consistencyTokenMetadataKey))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "token in metadata",
+			ctx:    metadata.NewIncomingContext(context.Background(), metadata.Pairs(consistencyTokenMetadataKey, "token-1")),
+			want:   "token-1",
+			wantOK: true,
+		},
+		{
+			name:   "last token wins",
+			ctx:    metadata.NewIncomingContext(context.Background(), metadata.Pairs(consistencyTokenMetadataKey, "token-1", consistencyTokenMetadataKey, "token-2")),
+			want:   "token-2",
+			wantOK: true,
+		},
+		{name: "no token", ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs("other", "value"))},
+		{name: "no metadata", ctx: context.Background()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			var token string
+			var ok bool
+			handler := func(ctx context.Context, _ any) (any, error) {
+				token, ok = ConsistencyTokenFromContext(ctx)
+				return "reply", nil
+			}
+
+			// When
+			reply, err := UnaryServerInterceptor()(tt.ctx, nil, &grpc.UnaryServerInfo{}, handler)
+
+			// Then
+			require.NoError(t, err)
+			assert.Equal(t, "reply", reply)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, token)
+		})
+	}
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	// Given
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(consistencyTokenMetadataKey, "token-1"))
+	var token string
+	handler := func(_ any, stream grpc.ServerStream) error {
+		token, _ = ConsistencyTokenFromContext(stream.Context())
+		return nil
+	}
+
+	// When
+	err := StreamServerInterceptor()(nil, &contextServerStream{ctx: ctx}, &grpc.StreamServerInfo{}, handler)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+}
+
+func TestConsistencyInterceptors_RoundTrip(t *testing.T) {
+	// Given
+	ctx := NewConsistencyTokenContext(context.Background(), "token-1")
+	var token string
+	invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		outgoing, _ := metadata.FromOutgoingContext(ctx)
+		_, err := UnaryServerInterceptor()(metadata.NewIncomingContext(context.Background(), outgoing), nil, &grpc.UnaryServerInfo{},
+			func(ctx context.Context, _ any) (any, error) {
+				token, _ = ConsistencyTokenFromContext(ctx)
+				return nil, nil
+			})
+		return err
+	}
+
+	// When
+	err := UnaryClientInterceptor()(ctx, "/aclgate.v1.AclService/Check", nil, nil, nil, invoker)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+	consistency := resolveConsistency(NewConsistencyTokenContext(context.Background(), token), nil)
+	assert.Equal(t, AtLeastAsFresh("token-1"), consistency)
+}
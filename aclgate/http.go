@@ -10,3 +10,42 @@ func WithContext(service ClientService) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// WithConsistencyToken creates a middleware that injects the consistency token
+// from the ConsistencyTokenHeader into the request context
+func WithConsistencyToken() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token := r.Header.Get(ConsistencyTokenHeader); token != "" {
+				r = r.WithContext(NewConsistencyTokenContext(r.Context(), token))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// NewConsistencyTransport wraps base so that outgoing requests carry the
+// consistency token of their context in the ConsistencyTokenHeader
+func NewConsistencyTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &consistencyTransport{base: base}
+}
+
+// consistencyTransport implements http.RoundTripper
+type consistencyTransport struct {
+	base http.RoundTripper
+}
+
+func (t *consistencyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, ok := ConsistencyTokenFromContext(req.Context())
+	if !ok || req.Header.Get(ConsistencyTokenHeader) != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	// RoundTrippers must not modify the original request
+	clone := req.Clone(req.Context())
+	clone.Header.Set(ConsistencyTokenHeader, token)
+	return t.base.RoundTrip(clone)
+}
@@ -0,0 +1,124 @@
+package aclgate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTripperFunc adapts a function to http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestWithContext(t *testing.T) {
+	// Given
+	service := &tokenService{}
+	var got ClientService
+	handler := WithContext(service)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got, _ = FromContext(r.Context())
+	}))
+
+	// When
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// Then
+	assert.Same(t, service, got)
+}
+
+func TestWithConsistencyToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		wantOK bool
+	}{
+		{name: "token header", header: "token-1", wantOK: true},
+		{name: "no token header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			var token string
+			var ok bool
+			handler := WithConsistencyToken()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				token, ok = ConsistencyTokenFromContext(r.Context())
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(ConsistencyTokenHeader, tt.header)
+			}
+
+			// When
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			// Then
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.header, token)
+		})
+	}
+}
+
+func TestNewConsistencyTransport(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		header string
+		want   string
+	}{
+		{name: "token in context", ctx: NewConsistencyTokenContext(context.Background(), "token-1"), want: "token-1"},
+		{name: "explicit header is kept", ctx: NewConsistencyTokenContext(context.Background(), "token-1"), header: "token-2", want: "token-2"},
+		{name: "no token", ctx: context.Background()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			var sent *http.Request
+			transport := NewConsistencyTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				sent = req
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+			}))
+			req := httptest.NewRequest(http.MethodGet, "http://aclgate.test/", nil).WithContext(tt.ctx)
+			if tt.header != "" {
+				req.Header.Set(ConsistencyTokenHeader, tt.header)
+			}
+
+			// When
+			resp, err := transport.RoundTrip(req)
+
+			// Then
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+			assert.Equal(t, tt.want, sent.Header.Get(ConsistencyTokenHeader))
+			assert.Equal(t, tt.header, req.Header.Get(ConsistencyTokenHeader), "original request must not be modified")
+		})
+	}
+}
+
+func TestConsistencyToken_HTTPRoundTrip(t *testing.T) {
+	// Given
+	var token string
+	server := httptest.NewServer(WithConsistencyToken()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		token, _ = ConsistencyTokenFromContext(r.Context())
+	})))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewConsistencyTransport(nil)}
+	req, err := http.NewRequestWithContext(NewConsistencyTokenContext(context.Background(), "token-1"), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	// When
+	resp, err := client.Do(req)
+
+	// Then
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, "token-1", token)
+}
@@ -0,0 +1,345 @@
+package aclgate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	v1 "github.com/carped99/gosdk/aclgate/api/gen/aclgate/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TupleOperation represents the kind of change applied to a tuple
+type TupleOperation int
+
+const (
+	TupleOperationUnspecified TupleOperation = iota
+	TupleOperationWrite
+	TupleOperationDelete
+)
+
+// String returns the name of the operation
+func (o TupleOperation) String() string {
+	switch o {
+	case TupleOperationWrite:
+		return "WRITE"
+	case TupleOperationDelete:
+		return "DELETE"
+	default:
+		return "UNSPECIFIED"
+	}
+}
+
+// WatchFilter selects the tuple changes to stream.
+// Empty fields match everything; an empty Cursor starts from now.
+type WatchFilter struct {
+	ResourceType string
+	Subject      *Subject
+	Cursor       string
+}
+
+// TupleChange represents a single write or delete of a tuple
+type TupleChange struct {
+	Operation        TupleOperation
+	Tuple            *Tuple
+	Timestamp        time.Time
+	ConsistencyToken string
+}
+
+// WatchEvent represents a batch of tuple changes and the cursor to resume from
+type WatchEvent struct {
+	Changes []*TupleChange
+	Cursor  string
+}
+
+// WatchStream is a stream of tuple changes returned by ClientService.Watch
+type WatchStream interface {
+	// Recv returns the next event; io.EOF is returned when the server ends the stream
+	Recv() (*WatchEvent, error)
+
+	// Close cancels the stream
+	Close() error
+}
+
+// watchStream implements WatchStream over a gRPC server stream
+type watchStream struct {
+	stream grpc.ServerStreamingClient[v1.WatchResponse]
+	cancel context.CancelFunc
+}
+
+func (s *watchStream) Recv() (*WatchEvent, error) {
+	resp, err := s.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]*TupleChange, 0, len(resp.GetChanges()))
+	for _, c := range resp.GetChanges() {
+		tuple, err := toDomainTuple(c.GetTuple())
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert tuple change: %w", err)
+		}
+
+		change := &TupleChange{
+			Operation:        toDomainTupleOperation(c.GetOperation()),
+			Tuple:            tuple,
+			ConsistencyToken: c.GetConsistencyToken(),
+		}
+		if c.GetTimestamp() != nil {
+			change.Timestamp = c.GetTimestamp().AsTime()
+		}
+		changes = append(changes, change)
+	}
+
+	return &WatchEvent{Changes: changes, Cursor: resp.GetCursor()}, nil
+}
+
+func (s *watchStream) Close() error {
+	s.cancel()
+	return nil
+}
+
+func toDomainTupleOperation(op v1.TupleOperation) TupleOperation {
+	switch op {
+	case v1.TupleOperation_TUPLE_OPERATION_WRITE:
+		return TupleOperationWrite
+	case v1.TupleOperation_TUPLE_OPERATION_DELETE:
+		return TupleOperationDelete
+	default:
+		return TupleOperationUnspecified
+	}
+}
+
+// WatchHandler processes a watch event. Returning an error stops the Watcher
+// without checkpointing the event, so it is redelivered on the next run.
+type WatchHandler func(ctx context.Context, event *WatchEvent) error
+
+// CheckpointStore persists the cursor of a Watcher
+type CheckpointStore interface {
+	// Load returns the last saved cursor, or an empty string if none
+	Load(ctx context.Context) (string, error)
+
+	// Save stores the cursor
+	Save(ctx context.Context, cursor string) error
+}
+
+// memoryCheckpointStore implements CheckpointStore in memory
+type memoryCheckpointStore struct {
+	mu     sync.RWMutex
+	cursor string
+}
+
+// NewMemoryCheckpointStore creates an in-memory CheckpointStore starting at cursor
+func NewMemoryCheckpointStore(cursor string) CheckpointStore {
+	return &memoryCheckpointStore{cursor: cursor}
+}
+
+func (s *memoryCheckpointStore) Load(_ context.Context) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cursor, nil
+}
+
+func (s *memoryCheckpointStore) Save(_ context.Context, cursor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursor = cursor
+	return nil
+}
+
+const (
+	defaultWatchMinBackoff = 100 * time.Millisecond
+	defaultWatchMaxBackoff = 30 * time.Second
+)
+
+// watcherConfig holds Watcher configuration
+type watcherConfig struct {
+	checkpoints CheckpointStore
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	onError     func(err error)
+}
+
+// WatcherOption configures a Watcher
+type WatcherOption func(*watcherConfig)
+
+// WithCheckpointStore sets where the Watcher loads and saves its cursor
+func WithCheckpointStore(store CheckpointStore) WatcherOption {
+	return func(c *watcherConfig) {
+		if store != nil {
+			c.checkpoints = store
+		}
+	}
+}
+
+// WithReconnectBackoff sets the exponential backoff bounds between reconnects
+func WithReconnectBackoff(min, max time.Duration) WatcherOption {
+	return func(c *watcherConfig) {
+		if min > 0 {
+			c.minBackoff = min
+		}
+		if max >= c.minBackoff {
+			c.maxBackoff = max
+		}
+	}
+}
+
+// WithWatchErrorHandler sets a callback invoked with every stream error that causes a reconnect
+func WithWatchErrorHandler(fn func(err error)) WatcherOption {
+	return func(c *watcherConfig) {
+		c.onError = fn
+	}
+}
+
+// Watcher consumes a Watch stream, reconnecting on failures and checkpointing
+// the cursor after each successfully handled event
+type Watcher struct {
+	service ClientService
+	filter  WatchFilter
+	handler WatchHandler
+	config  watcherConfig
+	after   func(time.Duration) <-chan time.Time // Waits between reconnects
+}
+
+// NewWatcher creates a new Watcher
+func NewWatcher(service ClientService, filter *WatchFilter, handler WatchHandler, opts ...WatcherOption) (*Watcher, error) {
+	if service == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if handler == nil {
+		return nil, fmt.Errorf("handler cannot be nil")
+	}
+
+	config := watcherConfig{
+		minBackoff: defaultWatchMinBackoff,
+		maxBackoff: defaultWatchMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	w := &Watcher{service: service, handler: handler, config: config, after: time.After}
+	if filter != nil {
+		w.filter = *filter
+	}
+	if w.config.checkpoints == nil {
+		w.config.checkpoints = NewMemoryCheckpointStore(w.filter.Cursor)
+	}
+	return w, nil
+}
+
+// Run watches until ctx is cancelled, the handler or checkpoint store fails, or the stream
+// fails with an error other than a retryable status or network error
+func (w *Watcher) Run(ctx context.Context) error {
+	backoff := w.config.minBackoff
+	for {
+		received, err := w.watchOnce(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var stopErr *watchStopError
+		if errors.As(err, &stopErr) {
+			return stopErr.err
+		}
+		if err != nil && !isRetryableWatchError(err) {
+			return err
+		}
+		if err != nil && w.config.onError != nil {
+			w.config.onError(err)
+		}
+
+		if received {
+			backoff = w.config.minBackoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-w.after(backoff):
+		}
+
+		backoff *= 2
+		if backoff > w.config.maxBackoff {
+			backoff = w.config.maxBackoff
+		}
+	}
+}
+
+// watchOnce consumes a single stream and reports whether any event was received
+func (w *Watcher) watchOnce(ctx context.Context) (bool, error) {
+	cursor, err := w.config.checkpoints.Load(ctx)
+	if err != nil {
+		return false, &watchStopError{err: fmt.Errorf("failed to load checkpoint: %w", err)}
+	}
+
+	filter := w.filter
+	filter.Cursor = cursor
+
+	stream, err := w.service.Watch(ctx, &filter)
+	if err != nil {
+		return false, err
+	}
+	defer stream.Close()
+
+	received := false
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return received, nil
+		}
+		if err != nil {
+			return received, err
+		}
+		received = true
+
+		if err := w.handler(ctx, event); err != nil {
+			return received, &watchStopError{err: err}
+		}
+
+		if event.Cursor != "" {
+			if err := w.config.checkpoints.Save(ctx, event.Cursor); err != nil {
+				return received, &watchStopError{err: fmt.Errorf("failed to save checkpoint: %w", err)}
+			}
+		}
+	}
+}
+
+// watchStopError marks an error that stops the Watcher without reconnecting,
+// returned by the WatchHandler or the CheckpointStore
+type watchStopError struct {
+	err error
+}
+
+func (e *watchStopError) Error() string {
+	return e.err.Error()
+}
+
+func (e *watchStopError) Unwrap() error {
+	return e.err
+}
+
+// isRetryableWatchError reports whether a reconnect may succeed after err.
+// Only gRPC status errors and network errors are retryable; other errors,
+// such as a change that cannot be converted, would fail again.
+func isRetryableWatchError(err error) bool {
+	st, ok := status.FromError(err)
+	if !ok {
+		var netErr net.Error
+		return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+	}
+
+	switch st.Code() {
+	case codes.InvalidArgument, codes.PermissionDenied, codes.Unauthenticated,
+		codes.Unimplemented, codes.FailedPrecondition, codes.OutOfRange:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,329 @@
+package aclgate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// scriptedStream returns its events, then err
+type scriptedStream struct {
+	events []*WatchEvent
+	err    error
+	closed bool
+}
+
+func (s *scriptedStream) Recv() (*WatchEvent, error) {
+	if len(s.events) == 0 {
+		return nil, s.err
+	}
+	event := s.events[0]
+	s.events = s.events[1:]
+	return event, nil
+}
+
+func (s *scriptedStream) Close() error {
+	s.closed = true
+	return nil
+}
+
+// watchResult is the result of a single Watch call
+type watchResult struct {
+	stream *scriptedStream
+	err    error
+}
+
+// watchService answers Watch calls with scripted results and records their filters
+type watchService struct {
+	ClientService
+
+	mu      sync.Mutex
+	results []watchResult
+	filters []WatchFilter
+}
+
+func (s *watchService) Watch(_ context.Context, filter *WatchFilter) (WatchStream, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.filters = append(s.filters, *filter)
+	if len(s.results) == 0 {
+		return nil, status.Error(codes.FailedPrecondition, "no more streams")
+	}
+	result := s.results[0]
+	s.results = s.results[1:]
+	if result.err != nil {
+		return nil, result.err
+	}
+	return result.stream, nil
+}
+
+func (s *watchService) cursors() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cursors := make([]string, 0, len(s.filters))
+	for _, f := range s.filters {
+		cursors = append(cursors, f.Cursor)
+	}
+	return cursors
+}
+
+// recordBackoff replaces the reconnect wait of the watcher, recording the backoffs
+func recordBackoff(w *Watcher) *[]time.Duration {
+	var backoffs []time.Duration
+	w.after = func(d time.Duration) <-chan time.Time {
+		backoffs = append(backoffs, d)
+		ch := make(chan time.Time, 1)
+		ch <- time.Time{}
+		return ch
+	}
+	return &backoffs
+}
+
+func watchEvent(cursor string) *WatchEvent {
+	return &WatchEvent{
+		Changes: []*TupleChange{{Operation: TupleOperationWrite, Tuple: &Tuple{}}},
+		Cursor:  cursor,
+	}
+}
+
+func TestWatcher_ResumesFromCheckpoint(t *testing.T) {
+	// Given
+	unavailable := status.Error(codes.Unavailable, "stream dropped")
+	first := &scriptedStream{events: []*WatchEvent{watchEvent("c1"), watchEvent("c2")}, err: unavailable}
+	second := &scriptedStream{events: []*WatchEvent{watchEvent("c3"), watchEvent("stop")}, err: io.EOF}
+	service := &watchService{results: []watchResult{{stream: first}, {stream: second}}}
+	store := NewMemoryCheckpointStore("c0")
+
+	stopErr := errors.New("stop")
+	var handled []string
+	handler := func(_ context.Context, event *WatchEvent) error {
+		if event.Cursor == "stop" {
+			return stopErr
+		}
+		handled = append(handled, event.Cursor)
+		return nil
+	}
+
+	var streamErrs []error
+	watcher, err := NewWatcher(service, &WatchFilter{ResourceType: "document", Cursor: "ignored"}, handler,
+		WithCheckpointStore(store),
+		WithWatchErrorHandler(func(err error) { streamErrs = append(streamErrs, err) }))
+	require.NoError(t, err)
+	recordBackoff(watcher)
+
+	// When
+	err = watcher.Run(context.Background())
+
+	// Then
+	assert.ErrorIs(t, err, stopErr)
+	assert.Equal(t, []string{"c1", "c2", "c3"}, handled)
+	assert.Equal(t, []string{"c0", "c2"}, service.cursors(), "the stream is resumed from the stored checkpoint")
+	assert.Equal(t, "document", service.filters[1].ResourceType)
+	assert.Equal(t, []error{unavailable}, streamErrs)
+	assert.True(t, first.closed)
+	assert.True(t, second.closed)
+
+	cursor, err := store.Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "c3", cursor, "the failed event is not checkpointed")
+}
+
+func TestWatcher_ReconnectBackoff(t *testing.T) {
+	// Given
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+	service := &watchService{results: []watchResult{
+		{err: unavailable},
+		{err: unavailable},
+		{err: unavailable},
+		{err: unavailable},
+		{stream: &scriptedStream{events: []*WatchEvent{watchEvent("c1")}, err: unavailable}},
+		{err: unavailable},
+		{err: status.Error(codes.PermissionDenied, "denied")},
+	}}
+	watcher, err := NewWatcher(service, nil, func(context.Context, *WatchEvent) error { return nil },
+		WithReconnectBackoff(10*time.Millisecond, 40*time.Millisecond))
+	require.NoError(t, err)
+	backoffs := recordBackoff(watcher)
+
+	// When
+	err = watcher.Run(context.Background())
+
+	// Then
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, []time.Duration{
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		40 * time.Millisecond,
+		40 * time.Millisecond,
+		10 * time.Millisecond, // reset after an event was received
+		20 * time.Millisecond,
+	}, *backoffs)
+	assert.Equal(t, []string{"", "", "", "", "", "c1", "c1"}, service.cursors())
+}
+
+func TestWatcher_Run(t *testing.T) {
+	t.Run("end of stream reconnects", func(t *testing.T) {
+		// Given
+		service := &watchService{results: []watchResult{
+			{stream: &scriptedStream{events: []*WatchEvent{watchEvent("c1")}, err: io.EOF}},
+		}}
+		var streamErrs []error
+		watcher, err := NewWatcher(service, nil, func(context.Context, *WatchEvent) error { return nil },
+			WithWatchErrorHandler(func(err error) { streamErrs = append(streamErrs, err) }))
+		require.NoError(t, err)
+		recordBackoff(watcher)
+
+		// When
+		err = watcher.Run(context.Background())
+
+		// Then
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.Equal(t, []string{"", "c1"}, service.cursors())
+		assert.Empty(t, streamErrs, "end of stream is not an error")
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		// Given
+		ctx, cancel := context.WithCancel(context.Background())
+		service := &watchService{results: []watchResult{
+			{stream: &scriptedStream{events: []*WatchEvent{watchEvent("c1")}, err: io.EOF}},
+		}}
+		watcher, err := NewWatcher(service, nil, func(context.Context, *WatchEvent) error {
+			cancel()
+			return nil
+		})
+		require.NoError(t, err)
+
+		// When
+		err = watcher.Run(ctx)
+
+		// Then
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("initial cursor of the filter", func(t *testing.T) {
+		// Given
+		service := &watchService{}
+		watcher, err := NewWatcher(service, &WatchFilter{Cursor: "c0"}, func(context.Context, *WatchEvent) error { return nil })
+		require.NoError(t, err)
+
+		// When
+		err = watcher.Run(context.Background())
+
+		// Then
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.Equal(t, []string{"c0"}, service.cursors())
+	})
+}
+
+func TestNewWatcher(t *testing.T) {
+	handler := func(context.Context, *WatchEvent) error { return nil }
+
+	_, err := NewWatcher(nil, nil, handler)
+	assert.Error(t, err)
+
+	_, err = NewWatcher(&watchService{}, nil, nil)
+	assert.Error(t, err)
+}
+
+// failingCheckpointStore fails to load the checkpoint
+type failingCheckpointStore struct {
+	err error
+}
+
+func (s *failingCheckpointStore) Load(context.Context) (string, error) {
+	return "", s.err
+}
+
+func (s *failingCheckpointStore) Save(context.Context, string) error {
+	return nil
+}
+
+func TestWatcher_StopsOnPermanentError(t *testing.T) {
+	t.Run("checkpoint load failure", func(t *testing.T) {
+		// Given
+		loadErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+		service := &watchService{}
+		watcher, err := NewWatcher(service, nil, func(context.Context, *WatchEvent) error { return nil },
+			WithCheckpointStore(&failingCheckpointStore{err: loadErr}))
+		require.NoError(t, err)
+		backoffs := recordBackoff(watcher)
+
+		// When
+		err = watcher.Run(context.Background())
+
+		// Then
+		assert.ErrorIs(t, err, loadErr)
+		assert.ErrorContains(t, err, "failed to load checkpoint")
+		assert.Empty(t, service.cursors())
+		assert.Empty(t, *backoffs)
+	})
+
+	t.Run("stream failure", func(t *testing.T) {
+		// Given
+		convertErr := errors.New("failed to convert tuple change: invalid resource")
+		service := &watchService{results: []watchResult{{stream: &scriptedStream{err: convertErr}}}}
+		watcher, err := NewWatcher(service, nil, func(context.Context, *WatchEvent) error { return nil })
+		require.NoError(t, err)
+		backoffs := recordBackoff(watcher)
+
+		// When
+		err = watcher.Run(context.Background())
+
+		// Then
+		assert.ErrorIs(t, err, convertErr)
+		assert.Empty(t, *backoffs)
+	})
+}
+
+func TestMemoryCheckpointStore(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	store := NewMemoryCheckpointStore("c0")
+
+	// When
+	initial, loadErr := store.Load(ctx)
+	saveErr := store.Save(ctx, "c1")
+	saved, reloadErr := store.Load(ctx)
+
+	// Then
+	require.NoError(t, loadErr)
+	require.NoError(t, saveErr)
+	require.NoError(t, reloadErr)
+	assert.Equal(t, "c0", initial)
+	assert.Equal(t, "c1", saved)
+}
+
+func TestIsRetryableWatchError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{err: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, want: true},
+		{err: fmt.Errorf("failed to receive: %w", io.ErrUnexpectedEOF), want: true},
+		{err: fmt.Errorf("failed to convert tuple change: %w", errors.New("invalid resource"))},
+		{err: status.Error(codes.Unavailable, ""), want: true},
+		{err: status.Error(codes.Internal, ""), want: true},
+		{err: status.Error(codes.InvalidArgument, "")},
+		{err: status.Error(codes.PermissionDenied, "")},
+		{err: status.Error(codes.Unauthenticated, "")},
+		{err: status.Error(codes.OutOfRange, "")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			assert.Equal(t, tt.want, isRetryableWatchError(tt.err))
+		})
+	}
+}
@@ -70,6 +70,11 @@ func (db *DB) Config() DatabaseConfig {
 	return db.config
 }
 
+// DriverName returns the configured database driver, such as "postgres" or "mysql".
+func (db *DB) DriverName() string {
+	return string(db.config.Driver)
+}
+
 // BeginTx starts a new transaction with the given context and options.
 func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
 	return db.DB.BeginTx(ctx, opts)
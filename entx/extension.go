@@ -9,6 +9,7 @@ type (
 	Config struct {
 		enableAudit   bool
 		enableTracing bool
+		enableOutbox  bool
 	}
 
 	Extension struct {
@@ -34,6 +35,15 @@ func WithTracing() ExtensionOption {
 	}
 }
 
+// WithOutbox generates helpers binding outbox publishers to ent transactions.
+// The generated code imports github.com/carped99/gosdk/outbox.
+func WithOutbox() ExtensionOption {
+	return func(c *Extension) error {
+		c.config.enableOutbox = true
+		return nil
+	}
+}
+
 func NewExtension(opts ...ExtensionOption) *Extension {
 	ex := &Extension{
 		config: &Config{},
@@ -71,6 +81,9 @@ func (e *Extension) Templates() []*gen.Template {
 	var templates []*gen.Template
 	templates = append(templates, clientTemplate, defaultsTemplate, GetModelNameTemplate)
 	templates = append(templates, WithTxTemplate)
+	if e.config.enableOutbox {
+		templates = append(templates, WithOutboxTemplate)
+	}
 	return templates
 }
 
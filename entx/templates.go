@@ -14,6 +14,7 @@ var (
 	defaultsTemplate     = parseT("template/defaults.tmpl")
 	GetModelNameTemplate = parseT("template/get_model_name.tmpl")
 	WithTxTemplate       = parseT("template/with_tx.tmpl")
+	WithOutboxTemplate   = parseT("template/with_outbox.tmpl")
 )
 
 func parseT(path string) *gen.Template {
@@ -0,0 +1,88 @@
+package entx
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"entgo.io/ent/entc/gen"
+	"entgo.io/ent/entc/load"
+	"entgo.io/ent/schema/field"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestGraph returns a graph of a single User schema generated into dir
+func newTestGraph(t *testing.T, dir string, templates []*gen.Template) *gen.Graph {
+	t.Helper()
+
+	storage, err := gen.NewStorage("sql")
+	require.NoError(t, err)
+
+	graph, err := gen.NewGraph(&gen.Config{
+		Package:   "example.com/app/ent",
+		Target:    dir,
+		Storage:   storage,
+		IDType:    &field.TypeInfo{Type: field.TypeInt},
+		Templates: templates,
+	}, &load.Schema{
+		Name:   "User",
+		Fields: []*load.Field{{Name: "name", Info: &field.TypeInfo{Type: field.TypeString}}},
+	})
+	require.NoError(t, err)
+	return graph
+}
+
+// funcNames returns the names of the functions and methods declared in the Go file
+func funcNames(t *testing.T, path string) []string {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+	require.NoError(t, err)
+
+	var names []string
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			names = append(names, fn.Name.Name)
+		}
+	}
+	return names
+}
+
+func TestWithOutboxTemplate(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	graph := newTestGraph(t, dir, NewExtension(WithOutbox()).Templates())
+
+	// When
+	err := graph.Gen()
+
+	// Then
+	require.NoError(t, err)
+	path := filepath.Join(dir, "entx_with_outbox.go")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "package ent")
+	assert.Contains(t, string(content), `"github.com/carped99/gosdk/outbox"`)
+	assert.ElementsMatch(t, []string{
+		"ExecContext", "OutboxExecutor", "NewOutboxTxContext", "WithOutboxTxContext", "WithOutboxTxVoid",
+	}, funcNames(t, path))
+	assert.Contains(t, funcNames(t, filepath.Join(dir, "entx_with_tx.go")), "WithTxContext")
+}
+
+func TestWithOutboxTemplate_Disabled(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	graph := newTestGraph(t, dir, NewExtension().Templates())
+
+	// When
+	err := graph.Gen()
+
+	// Then
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(dir, "entx_with_outbox.go"))
+	assert.FileExists(t, filepath.Join(dir, "entx_with_tx.go"))
+}
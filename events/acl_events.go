@@ -0,0 +1,137 @@
+package events
+
+import (
+	"github.com/carped99/gosdk/outbox"
+)
+
+// Topic and domain of ACL events
+const (
+	AclEventTopic  = "acl"
+	AclEventDomain = "acl"
+)
+
+// Event types of ACL events
+const (
+	EventTypeAclTuplesWritten   = "acl.tuples.written"
+	EventTypeAclTuplesDeleted   = "acl.tuples.deleted"
+	EventTypeAclResourceDeleted = "acl.resource.deleted"
+	EventTypeAclSubjectDeleted  = "acl.subject.deleted"
+)
+
+// Object types of ACL events
+const (
+	ObjectTypeAclTuple    = "acl_tuple"
+	ObjectTypeAclResource = "acl_resource"
+	ObjectTypeAclSubject  = "acl_subject"
+)
+
+// AclTuplesWritten is published when tuples are granted
+type AclTuplesWritten struct {
+	Tuples []*AclTuple `json:"tuples"`
+}
+
+func (p *AclTuplesWritten) EventType() string {
+	return EventTypeAclTuplesWritten
+}
+
+func (p *AclTuplesWritten) ObjectType() string {
+	return ObjectTypeAclTuple
+}
+
+// Validate requires at least one tuple, each with resource, subject and relation
+func (p *AclTuplesWritten) Validate() error {
+	return validateAclTuples(p.Tuples, true, true).err()
+}
+
+// AclTuplesDeleted is published when tuples are revoked
+type AclTuplesDeleted struct {
+	Tuples []*AclTuple `json:"tuples"`
+}
+
+func (p *AclTuplesDeleted) EventType() string {
+	return EventTypeAclTuplesDeleted
+}
+
+func (p *AclTuplesDeleted) ObjectType() string {
+	return ObjectTypeAclTuple
+}
+
+// Validate requires at least one tuple, each with resource, subject and relation.
+// Publish AclResourceDeleted or AclSubjectDeleted to revoke all tuples of a resource or subject.
+func (p *AclTuplesDeleted) Validate() error {
+	return validateAclTuples(p.Tuples, true, true).err()
+}
+
+// AclResourceDeleted is published when a resource is deleted and all of its tuples must be revoked
+type AclResourceDeleted struct {
+	Resource *AclResource `json:"resource"`
+}
+
+func (p *AclResourceDeleted) EventType() string {
+	return EventTypeAclResourceDeleted
+}
+
+func (p *AclResourceDeleted) ObjectType() string {
+	return ObjectTypeAclResource
+}
+
+func (p *AclResourceDeleted) Validate() error {
+	if p.Resource == nil {
+		return ValidationErrors{{Field: "resource", Err: ErrMissingField}}
+	}
+	return p.Resource.validate("resource").err()
+}
+
+// AclSubjectDeleted is published when a subject is deleted and all of its tuples must be revoked
+type AclSubjectDeleted struct {
+	Subject *AclSubject `json:"subject"`
+}
+
+func (p *AclSubjectDeleted) EventType() string {
+	return EventTypeAclSubjectDeleted
+}
+
+func (p *AclSubjectDeleted) ObjectType() string {
+	return ObjectTypeAclSubject
+}
+
+func (p *AclSubjectDeleted) Validate() error {
+	if p.Subject == nil {
+		return ValidationErrors{{Field: "subject", Err: ErrMissingField}}
+	}
+	return p.Subject.validate("subject").err()
+}
+
+// NewAclTuplesWrittenMessage creates an outbox message announcing granted tuples
+func NewAclTuplesWrittenMessage(tuples ...*AclTuple) (*outbox.Message, error) {
+	return newAclEventMessage(&AclTuplesWritten{Tuples: tuples})
+}
+
+// NewAclTuplesDeletedMessage creates an outbox message announcing revoked tuples
+func NewAclTuplesDeletedMessage(tuples ...*AclTuple) (*outbox.Message, error) {
+	return newAclEventMessage(&AclTuplesDeleted{Tuples: tuples})
+}
+
+// NewAclResourceDeletedMessage creates an outbox message announcing a deleted resource
+func NewAclResourceDeletedMessage(resource *AclResource) (*outbox.Message, error) {
+	return newAclEventMessage(&AclResourceDeleted{Resource: resource})
+}
+
+// NewAclSubjectDeletedMessage creates an outbox message announcing a deleted subject
+func NewAclSubjectDeletedMessage(subject *AclSubject) (*outbox.Message, error) {
+	return newAclEventMessage(&AclSubjectDeleted{Subject: subject})
+}
+
+// aclEvent is implemented by all ACL event payloads
+type aclEvent interface {
+	EventType() string
+	ObjectType() string
+	Validate() error
+}
+
+func newAclEventMessage[E aclEvent](event E) (*outbox.Message, error) {
+	if err := event.Validate(); err != nil {
+		return nil, err
+	}
+	return outbox.NewTypedMessage(AclEventTopic, AclEventDomain, "", event)
+}
@@ -0,0 +1,114 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/carped99/gosdk/outbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAclEventMessages(t *testing.T) {
+	tuple := &AclTuple{
+		Resource: &AclResource{Type: "document", ID: "doc-1"},
+		Subject:  &AclSubject{Type: "user", ID: "alice"},
+		Relation: &AclRelation{Name: "viewer"},
+	}
+
+	tests := []struct {
+		name       string
+		newMessage func() (*outbox.Message, error)
+		eventType  string
+		objectType string
+		decode     func(msg *outbox.Message) (any, error)
+		payload    any
+	}{
+		{
+			name:       "tuples written",
+			newMessage: func() (*outbox.Message, error) { return NewAclTuplesWrittenMessage(tuple) },
+			eventType:  EventTypeAclTuplesWritten,
+			objectType: ObjectTypeAclTuple,
+			decode: func(msg *outbox.Message) (any, error) {
+				return outbox.DecodePayload[*AclTuplesWritten](msg)
+			},
+			payload: &AclTuplesWritten{Tuples: []*AclTuple{tuple}},
+		},
+		{
+			name:       "tuples deleted",
+			newMessage: func() (*outbox.Message, error) { return NewAclTuplesDeletedMessage(tuple) },
+			eventType:  EventTypeAclTuplesDeleted,
+			objectType: ObjectTypeAclTuple,
+			decode: func(msg *outbox.Message) (any, error) {
+				return outbox.DecodePayload[*AclTuplesDeleted](msg)
+			},
+			payload: &AclTuplesDeleted{Tuples: []*AclTuple{tuple}},
+		},
+		{
+			name:       "resource deleted",
+			newMessage: func() (*outbox.Message, error) { return NewAclResourceDeletedMessage(tuple.Resource) },
+			eventType:  EventTypeAclResourceDeleted,
+			objectType: ObjectTypeAclResource,
+			decode: func(msg *outbox.Message) (any, error) {
+				return outbox.DecodePayload[*AclResourceDeleted](msg)
+			},
+			payload: &AclResourceDeleted{Resource: tuple.Resource},
+		},
+		{
+			name:       "subject deleted",
+			newMessage: func() (*outbox.Message, error) { return NewAclSubjectDeletedMessage(tuple.Subject) },
+			eventType:  EventTypeAclSubjectDeleted,
+			objectType: ObjectTypeAclSubject,
+			decode: func(msg *outbox.Message) (any, error) {
+				return outbox.DecodePayload[*AclSubjectDeleted](msg)
+			},
+			payload: &AclSubjectDeleted{Subject: tuple.Subject},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			msg, err := tt.newMessage()
+
+			// Then
+			require.NoError(t, err)
+			assert.NotEmpty(t, msg.EventID)
+			assert.Equal(t, AclEventTopic, msg.EventTopic)
+			assert.Equal(t, AclEventDomain, msg.EventDomain)
+			assert.Equal(t, tt.eventType, msg.EventType)
+			assert.Equal(t, tt.objectType, msg.ObjectType)
+
+			payload, err := tt.decode(msg)
+			require.NoError(t, err)
+			assert.Equal(t, tt.payload, payload)
+		})
+	}
+}
+
+func TestNewAclEventMessages_Invalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		newMessage func() (*outbox.Message, error)
+	}{
+		{name: "no written tuples", newMessage: func() (*outbox.Message, error) { return NewAclTuplesWrittenMessage() }},
+		{name: "partial deleted tuple", newMessage: func() (*outbox.Message, error) {
+			return NewAclTuplesDeletedMessage(&AclTuple{Subject: &AclSubject{Type: "user", ID: "alice"}})
+		}},
+		{name: "no resource", newMessage: func() (*outbox.Message, error) { return NewAclResourceDeletedMessage(nil) }},
+		{name: "invalid subject", newMessage: func() (*outbox.Message, error) {
+			return NewAclSubjectDeletedMessage(&AclSubject{Type: "user"})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			msg, err := tt.newMessage()
+
+			// Then
+			var errs ValidationErrors
+			assert.ErrorAs(t, err, &errs)
+			assert.Nil(t, msg)
+		})
+	}
+}
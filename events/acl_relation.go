@@ -1,21 +1,16 @@
 package events
 
-import (
-	"fmt"
-	"regexp"
-)
-
-var (
-	relationRegex = regexp.MustCompile(`^[^:#@\s]+$`)
-)
-
 type AclRelation struct {
 	Name string `json:"name"`
 }
 
+// Validate reports every invalid field of the relation as ValidationErrors
 func (p *AclRelation) Validate() error {
-	if !relationRegex.MatchString(p.Name) {
-		return fmt.Errorf("invalid relation name: '%s'", p.Name)
-	}
-	return nil
+	return p.validate("").err()
+}
+
+func (p *AclRelation) validate(prefix string) ValidationErrors {
+	var errs ValidationErrors
+	errs.check(aclRelationRegex.MatchString(p.Name), joinField(prefix, "name"), p.Name, ErrInvalidRelationName)
+	return errs
 }
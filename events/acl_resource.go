@@ -1,28 +1,18 @@
 package events
 
-import (
-	"fmt"
-	"regexp"
-)
-
-var (
-	resourceTypeRegex = regexp.MustCompile(`^[^:#\s]+$`)
-	resourceIDRegex   = regexp.MustCompile(`^[^:#\s]+$`)
-)
-
 type AclResource struct {
 	Type string `json:"type"`
 	ID   string `json:"id"`
 }
 
+// Validate reports every invalid field of the resource as ValidationErrors
 func (p *AclResource) Validate() error {
-	if !resourceTypeRegex.MatchString(p.Type) {
-		return fmt.Errorf("invalid resource type: '%s'", p.Type)
-	}
-
-	if !resourceIDRegex.MatchString(p.ID) {
-		return fmt.Errorf("invalid resource id: '%s'", p.ID)
-	}
+	return p.validate("").err()
+}
 
-	return nil
+func (p *AclResource) validate(prefix string) ValidationErrors {
+	var errs ValidationErrors
+	errs.check(aclTypeRegex.MatchString(p.Type), joinField(prefix, "type"), p.Type, ErrInvalidResourceType)
+	errs.check(aclIDRegex.MatchString(p.ID), joinField(prefix, "id"), p.ID, ErrInvalidResourceID)
+	return errs
 }
@@ -1,28 +1,18 @@
 package events
 
-import (
-	"fmt"
-	"regexp"
-)
-
-var (
-	subjectTypeRegex = regexp.MustCompile(`^[^:#\s]+$`)
-	subjectIDRegex   = regexp.MustCompile(`^[^:#\s]+$`)
-)
-
 type AclSubject struct {
 	Type string `json:"type"`
 	ID   string `json:"id"`
 }
 
+// Validate reports every invalid field of the subject as ValidationErrors
 func (p *AclSubject) Validate() error {
-	if !subjectTypeRegex.MatchString(p.Type) {
-		return fmt.Errorf("invalid subject type: '%s'", p.Type)
-	}
-
-	if !subjectIDRegex.MatchString(p.ID) {
-		return fmt.Errorf("invalid subject id: '%s'", p.ID)
-	}
+	return p.validate("").err()
+}
 
-	return nil
+func (p *AclSubject) validate(prefix string) ValidationErrors {
+	var errs ValidationErrors
+	errs.check(aclTypeRegex.MatchString(p.Type), joinField(prefix, "type"), p.Type, ErrInvalidSubjectType)
+	errs.check(aclIDRegex.MatchString(p.ID), joinField(prefix, "id"), p.ID, ErrInvalidSubjectID)
+	return errs
 }
@@ -6,23 +6,37 @@ type AclTuple struct {
 	Relation *AclRelation `json:"relation,omitempty"`
 }
 
+// Validate reports every invalid field of the tuple as ValidationErrors.
+// Missing parts are allowed; use ValidateComplete to require all of them.
 func (p *AclTuple) Validate() error {
+	return p.validate("", false).err()
+}
+
+// ValidateComplete is like Validate but also requires resource, subject and relation
+func (p *AclTuple) ValidateComplete() error {
+	return p.validate("", true).err()
+}
+
+func (p *AclTuple) validate(prefix string, complete bool) ValidationErrors {
+	var errs ValidationErrors
+
 	if p.Resource != nil {
-		if err := p.Resource.Validate(); err != nil {
-			return err
-		}
+		errs = append(errs, p.Resource.validate(joinField(prefix, "resource"))...)
+	} else if complete {
+		errs.missing(joinField(prefix, "resource"))
 	}
 
 	if p.Subject != nil {
-		if err := p.Subject.Validate(); err != nil {
-			return err
-		}
+		errs = append(errs, p.Subject.validate(joinField(prefix, "subject"))...)
+	} else if complete {
+		errs.missing(joinField(prefix, "subject"))
 	}
 
 	if p.Relation != nil {
-		if err := p.Relation.Validate(); err != nil {
-			return err
-		}
+		errs = append(errs, p.Relation.validate(joinField(prefix, "relation"))...)
+	} else if complete {
+		errs.missing(joinField(prefix, "relation"))
 	}
-	return nil
+
+	return errs
 }
@@ -50,23 +50,10 @@ func (b *AclTupleBuilder) SetRelationName(relationName string) *AclTupleBuilder
 	return b
 }
 
+// Build returns the tuple, reporting every invalid field of the parts set as ValidationErrors
 func (b *AclTupleBuilder) Build() (*AclTuple, error) {
-	if b.payload.Resource != nil {
-		if err := b.payload.Resource.Validate(); err != nil {
-			return nil, err
-		}
-	}
-
-	if b.payload.Subject != nil {
-		if err := b.payload.Subject.Validate(); err != nil {
-			return nil, err
-		}
-	}
-
-	if b.payload.Relation != nil {
-		if err := b.payload.Relation.Validate(); err != nil {
-			return nil, err
-		}
+	if err := b.payload.Validate(); err != nil {
+		return nil, err
 	}
 	return b.payload, nil
 }
@@ -0,0 +1,62 @@
+package events
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAclTupleBuilder_Build(t *testing.T) {
+	tests := []struct {
+		name   string
+		build  func(b *AclTupleBuilder) *AclTupleBuilder
+		fields []string
+	}{
+		{
+			name: "valid tuple",
+			build: func(b *AclTupleBuilder) *AclTupleBuilder {
+				return b.SetResourceType("document").SetResourceID("doc-1").
+					SetSubjectType("user").SetSubjectID("alice").
+					SetRelationName("viewer")
+			},
+		},
+		{
+			name: "partial tuple",
+			build: func(b *AclTupleBuilder) *AclTupleBuilder {
+				return b.SetSubjectType("user").SetSubjectID("alice")
+			},
+		},
+		{
+			name: "every part invalid",
+			build: func(b *AclTupleBuilder) *AclTupleBuilder {
+				return b.SetResourceType("doc:x").SetResourceID("doc-1").
+					SetSubjectType("user").SetSubjectID("").
+					SetRelationName("view#er")
+			},
+			fields: []string{"resource.type", "subject.id", "relation.name"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			tuple, err := tt.build(NewAclTupleBuilder()).Build()
+
+			// Then
+			if tt.fields == nil {
+				require.NoError(t, err)
+				assert.NotNil(t, tuple)
+				return
+			}
+			var errs ValidationErrors
+			require.True(t, errors.As(err, &errs))
+			fields := make([]string, 0, len(errs))
+			for _, e := range errs {
+				fields = append(fields, e.Field)
+			}
+			assert.Equal(t, tt.fields, fields)
+		})
+	}
+}
@@ -1,16 +1,32 @@
 package events
 
+import "fmt"
+
 type AclTuples struct {
 	Tuples []*AclTuple `json:"tuples,omitempty"`
 }
 
+// Validate reports every invalid field of the tuples as ValidationErrors
 func (p *AclTuples) Validate() error {
-	for _, tuple := range p.Tuples {
-		if err := tuple.Validate(); err != nil {
-			return err
+	return validateAclTuples(p.Tuples, false, false).err()
+}
+
+// validateAclTuples validates each tuple under the "tuples[i]" field path
+func validateAclTuples(tuples []*AclTuple, required, complete bool) ValidationErrors {
+	var errs ValidationErrors
+	if required && len(tuples) == 0 {
+		errs.missing("tuples")
+	}
+
+	for i, tuple := range tuples {
+		field := fmt.Sprintf("tuples[%d]", i)
+		if tuple == nil {
+			errs.missing(field)
+			continue
 		}
+		errs = append(errs, tuple.validate(field, complete)...)
 	}
-	return nil
+	return errs
 }
 
 type AclTuplesBuilder struct {
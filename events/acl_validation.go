@@ -0,0 +1,88 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Validation rules shared with the ACL gateway. A value accepted here is accepted by aclgate.
+var (
+	aclTypeRegex     = regexp.MustCompile(`^[^:#@\s]{1,254}$`)
+	aclIDRegex       = regexp.MustCompile(`^[^#:\s]+$`)
+	aclRelationRegex = regexp.MustCompile(`^[^:#@\s]{1,50}$`)
+)
+
+// Validation errors reported through FieldError
+var (
+	ErrMissingField        = errors.New("missing required field")
+	ErrInvalidResourceType = errors.New("invalid resource type")
+	ErrInvalidResourceID   = errors.New("invalid resource id")
+	ErrInvalidSubjectType  = errors.New("invalid subject type")
+	ErrInvalidSubjectID    = errors.New("invalid subject id")
+	ErrInvalidRelationName = errors.New("invalid relation name")
+)
+
+// FieldError describes a single invalid field
+type FieldError struct {
+	Field string
+	Value string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	if errors.Is(e.Err, ErrMissingField) {
+		return fmt.Sprintf("%s: %v", e.Field, e.Err)
+	}
+	return fmt.Sprintf("%s: %v: '%s'", e.Field, e.Err, e.Value)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors collects every invalid field of a value
+type ValidationErrors []*FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// err returns e as an error, or nil if there are no field errors
+func (e ValidationErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationErrors) check(ok bool, field, value string, err error) {
+	if !ok {
+		*e = append(*e, &FieldError{Field: field, Value: value, Err: err})
+	}
+}
+
+func (e *ValidationErrors) missing(field string) {
+	*e = append(*e, &FieldError{Field: field, Err: ErrMissingField})
+}
+
+// joinField prefixes field with the path of its parent
+func joinField(prefix, field string) string {
+	if prefix == "" {
+		return field
+	}
+	return prefix + "." + field
+}
@@ -0,0 +1,82 @@
+package events
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAclValidationRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{ Validate() error }
+		err   error
+	}{
+		{name: "valid resource", value: &AclResource{Type: "document", ID: "doc-1"}},
+		{name: "resource id with at sign", value: &AclResource{Type: "document", ID: "alice@example.com"}},
+		{name: "resource type with colon", value: &AclResource{Type: "doc:x", ID: "1"}, err: ErrInvalidResourceType},
+		{name: "resource type with at sign", value: &AclResource{Type: "doc@x", ID: "1"}, err: ErrInvalidResourceType},
+		{name: "resource type too long", value: &AclResource{Type: strings.Repeat("t", 255), ID: "1"}, err: ErrInvalidResourceType},
+		{name: "resource id with hash", value: &AclResource{Type: "document", ID: "1#x"}, err: ErrInvalidResourceID},
+		{name: "empty resource id", value: &AclResource{Type: "document"}, err: ErrInvalidResourceID},
+		{name: "valid subject", value: &AclSubject{Type: "user", ID: "alice"}},
+		{name: "empty subject type", value: &AclSubject{ID: "alice"}, err: ErrInvalidSubjectType},
+		{name: "subject id with space", value: &AclSubject{Type: "user", ID: "a b"}, err: ErrInvalidSubjectID},
+		{name: "valid relation", value: &AclRelation{Name: "viewer"}},
+		{name: "relation at the length limit", value: &AclRelation{Name: strings.Repeat("r", 50)}},
+		{name: "relation too long", value: &AclRelation{Name: strings.Repeat("r", 51)}, err: ErrInvalidRelationName},
+		{name: "relation with hash", value: &AclRelation{Name: "view#er"}, err: ErrInvalidRelationName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.value.Validate()
+
+			if tt.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestAclTuple_Validate(t *testing.T) {
+	t.Run("reports every invalid field", func(t *testing.T) {
+		// Given
+		tuple := &AclTuple{
+			Resource: &AclResource{Type: "doc:x", ID: ""},
+			Subject:  &AclSubject{Type: "user", ID: "a b"},
+			Relation: &AclRelation{Name: "viewer"},
+		}
+
+		// When
+		err := tuple.Validate()
+
+		// Then
+		var errs ValidationErrors
+		require.True(t, errors.As(err, &errs))
+		require.Len(t, errs, 3)
+		assert.Equal(t, "resource.type", errs[0].Field)
+		assert.Equal(t, "resource.id", errs[1].Field)
+		assert.Equal(t, "subject.id", errs[2].Field)
+		assert.ErrorIs(t, err, ErrInvalidResourceType)
+		assert.ErrorIs(t, err, ErrInvalidSubjectID)
+	})
+
+	t.Run("complete requires every part", func(t *testing.T) {
+		// When
+		err := (&AclTuple{Relation: &AclRelation{Name: "viewer"}}).ValidateComplete()
+
+		// Then
+		var errs ValidationErrors
+		require.True(t, errors.As(err, &errs))
+		require.Len(t, errs, 2)
+		assert.Equal(t, "resource", errs[0].Field)
+		assert.Equal(t, "subject", errs[1].Field)
+		assert.ErrorIs(t, err, ErrMissingField)
+	})
+}
@@ -0,0 +1,24 @@
+module github.com/carped99/gosdk/inbox
+
+go 1.23.0
+
+toolchain go1.23.9
+
+require (
+	github.com/DATA-DOG/go-sqlmock v1.5.2
+	github.com/carped99/gosdk/outbox v0.0.0
+	github.com/stretchr/testify v1.10.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/klauspost/compress v1.17.11 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1 // indirect
+	go.uber.org/multierr v1.11.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/carped99/gosdk/outbox => ../outbox
@@ -0,0 +1,256 @@
+// Package inbox processes messages published through the outbox exactly once.
+//
+// Each processed EventID is recorded in an inbox table in the same transaction as
+// the writes of its handler. A redelivered message finds its record and is skipped,
+// and a failed handler rolls back the record so the message can be delivered again.
+package inbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/carped99/gosdk/outbox"
+)
+
+var (
+	defaultTableName = "inbox_message"
+	defaultConsumer  = "default"
+)
+
+type config struct {
+	tableName  string
+	consumer   string
+	dialect    outbox.Dialect
+	onError    func(msg *outbox.Message, err error)
+	quarantine Quarantiner
+	restorers  []outbox.PayloadRestorer
+}
+
+// Option defines a function that configures an Inbox or Migrate
+type Option func(*config) error
+
+// WithTableName sets the inbox table name
+func WithTableName(tableName string) Option {
+	return func(c *config) error {
+		tableName = strings.TrimSpace(tableName)
+		if err := outbox.ValidateSQLIdentifier(tableName); err != nil {
+			return fmt.Errorf("invalid table name: %w", err)
+		}
+		c.tableName = tableName
+		return nil
+	}
+}
+
+// WithConsumer sets the name recorded with processed messages, "default" by default.
+// Consumers with different names process the same message independently.
+func WithConsumer(name string) Option {
+	return func(c *config) error {
+		if name == "" {
+			return fmt.Errorf("consumer name cannot be empty")
+		}
+		c.consumer = name
+		return nil
+	}
+}
+
+// WithDialect sets the SQL dialect of the inbox table.
+// Without it the dialect is detected from a db implementing outbox.DriverNamer, or Postgres.
+func WithDialect(dialect outbox.Dialect) Option {
+	return func(c *config) error {
+		if _, err := outbox.DialectFromDriver(string(dialect)); err != nil {
+			return err
+		}
+		c.dialect = dialect
+		return nil
+	}
+}
+
+// WithErrorHandler sets a function called by a Runner with messages that failed to process
+func WithErrorHandler(handler func(msg *outbox.Message, err error)) Option {
+	return func(c *config) error {
+		c.onError = handler
+		return nil
+	}
+}
+
+// Quarantiner stores messages that cannot be processed, such as *outbox.DeadLetterStore
+type Quarantiner interface {
+	Quarantine(ctx context.Context, msg *outbox.Message, attempts int, cause error) error
+}
+
+// WithQuarantine sets where a Runner stores valid messages that failed permanently,
+// such as messages without a handler, before acknowledging them
+func WithQuarantine(store Quarantiner) Option {
+	return func(c *config) error {
+		if store == nil {
+			return fmt.Errorf("quarantine store cannot be nil")
+		}
+		c.quarantine = store
+		return nil
+	}
+}
+
+// WithPayloadRestorers reverts the payload transformers of the publisher, such as
+// outbox.PayloadEncryptor, before messages are routed.
+// Restorers are given in the order of the transformers.
+func WithPayloadRestorers(restorers ...outbox.PayloadRestorer) Option {
+	return func(c *config) error {
+		for i, r := range restorers {
+			if r == nil {
+				return fmt.Errorf("payload restorer at index %d is nil", i)
+			}
+		}
+		c.restorers = append(c.restorers, restorers...)
+		return nil
+	}
+}
+
+// Inbox processes messages with the handlers of a Router, recording each processed
+// message in the inbox table.
+type Inbox struct {
+	db     outbox.TxBeginner
+	router *Router
+	config config
+
+	insertQuery string
+}
+
+// New creates a new Inbox recording processed messages in db
+func New(db outbox.TxBeginner, router *Router, opts ...Option) (*Inbox, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db cannot be nil")
+	}
+	if router == nil {
+		return nil, fmt.Errorf("router cannot be nil")
+	}
+
+	config, err := newConfig(db, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Inbox{
+		db:          db,
+		router:      router,
+		config:      config,
+		insertQuery: insertQuery(config.dialect, config.dialect.QuoteIdentifier(config.tableName)),
+	}, nil
+}
+
+// Process handles the message in a transaction unless the consumer already processed it.
+// Duplicates are skipped without error. Messages without a handler fail with ErrNoHandler.
+func (i *Inbox) Process(ctx context.Context, msg *outbox.Message) error {
+	if msg == nil {
+		return fmt.Errorf("message cannot be nil")
+	}
+	if err := msg.Validate(); err != nil {
+		return outbox.Permanent(fmt.Errorf("invalid message: %w", err))
+	}
+
+	msg, err := i.restore(ctx, msg)
+	if err != nil {
+		return err
+	}
+
+	rt, err := i.router.route(msg)
+	if err != nil {
+		return outbox.Permanent(err)
+	}
+	return i.process(ctx, rt, msg)
+}
+
+// restore returns a copy of the message with the payload restorers applied.
+// Payloads that cannot be decrypted fail permanently.
+func (i *Inbox) restore(ctx context.Context, msg *outbox.Message) (*outbox.Message, error) {
+	if len(i.config.restorers) == 0 {
+		return msg, nil
+	}
+
+	restored := *msg
+	if err := outbox.RestorePayload(ctx, &restored, i.config.restorers...); err != nil {
+		err = fmt.Errorf("failed to restore message %s: %w", msg.EventID, err)
+		if errors.Is(err, outbox.ErrDecryptionFailed) {
+			return nil, outbox.Permanent(err)
+		}
+		return nil, err
+	}
+	return &restored, nil
+}
+
+func (i *Inbox) process(ctx context.Context, rt *route, msg *outbox.Message) error {
+	tx, err := i.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx, i.insertQuery,
+		i.config.consumer, msg.EventID, msg.EventDomain, msg.EventType, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to record message %s: %w", msg.EventID, err)
+	}
+	recorded, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to record message %s: %w", msg.EventID, err)
+	}
+	if recorded == 0 {
+		return nil // processed before
+	}
+
+	if err := rt.handler.Handle(outbox.ContextWithTx(ctx, tx), tx, msg); err != nil {
+		return fmt.Errorf("failed to handle message %s: %w", msg.EventID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// newConfig applies opts over the defaults and detects the dialect of db
+func newConfig(db any, opts []Option) (config, error) {
+	c := config{
+		tableName: defaultTableName,
+		consumer:  defaultConsumer,
+	}
+	for _, opt := range opts {
+		if err := opt(&c); err != nil {
+			return c, fmt.Errorf("failed to apply inbox option: %w", err)
+		}
+	}
+
+	if c.dialect == "" {
+		c.dialect = outbox.DialectPostgres
+		if namer, ok := db.(outbox.DriverNamer); ok {
+			dialect, err := outbox.DialectFromDriver(namer.DriverName())
+			if err != nil {
+				return c, fmt.Errorf("failed to detect dialect: %w", err)
+			}
+			c.dialect = dialect
+		}
+	}
+	return c, nil
+}
+
+// insertQuery returns the statement recording a message, which affects no row
+// when the consumer already recorded it
+func insertQuery(dialect outbox.Dialect, table string) string {
+	const columns = " (consumer, event_id, event_domain, event_type, processed_at) VALUES "
+
+	if dialect == outbox.DialectMySQL {
+		return "INSERT IGNORE INTO " + table + columns + "(?, ?, ?, ?, ?)"
+	}
+
+	params := make([]string, 5)
+	for n := range params {
+		params[n] = "?"
+		if dialect == outbox.DialectPostgres {
+			params[n] = "$" + strconv.Itoa(n+1)
+		}
+	}
+	return "INSERT INTO " + table + columns + "(" + strings.Join(params, ", ") + ") ON CONFLICT DO NOTHING"
+}
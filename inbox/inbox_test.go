@@ -0,0 +1,249 @@
+package inbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/carped99/gosdk/outbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const insertPattern = `INSERT INTO "inbox_message" (consumer, event_id, event_domain, event_type, processed_at) VALUES ($1, $2, $3, $4, $5) ON CONFLICT DO NOTHING`
+
+type orderPlaced struct {
+	OrderID string `json:"order_id"`
+}
+
+func (orderPlaced) EventType() string { return "order.placed" }
+
+func newTestMessage(t *testing.T, correlationID string, orderID string) *outbox.Message {
+	builder, err := outbox.NewTypedMessageBuilder("orders", "shop", "", &orderPlaced{OrderID: orderID})
+	require.NoError(t, err)
+	msg, err := builder.SetCorrelationID(correlationID).Build()
+	require.NoError(t, err)
+	return msg
+}
+
+func TestInbox_Process(t *testing.T) {
+	t.Run("records and handles a new message", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		var handled []string
+		router := NewRouter()
+		require.NoError(t, HandleTyped(router, "shop", "order.placed",
+			func(ctx context.Context, tx *sql.Tx, payload *orderPlaced, msg *outbox.Message) error {
+				_, ok := outbox.TxFromContext(ctx)
+				assert.True(t, ok)
+				_, err := tx.ExecContext(ctx, "UPDATE orders SET status = 'placed' WHERE id = $1", payload.OrderID)
+				handled = append(handled, payload.OrderID)
+				return err
+			}))
+
+		inbox, err := New(db, router, WithConsumer("billing"))
+		require.NoError(t, err)
+		msg := newTestMessage(t, "corr-1", "o-1")
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(insertPattern)).
+			WithArgs("billing", msg.EventID, "shop", "order.placed", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE orders")).WithArgs("o-1").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// When
+		err = inbox.Process(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"o-1"}, handled)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("skips a duplicate", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		called := false
+		router := NewRouter()
+		require.NoError(t, router.Handle("shop", "order.placed", HandlerFunc(func(context.Context, *sql.Tx, *outbox.Message) error {
+			called = true
+			return nil
+		})))
+
+		inbox, err := New(db, router)
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(insertPattern)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		// When
+		err = inbox.Process(context.Background(), newTestMessage(t, "corr-1", "o-1"))
+
+		// Then
+		require.NoError(t, err)
+		assert.False(t, called)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rolls back when the handler fails", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		router := NewRouter()
+		require.NoError(t, router.Handle("shop", "order.placed", HandlerFunc(func(context.Context, *sql.Tx, *outbox.Message) error {
+			return errors.New("out of stock")
+		})))
+
+		inbox, err := New(db, router)
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(insertPattern)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+
+		// When
+		err = inbox.Process(context.Background(), newTestMessage(t, "corr-1", "o-1"))
+
+		// Then
+		assert.ErrorContains(t, err, "out of stock")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("no handler", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		inbox, err := New(db, NewRouter())
+		require.NoError(t, err)
+
+		// When
+		err = inbox.Process(context.Background(), newTestMessage(t, "corr-1", "o-1"))
+
+		// Then
+		assert.ErrorIs(t, err, ErrNoHandler)
+		assert.True(t, outbox.IsPermanent(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("payload of another type", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		router := NewRouter()
+		require.NoError(t, HandleTyped(router, "shop", "order.placed",
+			func(context.Context, *sql.Tx, json.Number, *outbox.Message) error { return nil }))
+
+		inbox, err := New(db, router)
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(insertPattern)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+
+		// When
+		err = inbox.Process(context.Background(), newTestMessage(t, "corr-1", "o-1"))
+
+		// Then
+		assert.True(t, outbox.IsPermanent(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestInsertQuery(t *testing.T) {
+	assert.Equal(t, "INSERT IGNORE INTO `inbox_message` (consumer, event_id, event_domain, event_type, processed_at) VALUES (?, ?, ?, ?, ?)",
+		insertQuery(outbox.DialectMySQL, outbox.DialectMySQL.QuoteIdentifier("inbox_message")))
+	assert.Equal(t, `INSERT INTO "inbox_message" (consumer, event_id, event_domain, event_type, processed_at) VALUES (?, ?, ?, ?, ?) ON CONFLICT DO NOTHING`,
+		insertQuery(outbox.DialectSQLite, outbox.DialectSQLite.QuoteIdentifier("inbox_message")))
+}
+
+func TestNew(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = New(db, nil)
+	assert.Error(t, err)
+
+	_, err = New(db, NewRouter(), WithTableName("inbox; DROP TABLE users"))
+	assert.Error(t, err)
+
+	_, err = New(db, NewRouter(), WithDialect("oracle"))
+	assert.Error(t, err)
+}
+
+func TestInbox_Process_PayloadRestorers(t *testing.T) {
+	t.Run("routes the restored payload", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		var handled []string
+		router := NewRouter()
+		require.NoError(t, HandleTyped(router, "shop", "order.placed",
+			func(_ context.Context, _ *sql.Tx, payload *orderPlaced, _ *outbox.Message) error {
+				handled = append(handled, payload.OrderID)
+				return nil
+			}))
+
+		restorer := outbox.PayloadRestorerFunc(func(_ context.Context, msg *outbox.Message) error {
+			msg.Payload = json.RawMessage(`{"order_id":"o-restored"}`)
+			return nil
+		})
+		inbox, err := New(db, router, WithPayloadRestorers(restorer))
+		require.NoError(t, err)
+		msg := newTestMessage(t, "corr-1", "o-1")
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(insertPattern)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// When
+		err = inbox.Process(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"o-restored"}, handled)
+		assert.JSONEq(t, `{"order_id":"o-1"}`, string(msg.Payload))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("decryption failure is permanent", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		restorer := outbox.PayloadRestorerFunc(func(context.Context, *outbox.Message) error {
+			return outbox.ErrDecryptionFailed
+		})
+		inbox, err := New(db, NewRouter(), WithPayloadRestorers(restorer))
+		require.NoError(t, err)
+
+		// When
+		err = inbox.Process(context.Background(), newTestMessage(t, "corr-1", "o-1"))
+
+		// Then
+		assert.ErrorIs(t, err, outbox.ErrDecryptionFailed)
+		assert.True(t, outbox.IsPermanent(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,79 @@
+package inbox
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/carped99/gosdk/outbox"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// migrationData is passed to the migration templates
+type migrationData struct {
+	dialect   outbox.Dialect
+	tableName string
+}
+
+// Table returns the quoted inbox table name
+func (d migrationData) Table() string {
+	return d.dialect.QuoteIdentifier(d.tableName)
+}
+
+// Index returns the name of an index on the inbox table
+func (d migrationData) Index(suffix string) string {
+	return "idx_" + strings.ReplaceAll(d.tableName, ".", "_") + "_" + suffix
+}
+
+// Migrate creates the inbox table if it does not exist.
+// Only the table name and dialect options apply.
+func Migrate(ctx context.Context, db outbox.Executor, opts ...Option) error {
+	if db == nil {
+		return fmt.Errorf("db cannot be nil")
+	}
+
+	config, err := newConfig(db, opts)
+	if err != nil {
+		return err
+	}
+
+	dir := path.Join("migrations", string(config.dialect))
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return fmt.Errorf("failed to read migrations (%s): %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		content, err := fs.ReadFile(migrationFiles, path.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read migration (%s): %w", entry.Name(), err)
+		}
+
+		tmpl, err := template.New(entry.Name()).Parse(string(content))
+		if err != nil {
+			return fmt.Errorf("failed to parse migration (%s): %w", entry.Name(), err)
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, migrationData{dialect: config.dialect, tableName: config.tableName}); err != nil {
+			return fmt.Errorf("failed to render migration (%s): %w", entry.Name(), err)
+		}
+
+		for _, stmt := range strings.Split(buf.String(), ";") {
+			if stmt = strings.TrimSpace(stmt); stmt == "" {
+				continue
+			}
+			if _, err := db.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("migration %s failed: %w", entry.Name(), err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package inbox
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/carped99/gosdk/outbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	t.Run("postgres", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS "app"."inbox_message"`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`CREATE INDEX IF NOT EXISTS idx_app_inbox_message_processed_at ON "app"."inbox_message"`)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// When
+		err = Migrate(context.Background(), db, WithTableName("app.inbox_message"))
+
+		// Then
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("mysql", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS `inbox_message`")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// When
+		err = Migrate(context.Background(), db, WithDialect(outbox.DialectMySQL))
+
+		// Then
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS {{.Table}} (
+    consumer     VARCHAR(255) NOT NULL,
+    event_id     VARCHAR(64)  NOT NULL,
+    event_domain VARCHAR(255) NOT NULL,
+    event_type   VARCHAR(255) NOT NULL,
+    processed_at DATETIME(6)  NOT NULL,
+    PRIMARY KEY (consumer, event_id),
+    INDEX {{.Index "processed_at"}} (processed_at)
+);
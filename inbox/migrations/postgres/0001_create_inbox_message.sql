@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS {{.Table}} (
+    consumer     VARCHAR(255) NOT NULL,
+    event_id     VARCHAR(64)  NOT NULL,
+    event_domain VARCHAR(255) NOT NULL,
+    event_type   VARCHAR(255) NOT NULL,
+    processed_at TIMESTAMPTZ  NOT NULL,
+    PRIMARY KEY (consumer, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS {{.Index "processed_at"}} ON {{.Table}} (processed_at);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS {{.Table}} (
+    consumer     TEXT      NOT NULL,
+    event_id     TEXT      NOT NULL,
+    event_domain TEXT      NOT NULL,
+    event_type   TEXT      NOT NULL,
+    processed_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (consumer, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS {{.Index "processed_at"}} ON {{.Table}} (processed_at);
@@ -0,0 +1,151 @@
+package inbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/carped99/gosdk/outbox"
+)
+
+var defaultConcurrency = 1
+
+// ErrNoHandler is returned for messages without a registered handler
+var ErrNoHandler = errors.New("no handler for message")
+
+// Handler processes a message inside the inbox transaction.
+// Writes made through tx commit together with the record of the message,
+// so a message is processed exactly once. The context also carries tx for
+// outbox.NewTxPublisher, so events published by the handler commit with it.
+type Handler interface {
+	Handle(ctx context.Context, tx *sql.Tx, msg *outbox.Message) error
+}
+
+// HandlerFunc adapts a function to the Handler interface
+type HandlerFunc func(ctx context.Context, tx *sql.Tx, msg *outbox.Message) error
+
+func (f HandlerFunc) Handle(ctx context.Context, tx *sql.Tx, msg *outbox.Message) error {
+	return f(ctx, tx, msg)
+}
+
+// TypedHandlerFunc processes the decoded payload of a message
+type TypedHandlerFunc[T any] func(ctx context.Context, tx *sql.Tx, payload T, msg *outbox.Message) error
+
+// TypedHandler returns a Handler decoding payloads with outbox.DecodePayload before calling fn
+func TypedHandler[T any](fn TypedHandlerFunc[T]) Handler {
+	return HandlerFunc(func(ctx context.Context, tx *sql.Tx, msg *outbox.Message) error {
+		payload, err := outbox.DecodePayload[T](msg)
+		if err != nil {
+			return outbox.Permanent(err)
+		}
+		return fn(ctx, tx, payload, msg)
+	})
+}
+
+// routeKey identifies the handler of a message
+type routeKey struct {
+	domain    string
+	eventType string
+}
+
+// route is a registered handler
+type route struct {
+	key         routeKey
+	handler     Handler
+	concurrency int
+}
+
+// HandlerOption configures a registered handler
+type HandlerOption func(*route) error
+
+// WithConcurrency sets the number of messages a Runner processes at once for the handler, 1 by default.
+// Messages sharing an AggregateKey, or a CorrelationID without one, are processed one at a time,
+// in the order they were received.
+func WithConcurrency(workers int) HandlerOption {
+	return func(r *route) error {
+		if workers <= 0 {
+			return fmt.Errorf("concurrency must be positive, got %d", workers)
+		}
+		r.concurrency = workers
+		return nil
+	}
+}
+
+// Router routes messages to handlers by EventDomain and EventType.
+// It is safe for concurrent use; a running Inbox.Run keeps the handlers
+// registered when it started.
+type Router struct {
+	mu     sync.RWMutex
+	routes map[routeKey]*route
+}
+
+// NewRouter creates an empty Router
+func NewRouter() *Router {
+	return &Router{routes: make(map[routeKey]*route)}
+}
+
+// Handle registers the handler of the messages of an event domain and type
+func (r *Router) Handle(domain, eventType string, handler Handler, opts ...HandlerOption) error {
+	if domain == "" {
+		return outbox.ErrInvalidEventDomain
+	}
+	if eventType == "" {
+		return outbox.ErrInvalidEventType
+	}
+	if handler == nil {
+		return fmt.Errorf("handler cannot be nil")
+	}
+
+	key := routeKey{domain: domain, eventType: eventType}
+	rt := &route{key: key, handler: handler, concurrency: defaultConcurrency}
+	for _, opt := range opts {
+		if err := opt(rt); err != nil {
+			return fmt.Errorf("failed to apply handler option: %w", err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.routes[key]; ok {
+		return fmt.Errorf("handler already registered for %s/%s", domain, eventType)
+	}
+	r.routes[key] = rt
+	return nil
+}
+
+// HandleTyped registers a TypedHandler of the messages of an event domain and type
+func HandleTyped[T any](r *Router, domain, eventType string, fn TypedHandlerFunc[T], opts ...HandlerOption) error {
+	if fn == nil {
+		return fmt.Errorf("handler cannot be nil")
+	}
+	return r.Handle(domain, eventType, TypedHandler(fn), opts...)
+}
+
+// route returns the handler registered for the message
+func (r *Router) route(msg *outbox.Message) (*route, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return lookupRoute(r.routes, msg)
+}
+
+// snapshot returns a copy of the registered routes
+func (r *Router) snapshot() map[routeKey]*route {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	routes := make(map[routeKey]*route, len(r.routes))
+	for key, rt := range r.routes {
+		routes[key] = rt
+	}
+	return routes
+}
+
+// lookupRoute returns the route of the message among routes
+func lookupRoute(routes map[routeKey]*route, msg *outbox.Message) (*route, error) {
+	rt, ok := routes[routeKey{domain: msg.EventDomain, eventType: msg.EventType}]
+	if !ok {
+		return nil, fmt.Errorf("%w %s (%s/%s)", ErrNoHandler, msg.EventID, msg.EventDomain, msg.EventType)
+	}
+	return rt, nil
+}
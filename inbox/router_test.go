@@ -0,0 +1,71 @@
+package inbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/carped99/gosdk/outbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTimeout = 5 * time.Second
+	testTick    = 5 * time.Millisecond
+)
+
+func TestRouter_Handle(t *testing.T) {
+	noop := HandlerFunc(func(context.Context, *sql.Tx, *outbox.Message) error { return nil })
+
+	t.Run("routes by domain and type", func(t *testing.T) {
+		router := NewRouter()
+		require.NoError(t, router.Handle("shop", "order.placed", noop, WithConcurrency(4)))
+
+		rt, err := router.route(&outbox.Message{EventDomain: "shop", EventType: "order.placed"})
+		require.NoError(t, err)
+		assert.Equal(t, 4, rt.concurrency)
+
+		_, err = router.route(&outbox.Message{EventDomain: "shop", EventType: "order.cancelled"})
+		assert.ErrorIs(t, err, ErrNoHandler)
+	})
+
+	t.Run("invalid registrations", func(t *testing.T) {
+		router := NewRouter()
+		require.NoError(t, router.Handle("shop", "order.placed", noop))
+
+		assert.Error(t, router.Handle("shop", "order.placed", noop))
+		assert.ErrorIs(t, router.Handle("", "order.placed", noop), outbox.ErrInvalidEventDomain)
+		assert.ErrorIs(t, router.Handle("shop", "", noop), outbox.ErrInvalidEventType)
+		assert.Error(t, router.Handle("shop", "order.paid", nil))
+		assert.Error(t, router.Handle("shop", "order.paid", noop, WithConcurrency(0)))
+	})
+}
+
+func TestRouter_Handle_Concurrent(t *testing.T) {
+	// Given
+	router := NewRouter()
+	handler := HandlerFunc(func(context.Context, *sql.Tx, *outbox.Message) error { return nil })
+
+	// When
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for n := range errs {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			errs[n] = router.Handle("shop", fmt.Sprintf("order.%d", n), handler)
+			_, _ = router.route(&outbox.Message{EventDomain: "shop", EventType: "order.0"})
+		}(n)
+	}
+	wg.Wait()
+
+	// Then
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Len(t, router.snapshot(), len(errs))
+}
@@ -0,0 +1,167 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+
+	"github.com/carped99/gosdk/outbox"
+)
+
+// Delivery is a message received from a broker
+type Delivery struct {
+	Message *outbox.Message
+
+	// Ack is called once with the result of processing; nil acknowledges the message
+	// and an error asks the broker to deliver it again. Messages failing permanently
+	// are acknowledged, since a redelivery would fail again. It may be nil.
+	Ack func(err error)
+}
+
+// Source receives messages from a broker, such as a Kafka consumer group or a JetStream consumer
+type Source interface {
+	// Receive blocks until a message arrives or ctx is done
+	Receive(ctx context.Context) (Delivery, error)
+}
+
+// SourceFunc adapts a function to the Source interface
+type SourceFunc func(ctx context.Context) (Delivery, error)
+
+func (f SourceFunc) Receive(ctx context.Context) (Delivery, error) {
+	return f(ctx)
+}
+
+// Run receives messages from source and processes them until ctx is done or
+// source fails, and waits for the messages in flight. Payloads are restored with
+// the WithPayloadRestorers restorers before they are routed.
+//
+// Each handler processes up to its concurrency of messages at once. Messages are
+// spread over its workers by AggregateKey, CorrelationID or EventID, the first one
+// set, so messages sharing a key are processed in the order they were received.
+//
+// Failures are reported to the WithErrorHandler handler. Permanent failures, such as
+// invalid messages or messages without a handler, are stored with the WithQuarantine
+// store and acknowledged; other failures are delivered again.
+//
+// Run uses the handlers registered when it starts; handlers registered later are
+// used by the next Run.
+func (i *Inbox) Run(ctx context.Context, source Source) error {
+	if source == nil {
+		return errors.New("source cannot be nil")
+	}
+
+	routes := i.router.snapshot()
+
+	var wg sync.WaitGroup
+	lanes := make(map[*route][]chan Delivery, len(routes))
+	for _, rt := range routes {
+		routeLanes := make([]chan Delivery, rt.concurrency)
+		for n := range routeLanes {
+			lane := make(chan Delivery)
+			routeLanes[n] = lane
+
+			wg.Add(1)
+			go func(rt *route) {
+				defer wg.Done()
+				for d := range lane {
+					i.deliver(ctx, d, i.process(ctx, rt, d.Message))
+				}
+			}(rt)
+		}
+		lanes[rt] = routeLanes
+	}
+
+	defer func() {
+		for _, routeLanes := range lanes {
+			for _, lane := range routeLanes {
+				close(lane)
+			}
+		}
+		wg.Wait()
+	}()
+
+	for {
+		d, err := source.Receive(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			return err
+		}
+		if d.Message == nil {
+			continue
+		}
+
+		if err := d.Message.Validate(); err != nil {
+			i.deliver(ctx, d, outbox.Permanent(err))
+			continue
+		}
+		restored, err := i.restore(ctx, d.Message)
+		if err != nil {
+			i.deliver(ctx, d, err)
+			continue
+		}
+		d.Message = restored
+
+		rt, err := lookupRoute(routes, d.Message)
+		if err != nil {
+			i.deliver(ctx, d, outbox.Permanent(err))
+			continue
+		}
+
+		routeLanes := lanes[rt]
+		select {
+		case routeLanes[laneOf(d.Message, len(routeLanes))] <- d:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// deliver reports the result of processing a delivery. Permanent failures are
+// quarantined and acknowledged, unless quarantining fails.
+func (i *Inbox) deliver(ctx context.Context, d Delivery, err error) {
+	if err != nil && i.config.onError != nil && ctx.Err() == nil {
+		i.config.onError(d.Message, err)
+	}
+	if err != nil && outbox.IsPermanent(err) {
+		err = i.quarantine(ctx, d.Message, err)
+	}
+	if d.Ack != nil {
+		d.Ack(err)
+	}
+}
+
+// quarantine stores a valid message that failed permanently with the WithQuarantine store.
+// Invalid messages cannot be stored and are dropped.
+func (i *Inbox) quarantine(ctx context.Context, msg *outbox.Message, cause error) error {
+	if i.config.quarantine == nil || msg.Validate() != nil {
+		return nil
+	}
+	if err := i.config.quarantine.Quarantine(ctx, msg, 1, cause); err != nil {
+		if i.config.onError != nil && ctx.Err() == nil {
+			i.config.onError(msg, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// laneOf returns the worker processing the message
+func laneOf(msg *outbox.Message, lanes int) int {
+	if lanes == 1 {
+		return 0
+	}
+
+	key := msg.AggregateKey
+	if key == "" {
+		key = msg.CorrelationID
+	}
+	if key == "" {
+		key = msg.EventID
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(lanes))
+}
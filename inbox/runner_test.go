@@ -0,0 +1,258 @@
+package inbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/carped99/gosdk/outbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sliceSource delivers messages from a slice and records their acknowledgements
+type sliceSource struct {
+	mu       sync.Mutex
+	messages []*outbox.Message
+	acks     map[string]error
+}
+
+func newSliceSource(messages ...*outbox.Message) *sliceSource {
+	return &sliceSource{messages: messages, acks: make(map[string]error)}
+}
+
+func (s *sliceSource) Receive(ctx context.Context) (Delivery, error) {
+	s.mu.Lock()
+	if len(s.messages) == 0 {
+		s.mu.Unlock()
+		<-ctx.Done()
+		return Delivery{}, ctx.Err()
+	}
+	msg := s.messages[0]
+	s.messages = s.messages[1:]
+	s.mu.Unlock()
+
+	return Delivery{Message: msg, Ack: func(err error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.acks[msg.EventID] = err
+	}}, nil
+}
+
+func (s *sliceSource) ackCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.acks)
+}
+
+func TestInbox_Run(t *testing.T) {
+	// Given
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	const correlations, perCorrelation = 4, 5
+	var messages []*outbox.Message
+	for n := 0; n < perCorrelation; n++ {
+		for c := 0; c < correlations; c++ {
+			messages = append(messages, newTestMessage(t, fmt.Sprintf("corr-%d", c), fmt.Sprintf("o-%d", n)))
+		}
+	}
+	for range messages {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+	}
+
+	var mu sync.Mutex
+	processed := make(map[string][]string)
+	router := NewRouter()
+	require.NoError(t, HandleTyped(router, "shop", "order.placed",
+		func(_ context.Context, _ *sql.Tx, payload *orderPlaced, msg *outbox.Message) error {
+			mu.Lock()
+			defer mu.Unlock()
+			processed[msg.CorrelationID] = append(processed[msg.CorrelationID], payload.OrderID)
+			return nil
+		}, WithConcurrency(3)))
+
+	unrouted, err := outbox.NewMessageBuilder().
+		SetEventTopic("users").
+		SetEventDomain("iam").
+		SetEventType("user.created").
+		SetObjectType("user").
+		Build()
+	require.NoError(t, err)
+
+	var failures sync.Map
+	inbox, err := New(db, router, WithErrorHandler(func(msg *outbox.Message, err error) {
+		failures.Store(msg.EventID, err)
+	}))
+	require.NoError(t, err)
+	source := newSliceSource(append(messages, unrouted)...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// When
+	done := make(chan error)
+	go func() { done <- inbox.Run(ctx, source) }()
+	require.Eventually(t, func() bool { return source.ackCount() == len(messages)+1 }, testTimeout, testTick)
+	cancel()
+
+	// Then
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.NoError(t, source.acks[unrouted.EventID], "permanent failures are acknowledged")
+	failure, _ := failures.Load(unrouted.EventID)
+	assert.ErrorIs(t, failure.(error), ErrNoHandler)
+	for _, msg := range messages {
+		assert.NoError(t, source.acks[msg.EventID])
+	}
+	require.Len(t, processed, correlations)
+	for _, orders := range processed {
+		assert.Equal(t, []string{"o-0", "o-1", "o-2", "o-3", "o-4"}, orders)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// recordingQuarantine records quarantined messages, failing with err
+type recordingQuarantine struct {
+	mu     sync.Mutex
+	err    error
+	causes map[string]error
+}
+
+func (q *recordingQuarantine) Quarantine(_ context.Context, msg *outbox.Message, _ int, cause error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.causes == nil {
+		q.causes = make(map[string]error)
+	}
+	q.causes[msg.EventID] = cause
+	return q.err
+}
+
+func TestInbox_Run_Quarantine(t *testing.T) {
+	unrouted, err := outbox.NewMessageBuilder().
+		SetEventTopic("users").
+		SetEventDomain("iam").
+		SetEventType("user.created").
+		SetObjectType("user").
+		Build()
+	require.NoError(t, err)
+	invalid := &outbox.Message{EventID: "invalid"}
+
+	run := func(t *testing.T, quarantine *recordingQuarantine) *sliceSource {
+		t.Helper()
+		db, _, err := sqlmock.New()
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+
+		inbox, err := New(db, NewRouter(), WithQuarantine(quarantine))
+		require.NoError(t, err)
+		source := newSliceSource(unrouted, invalid)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		done := make(chan error)
+		go func() { done <- inbox.Run(ctx, source) }()
+		require.Eventually(t, func() bool { return source.ackCount() == 2 }, testTimeout, testTick)
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+		return source
+	}
+
+	t.Run("permanent failures are quarantined and acknowledged", func(t *testing.T) {
+		// Given
+		quarantine := &recordingQuarantine{}
+
+		// When
+		source := run(t, quarantine)
+
+		// Then
+		assert.NoError(t, source.acks[unrouted.EventID])
+		assert.NoError(t, source.acks[invalid.EventID])
+		assert.ErrorIs(t, quarantine.causes[unrouted.EventID], ErrNoHandler)
+		assert.NotContains(t, quarantine.causes, invalid.EventID, "invalid messages cannot be stored")
+	})
+
+	t.Run("failed quarantine is delivered again", func(t *testing.T) {
+		// Given
+		quarantineErr := errors.New("dead-letter table unavailable")
+		quarantine := &recordingQuarantine{err: quarantineErr}
+
+		// When
+		source := run(t, quarantine)
+
+		// Then
+		assert.ErrorIs(t, source.acks[unrouted.EventID], quarantineErr)
+		assert.NoError(t, source.acks[invalid.EventID])
+	})
+}
+
+func TestLaneOf(t *testing.T) {
+	msg := &outbox.Message{EventID: "e-1", CorrelationID: "corr-1"}
+	other := &outbox.Message{EventID: "e-2", CorrelationID: "corr-1"}
+
+	assert.Equal(t, 0, laneOf(msg, 1))
+	assert.Equal(t, laneOf(msg, 8), laneOf(other, 8))
+	assert.Less(t, laneOf(&outbox.Message{EventID: "e-3"}, 8), 8)
+
+	keyed := &outbox.Message{EventID: "e-4", CorrelationID: "corr-2", AggregateKey: "order-1"}
+	assert.Equal(t, laneOf(&outbox.Message{EventID: "e-5", AggregateKey: "order-1"}, 8), laneOf(keyed, 8))
+}
+
+func TestInbox_Run_PayloadRestorers(t *testing.T) {
+	// Given
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	keys, err := outbox.NewStaticKeyProvider("key-1", map[string][]byte{"key-1": make([]byte, 32)})
+	require.NoError(t, err)
+	encryptor, err := outbox.NewPayloadEncryptor(keys)
+	require.NoError(t, err)
+
+	msg := newTestMessage(t, "corr-1", "o-1")
+	require.NoError(t, encryptor.Transform(context.Background(), msg))
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	var mu sync.Mutex
+	var handled []string
+	router := NewRouter()
+	require.NoError(t, HandleTyped(router, "shop", "order.placed",
+		func(_ context.Context, _ *sql.Tx, payload *orderPlaced, _ *outbox.Message) error {
+			mu.Lock()
+			defer mu.Unlock()
+			handled = append(handled, payload.OrderID)
+			return nil
+		}))
+
+	inbox, err := New(db, router, WithPayloadRestorers(encryptor))
+	require.NoError(t, err)
+	source := newSliceSource(msg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// When
+	done := make(chan error)
+	go func() { done <- inbox.Run(ctx, source) }()
+	require.Eventually(t, func() bool { return source.ackCount() == 1 }, testTimeout, testTick)
+	cancel()
+
+	// Then
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.NoError(t, source.acks[msg.EventID])
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"o-1"}, handled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,101 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var defaultJSONLPrefix = "outbox"
+
+// JSONLOption defines a function that configures a JSONLArchiver
+type JSONLOption func(*JSONLArchiver) error
+
+// WithJSONLPrefix sets the prefix of the archive file names, "outbox" by default
+func WithJSONLPrefix(prefix string) JSONLOption {
+	return func(a *JSONLArchiver) error {
+		if prefix == "" || strings.ContainsAny(prefix, `/\`) {
+			return fmt.Errorf("invalid file prefix '%s'", prefix)
+		}
+		a.prefix = prefix
+		return nil
+	}
+}
+
+// JSONLArchiver appends archived messages as JSON Lines to one file per day,
+// named <prefix>-YYYYMMDD.jsonl after the day they are archived.
+//
+// A batch is synced to disk before its messages are deleted. When the deletion
+// fails the batch is archived again by the next cleanup, so a file may hold duplicates.
+type JSONLArchiver struct {
+	dir    string
+	prefix string
+	now    func() time.Time
+
+	mu sync.Mutex
+}
+
+var _ Archiver = (*JSONLArchiver)(nil)
+
+// NewJSONLArchiver creates a new JSONLArchiver writing to dir, created when missing
+func NewJSONLArchiver(dir string, opts ...JSONLOption) (*JSONLArchiver, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("directory cannot be empty")
+	}
+
+	a := &JSONLArchiver{
+		dir:    dir,
+		prefix: defaultJSONLPrefix,
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		if err := opt(a); err != nil {
+			return nil, fmt.Errorf("failed to apply jsonl option: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	return a, nil
+}
+
+// Archive appends the messages to the file of the current day
+func (a *JSONLArchiver) Archive(_ context.Context, _ *sql.Tx, messages []*ArchivedMessage) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	name := filepath.Join(a.dir, a.fileName(a.now()))
+	file, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to open archive file: %w", err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	encoder := json.NewEncoder(w)
+	for _, msg := range messages {
+		if err := encoder.Encode(msg); err != nil {
+			return fmt.Errorf("failed to write message %s: %w", msg.EventID, err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write archive file: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync archive file: %w", err)
+	}
+	return file.Close()
+}
+
+// fileName returns the archive file of a day
+func (a *JSONLArchiver) fileName(day time.Time) string {
+	return a.prefix + "-" + day.UTC().Format("20060102") + ".jsonl"
+}
@@ -0,0 +1,58 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONLArchiver(t *testing.T) {
+	// Given
+	dir := filepath.Join(t.TempDir(), "archive")
+	archiver, err := NewJSONLArchiver(dir, WithJSONLPrefix("orders"))
+	require.NoError(t, err)
+	archiver.now = func() time.Time { return time.Date(2024, 3, 9, 23, 0, 0, 0, time.UTC) }
+
+	publishedAt := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	messages := []*ArchivedMessage{
+		{Message: &Message{EventID: "id-1", EventTopic: "orders", Payload: json.RawMessage(`{"n":1}`), CreatedAt: publishedAt}, PublishedAt: publishedAt},
+		{Message: &Message{EventID: "id-2", EventTopic: "orders", CreatedAt: publishedAt}, PublishedAt: publishedAt},
+	}
+
+	// When
+	require.NoError(t, archiver.Archive(context.Background(), nil, messages[:1]))
+	require.NoError(t, archiver.Archive(context.Background(), nil, messages[1:]))
+
+	// Then
+	file, err := os.Open(filepath.Join(dir, "orders-20240309.jsonl"))
+	require.NoError(t, err)
+	defer file.Close()
+
+	var lines []map[string]any
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.Len(t, lines, 2)
+	assert.Equal(t, "id-1", lines[0]["event_id"])
+	assert.Equal(t, map[string]any{"n": float64(1)}, lines[0]["payload"])
+	assert.Equal(t, "2024-03-01T00:00:00Z", lines[0]["published_at"])
+	assert.Equal(t, "id-2", lines[1]["event_id"])
+}
+
+func TestNewJSONLArchiver(t *testing.T) {
+	_, err := NewJSONLArchiver("")
+	assert.Error(t, err)
+
+	_, err = NewJSONLArchiver(t.TempDir(), WithJSONLPrefix("../outbox"))
+	assert.Error(t, err)
+}
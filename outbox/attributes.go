@@ -0,0 +1,98 @@
+package outbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Message attribute keys, used as headers or fields by broker dispatchers
+const (
+	AttributeEventID       = "event_id"
+	AttributeEventDomain   = "event_domain"
+	AttributeEventType     = "event_type"
+	AttributeObjectType    = "object_type"
+	AttributeProducer      = "producer"
+	AttributeCorrelationID = "correlation_id"
+	AttributeCreatedAt     = "created_at"
+	AttributeMetadata      = "metadata"
+	AttributeAggregateKey  = "aggregate_key"
+	AttributeSequence      = "sequence"
+)
+
+// KV is a message attribute
+type KV struct {
+	Key   string
+	Value string
+}
+
+// MessageAttributes returns the attributes describing the message, skipping empty values.
+// The EventTopic and Payload are not included, since transports carry them natively.
+// Metadata is set as compact JSON, so consumers can restore transformed payloads.
+func MessageAttributes(msg *Message) ([]KV, error) {
+	values := []KV{
+		{AttributeEventID, msg.EventID},
+		{AttributeEventDomain, msg.EventDomain},
+		{AttributeEventType, msg.EventType},
+		{AttributeObjectType, msg.ObjectType},
+		{AttributeProducer, msg.Producer},
+		{AttributeCorrelationID, msg.CorrelationID},
+		{AttributeCreatedAt, msg.CreatedAt.UTC().Format(time.RFC3339Nano)},
+		{AttributeAggregateKey, msg.AggregateKey},
+	}
+	if msg.AggregateKey != "" {
+		values = append(values, KV{AttributeSequence, strconv.FormatInt(msg.Sequence, 10)})
+	}
+	if len(msg.Metadata) > 0 {
+		var metadata bytes.Buffer
+		if err := json.Compact(&metadata, msg.Metadata); err != nil {
+			return nil, &MarshalError{Field: "metadata", Err: err}
+		}
+		values = append(values, KV{AttributeMetadata, metadata.String()})
+	}
+
+	result := values[:0]
+	for _, v := range values {
+		if v.Value != "" {
+			result = append(result, v)
+		}
+	}
+	return result, nil
+}
+
+// MessageFromAttributes rebuilds a message from the attributes returned by get, which returns "" for a missing key.
+// The caller sets the EventTopic and Payload and validates the message.
+func MessageFromAttributes(get func(key string) string) (*Message, error) {
+	msg := &Message{
+		EventID:       get(AttributeEventID),
+		EventDomain:   get(AttributeEventDomain),
+		EventType:     get(AttributeEventType),
+		ObjectType:    get(AttributeObjectType),
+		Producer:      get(AttributeProducer),
+		CorrelationID: get(AttributeCorrelationID),
+		AggregateKey:  get(AttributeAggregateKey),
+	}
+	if v := get(AttributeCreatedAt); v != "" {
+		createdAt, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s attribute: %w", AttributeCreatedAt, err)
+		}
+		msg.CreatedAt = createdAt
+	}
+	if v := get(AttributeSequence); v != "" {
+		sequence, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s attribute: %w", AttributeSequence, err)
+		}
+		msg.Sequence = sequence
+	}
+	if v := get(AttributeMetadata); v != "" {
+		if !json.Valid([]byte(v)) {
+			return nil, fmt.Errorf("invalid %s attribute: not valid JSON", AttributeMetadata)
+		}
+		msg.Metadata = json.RawMessage(v)
+	}
+	return msg, nil
+}
@@ -0,0 +1,103 @@
+package outbox
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageAttributes(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		// Given
+		msg := &Message{
+			EventID:       uuid.New().String(),
+			EventTopic:    "orders",
+			EventDomain:   "shop",
+			EventType:     "order.created",
+			ObjectType:    "order",
+			Producer:      "checkout",
+			CorrelationID: "c-1",
+			AggregateKey:  "order-1",
+			Sequence:      7,
+			Payload:       json.RawMessage(`{"order_id":"o-1"}`),
+			Metadata:      json.RawMessage(`{ "schema_version": 2 }`),
+			CreatedAt:     time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		}
+
+		// When
+		attributes, err := MessageAttributes(msg)
+		require.NoError(t, err)
+		values := make(map[string]string, len(attributes))
+		for _, a := range attributes {
+			values[a.Key] = a.Value
+		}
+		got, err := MessageFromAttributes(func(key string) string { return values[key] })
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, `{"schema_version":2}`, values[AttributeMetadata])
+		assert.Equal(t, "7", values[AttributeSequence])
+		want := *msg
+		want.EventTopic, want.Payload = "", nil
+		want.Metadata = json.RawMessage(`{"schema_version":2}`)
+		assert.Equal(t, &want, got)
+	})
+
+	t.Run("skips empty values", func(t *testing.T) {
+		// Given
+		msg := &Message{EventID: "e-1", EventDomain: "shop", EventType: "order.created", ObjectType: "order"}
+
+		// When
+		attributes, err := MessageAttributes(msg)
+
+		// Then
+		require.NoError(t, err)
+		var keys []string
+		for _, a := range attributes {
+			keys = append(keys, a.Key)
+		}
+		assert.Equal(t, []string{AttributeEventID, AttributeEventDomain, AttributeEventType, AttributeObjectType, AttributeCreatedAt}, keys)
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		// Given
+		msg := &Message{EventID: "e-1", Metadata: json.RawMessage(`{`)}
+
+		// When
+		_, err := MessageAttributes(msg)
+
+		// Then
+		var marshalErr *MarshalError
+		assert.ErrorAs(t, err, &marshalErr)
+	})
+}
+
+func TestMessageFromAttributes_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		val  string
+	}{
+		{name: "created at", key: AttributeCreatedAt, val: "yesterday"},
+		{name: "sequence", key: AttributeSequence, val: "first"},
+		{name: "metadata", key: AttributeMetadata, val: "{"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			_, err := MessageFromAttributes(func(key string) string {
+				if key == tt.key {
+					return tt.val
+				}
+				return ""
+			})
+
+			// Then
+			assert.ErrorContains(t, err, "invalid "+tt.key+" attribute")
+		})
+	}
+}
@@ -0,0 +1,389 @@
+package outbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CloudEvents 1.0 constants.
+const (
+	CloudEventsSpecVersion = "1.0"
+
+	// CloudEventsContentType is the content type of a structured mode CloudEvent.
+	CloudEventsContentType = "application/cloudevents+json"
+
+	cloudEventsDataContentType = "application/json"
+	cloudEventsHTTPPrefix      = "Ce-"
+	cloudEventsKafkaPrefix     = "ce_"
+	contentTypeHeader          = "Content-Type"
+	kafkaContentTypeHeader     = "content-type"
+)
+
+// CloudEvent attribute and extension names used for Message fields.
+//
+//	EventID       -> id
+//	Producer      -> source (EventDomain when Producer is empty) and producer
+//	EventType     -> type
+//	CreatedAt     -> time
+//	EventTopic    -> eventtopic
+//	EventDomain   -> eventdomain
+//	ObjectType    -> objecttype
+//	CorrelationID -> correlationid
+//	AggregateKey  -> aggregatekey
+//	Sequence      -> sequence (with an aggregate key only)
+//	Metadata      -> metadata (compact JSON)
+//	Payload       -> data
+const (
+	ceID              = "id"
+	ceSource          = "source"
+	ceSpecVersion     = "specversion"
+	ceType            = "type"
+	ceTime            = "time"
+	ceSubject         = "subject"
+	ceDataContentType = "datacontenttype"
+	ceData            = "data"
+	ceEventTopic      = "eventtopic"
+	ceEventDomain     = "eventdomain"
+	ceObjectType      = "objecttype"
+	ceCorrelationID   = "correlationid"
+	ceMetadata        = "metadata"
+	ceAggregateKey    = "aggregatekey"
+	ceSequence        = "sequence"
+	ceProducer        = "producer"
+)
+
+// Header is a binary message header, such as a Kafka record header.
+type Header struct {
+	Key   string
+	Value []byte
+}
+
+// MarshalCloudEvent encodes the message as a structured mode CloudEvent (JSON).
+func MarshalCloudEvent(msg *Message) ([]byte, error) {
+	attrs, err := cloudEventAttributes(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	event := make(map[string]any, len(attrs)+2)
+	for _, attr := range attrs {
+		event[attr.name] = attr.value
+	}
+	if len(msg.Payload) > 0 {
+		event[ceDataContentType] = cloudEventsDataContentType
+		event[ceData] = msg.Payload
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, &MarshalError{Field: "cloud event", Err: err}
+	}
+	return data, nil
+}
+
+// UnmarshalCloudEvent decodes a structured mode CloudEvent (JSON) into a Message.
+func UnmarshalCloudEvent(data []byte) (*Message, error) {
+	var event map[string]json.RawMessage
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCloudEvent, err)
+	}
+
+	attrs := make(map[string]string, len(event))
+	for name, raw := range event {
+		if name == ceData || name == "data_base64" {
+			continue
+		}
+		value, ok, err := cloudEventAttributeValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: attribute %s: %v", ErrInvalidCloudEvent, name, err)
+		}
+		if ok {
+			attrs[name] = value
+		}
+	}
+
+	if _, ok := event["data_base64"]; ok {
+		return nil, fmt.Errorf("%w: binary data is not supported", ErrInvalidCloudEvent)
+	}
+
+	var payload json.RawMessage
+	if raw, ok := event[ceData]; ok && !bytes.Equal(raw, []byte("null")) {
+		payload = raw
+	}
+
+	return messageFromCloudEvent(attrs, payload)
+}
+
+// cloudEventAttributeValue returns the canonical string of a JSON attribute value.
+// Integer and boolean attributes, such as extensions of other producers, are kept as
+// their JSON text, and null marks an absent attribute.
+func cloudEventAttributeValue(raw json.RawMessage) (string, bool, error) {
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return "", false, err
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "", false, nil
+	case string:
+		return v, true, nil
+	case json.Number:
+		return v.String(), true, nil
+	case bool:
+		return strconv.FormatBool(v), true, nil
+	default:
+		return "", false, fmt.Errorf("unsupported type %T", v)
+	}
+}
+
+// WriteCloudEventHTTP writes the message attributes as binary mode HTTP headers
+// and returns the request body.
+func WriteCloudEventHTTP(msg *Message, header http.Header) ([]byte, error) {
+	attrs, err := cloudEventAttributes(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, attr := range attrs {
+		header.Set(cloudEventsHTTPPrefix+attr.name, percentEncode(attr.value))
+	}
+	if len(msg.Payload) > 0 {
+		header.Set(contentTypeHeader, cloudEventsDataContentType)
+	}
+	return msg.Payload, nil
+}
+
+// ReadCloudEventHTTP decodes an HTTP CloudEvent into a Message.
+// Both binary and structured mode are supported, based on the Content-Type header.
+func ReadCloudEventHTTP(header http.Header, body []byte) (*Message, error) {
+	if strings.HasPrefix(header.Get(contentTypeHeader), CloudEventsContentType) {
+		return UnmarshalCloudEvent(body)
+	}
+
+	attrs := make(map[string]string)
+	for key, values := range header {
+		if len(values) == 0 || !strings.HasPrefix(strings.ToLower(key), strings.ToLower(cloudEventsHTTPPrefix)) {
+			continue
+		}
+		value, err := percentDecode(values[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: header %s: %v", ErrInvalidCloudEvent, key, err)
+		}
+		attrs[strings.ToLower(key[len(cloudEventsHTTPPrefix):])] = value
+	}
+
+	return messageFromCloudEvent(attrs, body)
+}
+
+// ToCloudEventKafka encodes the message as binary mode Kafka headers and record value.
+func ToCloudEventKafka(msg *Message) ([]Header, []byte, error) {
+	attrs, err := cloudEventAttributes(msg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	headers := make([]Header, 0, len(attrs)+1)
+	for _, attr := range attrs {
+		headers = append(headers, Header{Key: cloudEventsKafkaPrefix + attr.name, Value: []byte(attr.value)})
+	}
+	if len(msg.Payload) > 0 {
+		headers = append(headers, Header{Key: kafkaContentTypeHeader, Value: []byte(cloudEventsDataContentType)})
+	}
+	return headers, msg.Payload, nil
+}
+
+// FromCloudEventKafka decodes a Kafka CloudEvent into a Message.
+// Both binary and structured mode are supported, based on the content-type header.
+func FromCloudEventKafka(headers []Header, value []byte) (*Message, error) {
+	attrs := make(map[string]string)
+	for _, h := range headers {
+		key := strings.ToLower(h.Key)
+		if key == kafkaContentTypeHeader && strings.HasPrefix(string(h.Value), CloudEventsContentType) {
+			return UnmarshalCloudEvent(value)
+		}
+		if strings.HasPrefix(key, cloudEventsKafkaPrefix) {
+			attrs[key[len(cloudEventsKafkaPrefix):]] = string(h.Value)
+		}
+	}
+
+	return messageFromCloudEvent(attrs, value)
+}
+
+// cloudEventAttribute is a single context attribute of a CloudEvent
+type cloudEventAttribute struct {
+	name  string
+	value string
+}
+
+// cloudEventAttributes returns the context attributes of the message in a stable order
+func cloudEventAttributes(msg *Message) ([]cloudEventAttribute, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("%w: message is nil", ErrInvalidCloudEvent)
+	}
+	if err := msg.validate(); err != nil {
+		return nil, err
+	}
+
+	source := msg.Producer
+	if source == "" {
+		source = msg.EventDomain
+	}
+
+	attrs := []cloudEventAttribute{
+		{ceSpecVersion, CloudEventsSpecVersion},
+		{ceID, msg.EventID},
+		{ceSource, source},
+		{ceType, msg.EventType},
+		{ceTime, msg.CreatedAt.Format(time.RFC3339Nano)},
+		{ceEventTopic, msg.EventTopic},
+		{ceEventDomain, msg.EventDomain},
+		{ceObjectType, msg.ObjectType},
+	}
+
+	// source alone cannot tell a Producer equal to the EventDomain from an empty one
+	if msg.Producer != "" {
+		attrs = append(attrs, cloudEventAttribute{ceProducer, msg.Producer})
+	}
+
+	if msg.CorrelationID != "" {
+		attrs = append(attrs, cloudEventAttribute{ceCorrelationID, msg.CorrelationID})
+	}
+
+	if msg.AggregateKey != "" {
+		attrs = append(attrs,
+			cloudEventAttribute{ceAggregateKey, msg.AggregateKey},
+			cloudEventAttribute{ceSequence, strconv.FormatInt(msg.Sequence, 10)})
+	}
+
+	if len(msg.Metadata) > 0 {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, msg.Metadata); err != nil {
+			return nil, &MarshalError{Field: "metadata", Err: err}
+		}
+		attrs = append(attrs, cloudEventAttribute{ceMetadata, compact.String()})
+	}
+
+	return attrs, nil
+}
+
+// messageFromCloudEvent builds a Message from CloudEvent attributes.
+// Events from other producers lacking the outbox extensions fall back to
+// source (domain), subject (object type) and type (topic).
+func messageFromCloudEvent(attrs map[string]string, data []byte) (*Message, error) {
+	if v := attrs[ceSpecVersion]; v != CloudEventsSpecVersion {
+		return nil, fmt.Errorf("%w: unsupported specversion '%s'", ErrInvalidCloudEvent, v)
+	}
+	for _, name := range []string{ceID, ceSource, ceType} {
+		if attrs[name] == "" {
+			return nil, fmt.Errorf("%w: missing required attribute %s", ErrInvalidCloudEvent, name)
+		}
+	}
+
+	msg := &Message{
+		EventID:       attrs[ceID],
+		EventTopic:    firstNonEmpty(attrs[ceEventTopic], attrs[ceType]),
+		EventDomain:   firstNonEmpty(attrs[ceEventDomain], attrs[ceSource]),
+		EventType:     attrs[ceType],
+		ObjectType:    firstNonEmpty(attrs[ceObjectType], attrs[ceSubject]),
+		CorrelationID: attrs[ceCorrelationID],
+		AggregateKey:  attrs[ceAggregateKey],
+	}
+
+	if producer, ok := attrs[ceProducer]; ok {
+		msg.Producer = producer
+	} else if source := attrs[ceSource]; source != msg.EventDomain {
+		msg.Producer = source
+	}
+
+	if v := attrs[ceTime]; v != "" {
+		createdAt, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid time '%s'", ErrInvalidCloudEvent, v)
+		}
+		msg.CreatedAt = createdAt
+	}
+
+	if v := attrs[ceSequence]; v != "" {
+		sequence, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid sequence '%s'", ErrInvalidCloudEvent, v)
+		}
+		msg.Sequence = sequence
+	}
+
+	if v := attrs[ceMetadata]; v != "" {
+		if !json.Valid([]byte(v)) {
+			return nil, fmt.Errorf("%w: metadata is not valid JSON", ErrInvalidCloudEvent)
+		}
+		msg.Metadata = json.RawMessage(v)
+	}
+
+	if len(data) > 0 {
+		if !json.Valid(data) {
+			return nil, fmt.Errorf("%w: data is not valid JSON", ErrInvalidCloudEvent)
+		}
+		msg.Payload = append(json.RawMessage(nil), data...)
+	}
+
+	if err := msg.validate(); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// percentEncode encodes a header value as required by the CloudEvents HTTP binding:
+// space, double quote, percent and bytes outside printable ASCII are percent-encoded.
+func percentEncode(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c <= ' ' || c >= 0x7f || c == '"' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// percentDecode reverses percentEncode
+func percentDecode(value string) (string, error) {
+	if !strings.Contains(value, "%") {
+		return value, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '%' {
+			b.WriteByte(value[i])
+			continue
+		}
+		if i+2 >= len(value) {
+			return "", fmt.Errorf("truncated escape sequence")
+		}
+		c, err := strconv.ParseUint(value[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", fmt.Errorf("invalid escape sequence '%s'", value[i:i+3])
+		}
+		b.WriteByte(byte(c))
+		i += 2
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,239 @@
+package outbox
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCloudEventTestMessage() *Message {
+	return &Message{
+		EventID:       uuid.New().String(),
+		EventTopic:    "test.topic",
+		EventDomain:   "test.domain",
+		EventType:     "test.type",
+		ObjectType:    "test.object",
+		Producer:      "test.producer",
+		CorrelationID: "test.correlation",
+		Payload:       json.RawMessage(`{"key":"value"}`),
+		Metadata:      json.RawMessage(`{"meta":"da ta \"ü\" 100%"}`),
+		CreatedAt:     time.Date(2024, 1, 15, 10, 30, 0, 123456789, time.UTC),
+	}
+}
+
+func TestCloudEvent_Structured(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		// Given
+		msg := newCloudEventTestMessage()
+
+		// When
+		data, err := MarshalCloudEvent(msg)
+		require.NoError(t, err)
+		decoded, err := UnmarshalCloudEvent(data)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, msg, decoded)
+	})
+
+	t.Run("standard attributes", func(t *testing.T) {
+		// Given
+		msg := newCloudEventTestMessage()
+
+		// When
+		data, err := MarshalCloudEvent(msg)
+		require.NoError(t, err)
+
+		// Then
+		var event map[string]any
+		require.NoError(t, json.Unmarshal(data, &event))
+		assert.Equal(t, "1.0", event["specversion"])
+		assert.Equal(t, msg.EventID, event["id"])
+		assert.Equal(t, "test.producer", event["source"])
+		assert.Equal(t, "test.type", event["type"])
+		assert.Equal(t, "2024-01-15T10:30:00.123456789Z", event["time"])
+		assert.Equal(t, "test.correlation", event["correlationid"])
+		assert.Equal(t, "application/json", event["datacontenttype"])
+		assert.Equal(t, map[string]any{"key": "value"}, event["data"])
+	})
+
+	t.Run("empty producer falls back to domain", func(t *testing.T) {
+		// Given
+		msg := newCloudEventTestMessage()
+		msg.Producer = ""
+
+		// When
+		data, err := MarshalCloudEvent(msg)
+		require.NoError(t, err)
+		decoded, err := UnmarshalCloudEvent(data)
+
+		// Then
+		require.NoError(t, err)
+		assert.Empty(t, decoded.Producer)
+		assert.Equal(t, "test.domain", decoded.EventDomain)
+	})
+
+	t.Run("producer equal to domain round trip", func(t *testing.T) {
+		// Given
+		msg := newCloudEventTestMessage()
+		msg.Producer = msg.EventDomain
+
+		// When
+		data, err := MarshalCloudEvent(msg)
+		require.NoError(t, err)
+		decoded, err := UnmarshalCloudEvent(data)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, msg, decoded)
+		assert.Equal(t, "test.domain", decoded.Producer)
+	})
+
+	t.Run("foreign event without extensions", func(t *testing.T) {
+		// Given
+		data := []byte(`{"specversion":"1.0","id":"1","source":"/orders","type":"order.created","subject":"order","time":"2024-01-15T10:30:00Z","data":{"id":1}}`)
+
+		// When
+		msg, err := UnmarshalCloudEvent(data)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, "order.created", msg.EventTopic)
+		assert.Equal(t, "/orders", msg.EventDomain)
+		assert.Equal(t, "order", msg.ObjectType)
+		assert.JSONEq(t, `{"id":1}`, string(msg.Payload))
+	})
+
+	t.Run("third-party event with non-string extensions", func(t *testing.T) {
+		// Given
+		data := []byte(`{
+			"specversion": "1.0",
+			"id": "A234-1234-1234",
+			"source": "https://github.com/cloudevents/spec/pull",
+			"type": "com.github.pull_request.opened",
+			"subject": "123",
+			"time": "2018-04-05T17:31:00Z",
+			"comexampleextension1": 5,
+			"comexampleextension2": true,
+			"comexampleextension3": null,
+			"aggregatekey": "pull-123",
+			"sequence": 7,
+			"datacontenttype": "application/json",
+			"data": {"number": 123}
+		}`)
+
+		// When
+		msg, err := UnmarshalCloudEvent(data)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, "A234-1234-1234", msg.EventID)
+		assert.Equal(t, "com.github.pull_request.opened", msg.EventType)
+		assert.Equal(t, "pull-123", msg.AggregateKey)
+		assert.Equal(t, int64(7), msg.Sequence)
+		assert.JSONEq(t, `{"number":123}`, string(msg.Payload))
+	})
+
+	t.Run("object attribute", func(t *testing.T) {
+		// When
+		_, err := UnmarshalCloudEvent([]byte(`{"specversion":"1.0","id":"1","source":"s","type":"t","ext":{"a":1}}`))
+
+		// Then
+		assert.ErrorIs(t, err, ErrInvalidCloudEvent)
+	})
+
+	t.Run("invalid spec version", func(t *testing.T) {
+		// When
+		_, err := UnmarshalCloudEvent([]byte(`{"specversion":"0.3","id":"1","source":"s","type":"t"}`))
+
+		// Then
+		assert.ErrorIs(t, err, ErrInvalidCloudEvent)
+	})
+}
+
+func TestCloudEvent_HTTPBinary(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		// Given
+		msg := newCloudEventTestMessage()
+		header := http.Header{}
+
+		// When
+		body, err := WriteCloudEventHTTP(msg, header)
+		require.NoError(t, err)
+		decoded, err := ReadCloudEventHTTP(header, body)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, msg, decoded)
+		assert.Equal(t, "1.0", header.Get("ce-specversion"))
+		assert.Equal(t, msg.EventID, header.Get("ce-id"))
+		assert.Equal(t, "application/json", header.Get("Content-Type"))
+		assert.Equal(t, `{%22meta%22:%22da%20ta%20\%22%C3%BC\%22%20100%25%22}`, header.Get("ce-metadata"))
+	})
+
+	t.Run("structured content type", func(t *testing.T) {
+		// Given
+		msg := newCloudEventTestMessage()
+		data, err := MarshalCloudEvent(msg)
+		require.NoError(t, err)
+		header := http.Header{}
+		header.Set("Content-Type", CloudEventsContentType+"; charset=utf-8")
+
+		// When
+		decoded, err := ReadCloudEventHTTP(header, data)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, msg, decoded)
+	})
+}
+
+func TestCloudEvent_Kafka(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		// Given
+		msg := newCloudEventTestMessage()
+
+		// When
+		headers, value, err := ToCloudEventKafka(msg)
+		require.NoError(t, err)
+		decoded, err := FromCloudEventKafka(headers, value)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, msg, decoded)
+		assert.Contains(t, headers, Header{Key: "ce_id", Value: []byte(msg.EventID)})
+		assert.Contains(t, headers, Header{Key: "content-type", Value: []byte("application/json")})
+	})
+
+	t.Run("producer equal to domain round trip", func(t *testing.T) {
+		// Given
+		msg := newCloudEventTestMessage()
+		msg.Producer = msg.EventDomain
+
+		// When
+		headers, value, err := ToCloudEventKafka(msg)
+		require.NoError(t, err)
+		decoded, err := FromCloudEventKafka(headers, value)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, msg, decoded)
+		assert.Contains(t, headers, Header{Key: "ce_producer", Value: []byte("test.domain")})
+	})
+
+	t.Run("missing required attribute", func(t *testing.T) {
+		// Given
+		headers := []Header{{Key: "ce_specversion", Value: []byte("1.0")}, {Key: "ce_id", Value: []byte("1")}}
+
+		// When
+		_, err := FromCloudEventKafka(headers, nil)
+
+		// Then
+		assert.ErrorIs(t, err, ErrInvalidCloudEvent)
+	})
+}
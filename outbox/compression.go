@@ -0,0 +1,177 @@
+package outbox
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// MetadataContentEncoding records the compression of a compressed payload
+const MetadataContentEncoding = "content_encoding"
+
+// Compression is a payload compression algorithm
+type Compression string
+
+const (
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+// defaultCompressionThreshold is the payload size from which payloads are compressed
+const defaultCompressionThreshold = 4 << 10
+
+// compressedPayload is the payload of a compressed message
+type compressedPayload struct {
+	Data []byte `json:"data"`
+}
+
+type compressorConfig struct {
+	threshold int
+}
+
+// CompressorOption defines a function that configures a PayloadCompressor
+type CompressorOption func(*compressorConfig) error
+
+// WithCompressionThreshold sets the payload size in bytes from which payloads are compressed, 4 KiB by default
+func WithCompressionThreshold(threshold int) CompressorOption {
+	return func(c *compressorConfig) error {
+		if threshold < 0 {
+			return fmt.Errorf("compression threshold cannot be negative")
+		}
+		c.threshold = threshold
+		return nil
+	}
+}
+
+// PayloadCompressor compresses payloads above a size threshold.
+// The payload is kept a JSON object holding the compressed data, and the algorithm is
+// recorded in the metadata. Payloads that do not get smaller are left as is.
+type PayloadCompressor struct {
+	compression Compression
+	config      compressorConfig
+}
+
+var (
+	_ PayloadTransformer = (*PayloadCompressor)(nil)
+	_ PayloadRestorer    = (*PayloadCompressor)(nil)
+)
+
+// NewPayloadCompressor creates a new PayloadCompressor with the compression algorithm.
+// It restores payloads compressed with any supported algorithm.
+func NewPayloadCompressor(compression Compression, opts ...CompressorOption) (*PayloadCompressor, error) {
+	if compression != CompressionGzip && compression != CompressionZstd {
+		return nil, fmt.Errorf("unsupported compression: %s", compression)
+	}
+
+	config := compressorConfig{threshold: defaultCompressionThreshold}
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return nil, fmt.Errorf("failed to apply compressor option: %w", err)
+		}
+	}
+	return &PayloadCompressor{compression: compression, config: config}, nil
+}
+
+// Transform compresses the payload of the message when it reaches the threshold
+func (c *PayloadCompressor) Transform(_ context.Context, msg *Message) error {
+	if len(msg.Payload) == 0 || len(msg.Payload) < c.config.threshold {
+		return nil
+	}
+	if _, ok := MetadataString(msg, MetadataContentEncoding); ok {
+		return fmt.Errorf("payload of message %s is already compressed", msg.EventID)
+	}
+
+	data, err := compress(c.compression, msg.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to compress payload of message %s: %w", msg.EventID, err)
+	}
+	payload, err := json.Marshal(compressedPayload{Data: data})
+	if err != nil {
+		return &MarshalError{Field: "payload", Err: err}
+	}
+	if len(payload) >= len(msg.Payload) {
+		return nil
+	}
+
+	if err := setMetadata(msg, map[string]any{MetadataContentEncoding: c.compression}); err != nil {
+		return err
+	}
+	msg.Payload = payload
+	return nil
+}
+
+// Restore decompresses the payload of a message compressed by Transform and removes the
+// compression from its metadata; other messages are left as is
+func (c *PayloadCompressor) Restore(_ context.Context, msg *Message) error {
+	encoding, ok := MetadataString(msg, MetadataContentEncoding)
+	if !ok {
+		return nil
+	}
+
+	var compressed compressedPayload
+	if err := json.Unmarshal(msg.Payload, &compressed); err != nil {
+		return fmt.Errorf("failed to decompress payload of message %s: %w", msg.EventID, err)
+	}
+	payload, err := decompress(Compression(encoding), compressed.Data)
+	if err != nil {
+		return fmt.Errorf("failed to decompress payload of message %s: %w", msg.EventID, err)
+	}
+
+	if err := setMetadata(msg, map[string]any{MetadataContentEncoding: nil}); err != nil {
+		return err
+	}
+	msg.Payload = payload
+	return nil
+}
+
+func compress(compression Compression, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch compression {
+	case CompressionGzip:
+		w = gzip.NewWriter(&buf)
+	case CompressionZstd:
+		encoder, err := zstd.NewWriter(&buf)
+		if err != nil {
+			return nil, err
+		}
+		w = encoder
+	default:
+		return nil, fmt.Errorf("unsupported compression: %s", compression)
+	}
+
+	if _, err := w.Write(data); err != nil {
+		_ = w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompress(compression Compression, data []byte) ([]byte, error) {
+	switch compression {
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	case CompressionZstd:
+		decoder, err := zstd.NewReader(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer decoder.Close()
+		return decoder.DecodeAll(data, nil)
+	default:
+		return nil, fmt.Errorf("unsupported compression: %s", compression)
+	}
+}
@@ -0,0 +1,79 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadCompressor(t *testing.T) {
+	large := `{"items":"` + strings.Repeat("abc", 2000) + `"}`
+
+	for _, compression := range []Compression{CompressionGzip, CompressionZstd} {
+		t.Run(string(compression), func(t *testing.T) {
+			// Given
+			compressor, err := NewPayloadCompressor(compression)
+			require.NoError(t, err)
+			msg := newTransformTestMessage(t, large)
+
+			// When
+			err = compressor.Transform(context.Background(), msg)
+
+			// Then
+			require.NoError(t, err)
+			assert.Less(t, len(msg.Payload), len(large))
+			assert.True(t, json.Valid(msg.Payload))
+			encoding, ok := MetadataString(msg, MetadataContentEncoding)
+			assert.True(t, ok)
+			assert.Equal(t, string(compression), encoding)
+
+			require.NoError(t, compressor.Restore(context.Background(), msg))
+			assert.JSONEq(t, large, string(msg.Payload))
+			assert.JSONEq(t, `{"tenant":"acme"}`, string(msg.Metadata))
+		})
+	}
+
+	t.Run("payloads below the threshold are left as is", func(t *testing.T) {
+		// Given
+		compressor, err := NewPayloadCompressor(CompressionGzip, WithCompressionThreshold(1024))
+		require.NoError(t, err)
+		msg := newTransformTestMessage(t, `{"n":1}`)
+
+		// When
+		err = compressor.Transform(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"n":1}`, string(msg.Payload))
+		assert.JSONEq(t, `{"tenant":"acme"}`, string(msg.Metadata))
+	})
+
+	t.Run("restores payloads of any algorithm", func(t *testing.T) {
+		// Given
+		gzipCompressor, err := NewPayloadCompressor(CompressionGzip, WithCompressionThreshold(0))
+		require.NoError(t, err)
+		zstdCompressor, err := NewPayloadCompressor(CompressionZstd)
+		require.NoError(t, err)
+		msg := newTransformTestMessage(t, large)
+		require.NoError(t, gzipCompressor.Transform(context.Background(), msg))
+
+		// When
+		err = zstdCompressor.Restore(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		assert.JSONEq(t, large, string(msg.Payload))
+	})
+}
+
+func TestNewPayloadCompressor_Invalid(t *testing.T) {
+	_, err := NewPayloadCompressor("lz4")
+	assert.Error(t, err)
+
+	_, err = NewPayloadCompressor(CompressionGzip, WithCompressionThreshold(-1))
+	assert.Error(t, err)
+}
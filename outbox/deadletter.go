@@ -0,0 +1,407 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	defaultDeadLetterLimit = 100
+
+	// maxLastErrorLength bounds the error text stored with a failed message
+	maxLastErrorLength = 4096
+)
+
+// ErrDeadLetterNotFound is returned when no dead letter has the requested EventID
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// deadLetterColumns are the columns of the dead-letter table in scan order
+const deadLetterColumns = "event_id, event_topic, event_domain, event_type, object_type, producer, correlation_id, payload, metadata, created_at, aggregate_key, aggregate_sequence, attempts, last_error, failed_at"
+
+// deadLetterTableName returns the dead-letter table of an outbox table
+func deadLetterTableName(tableName string) string {
+	return tableName + "_dead_letter"
+}
+
+// DeadLetter is a message moved out of the outbox after failing too often
+type DeadLetter struct {
+	Message   *Message
+	Attempts  int       // Failed dispatches before the message was quarantined
+	LastError string    // Error of the last failed dispatch
+	FailedAt  time.Time // When the message was quarantined
+}
+
+// DeadLetterFilter selects dead letters; empty fields match everything
+type DeadLetterFilter struct {
+	EventTopic   string
+	EventDomain  string
+	EventType    string
+	FailedAfter  time.Time
+	FailedBefore time.Time
+	Limit        int // 100 when zero
+	Offset       int
+}
+
+// DeadLetterDB queries the dead-letter table and starts transactions, such as *sql.DB
+type DeadLetterDB interface {
+	MigrationDB
+	TxBeginner
+}
+
+type deadLetterConfig struct {
+	tableName string
+	dialect   Dialect
+}
+
+// DeadLetterOption defines a function that configures a DeadLetterStore
+type DeadLetterOption func(*deadLetterConfig) error
+
+// WithDeadLetterTableName sets the outbox table whose dead letters the store manages.
+// Dead letters are kept in <table>_dead_letter, created by Migrate.
+func WithDeadLetterTableName(tableName string) DeadLetterOption {
+	return func(c *deadLetterConfig) error {
+		sanitizedTableName, err := sanitizeTableName(tableName)
+		if err != nil {
+			return fmt.Errorf("invalid table name: %w", err)
+		}
+		c.tableName = sanitizedTableName
+		return nil
+	}
+}
+
+// WithDeadLetterDialect sets the SQL dialect of the tables.
+// Without it the dialect is detected from a db implementing DriverNamer, or Postgres.
+func WithDeadLetterDialect(dialect Dialect) DeadLetterOption {
+	return func(c *deadLetterConfig) error {
+		if err := dialect.validate(); err != nil {
+			return err
+		}
+		c.dialect = dialect
+		return nil
+	}
+}
+
+// DeadLetterStore lists, edits and replays quarantined messages
+type DeadLetterStore struct {
+	db      DeadLetterDB
+	dialect Dialect
+	table   string // Quoted outbox table
+	dlTable string // Quoted dead-letter table
+}
+
+// NewDeadLetterStore creates a new DeadLetterStore
+func NewDeadLetterStore(db DeadLetterDB, opts ...DeadLetterOption) (*DeadLetterStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db cannot be nil")
+	}
+
+	config := deadLetterConfig{
+		tableName: defaultTableName,
+	}
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return nil, fmt.Errorf("failed to apply dead letter option: %w", err)
+		}
+	}
+	if config.dialect == "" {
+		dialect, err := detectDialect(db)
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect dialect: %w", err)
+		}
+		config.dialect = dialect
+	}
+
+	return &DeadLetterStore{
+		db:      db,
+		dialect: config.dialect,
+		table:   config.dialect.QuoteIdentifier(config.tableName),
+		dlTable: config.dialect.QuoteIdentifier(deadLetterTableName(config.tableName)),
+	}, nil
+}
+
+// List returns the dead letters matching filter, oldest failure first
+func (s *DeadLetterStore) List(ctx context.Context, filter DeadLetterFilter) ([]*DeadLetter, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	where := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, s.dialect.placeholder(len(args))))
+	}
+	if filter.EventTopic != "" {
+		where("event_topic = %s", filter.EventTopic)
+	}
+	if filter.EventDomain != "" {
+		where("event_domain = %s", filter.EventDomain)
+	}
+	if filter.EventType != "" {
+		where("event_type = %s", filter.EventType)
+	}
+	if !filter.FailedAfter.IsZero() {
+		where("failed_at >= %s", filter.FailedAfter.UTC())
+	}
+	if !filter.FailedBefore.IsZero() {
+		where("failed_at < %s", filter.FailedBefore.UTC())
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultDeadLetterLimit
+	}
+
+	query := "SELECT " + deadLetterColumns + " FROM " + s.dlTable
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY failed_at, event_id LIMIT %d OFFSET %d", limit, max(filter.Offset, 0))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var deadLetters []*DeadLetter
+	for rows.Next() {
+		dl, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, err
+		}
+		deadLetters = append(deadLetters, dl)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	return deadLetters, nil
+}
+
+// Get returns the dead letter of a message
+func (s *DeadLetterStore) Get(ctx context.Context, eventID string) (*DeadLetter, error) {
+	return s.get(ctx, s.db, eventID, "")
+}
+
+// Update edits a dead letter with fn before it is replayed, such as to fix its payload.
+// The EventID cannot be changed.
+func (s *DeadLetterStore) Update(ctx context.Context, eventID string, fn func(msg *Message) error) error {
+	if fn == nil {
+		return fmt.Errorf("update func cannot be nil")
+	}
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		dl, err := s.get(ctx, tx, eventID, s.dialect.rowLockClause())
+		if err != nil {
+			return err
+		}
+
+		if err := fn(dl.Message); err != nil {
+			return err
+		}
+		if dl.Message.EventID != eventID {
+			return fmt.Errorf("event id of dead letter %s cannot be changed", eventID)
+		}
+		if err := dl.Message.validate(); err != nil {
+			return err
+		}
+
+		columns := []string{"event_topic", "event_domain", "event_type", "object_type", "producer", "correlation_id", "payload", "metadata"}
+		sets := make([]string, len(columns))
+		for i, column := range columns {
+			sets[i] = column + " = " + s.dialect.placeholder(i+1)
+		}
+		query := fmt.Sprintf("UPDATE %s SET %s WHERE event_id = %s", s.dlTable, strings.Join(sets, ", "), s.dialect.placeholder(len(columns)+1))
+
+		msg := dl.Message
+		if _, err := tx.ExecContext(ctx, query, msg.EventTopic, msg.EventDomain, msg.EventType, msg.ObjectType, msg.Producer,
+			msg.CorrelationID, s.dialect.jsonValue(msg.Payload), s.dialect.jsonValue(msg.Metadata), eventID); err != nil {
+			return fmt.Errorf("failed to update dead letter %s: %w", eventID, err)
+		}
+		return nil
+	})
+}
+
+// Replay moves dead letters back to the outbox table with no failed attempts,
+// so the relay dispatches them again. It returns the number of messages replayed.
+// A replayed message keeps its aggregate sequence, so it holds back later messages of its key.
+func (s *DeadLetterStore) Replay(ctx context.Context, eventIDs ...string) (int, error) {
+	if len(eventIDs) == 0 {
+		return 0, nil
+	}
+
+	const columns = "event_id, event_topic, event_domain, event_type, object_type, producer, correlation_id, payload, metadata, created_at, aggregate_key, aggregate_sequence"
+	in := s.dialect.placeholders(1, len(eventIDs))
+	args := stringArgs(eventIDs)
+
+	var replayed int64
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s WHERE event_id IN (%s)",
+			s.table, columns, columns, s.dlTable, in), args...)
+		if err != nil {
+			return fmt.Errorf("failed to replay dead letters: %w", err)
+		}
+		if replayed, err = result.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to replay dead letters: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE event_id IN (%s)", s.dlTable, in), args...); err != nil {
+			return fmt.Errorf("failed to remove replayed dead letters: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(replayed), nil
+}
+
+// Discard deletes dead letters for good. It returns the number of messages deleted.
+func (s *DeadLetterStore) Discard(ctx context.Context, eventIDs ...string) (int, error) {
+	if len(eventIDs) == 0 {
+		return 0, nil
+	}
+
+	result, err := s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE event_id IN (%s)",
+		s.dlTable, s.dialect.placeholders(1, len(eventIDs))), stringArgs(eventIDs)...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to discard dead letters: %w", err)
+	}
+	discarded, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to discard dead letters: %w", err)
+	}
+	return int(discarded), nil
+}
+
+// Quarantine stores a message in the dead-letter table, such as a message a
+// consumer keeps failing on, so it can be inspected and replayed later.
+func (s *DeadLetterStore) Quarantine(ctx context.Context, msg *Message, attempts int, cause error) error {
+	if msg == nil {
+		return fmt.Errorf("message cannot be nil")
+	}
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	dl := &DeadLetter{Message: msg, Attempts: attempts, LastError: errorText(cause), FailedAt: time.Now().UTC()}
+	if _, err := s.db.ExecContext(ctx, deadLetterInsertQuery(s.dialect, s.dlTable), deadLetterArgs(s.dialect, dl)...); err != nil {
+		return fmt.Errorf("failed to quarantine message %s: %w", msg.EventID, err)
+	}
+	return nil
+}
+
+// inTx runs fn in a transaction committed when fn returns nil
+func (s *DeadLetterStore) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// get reads one dead letter through q, a *sql.DB or *sql.Tx
+func (s *DeadLetterStore) get(ctx context.Context, q interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}, eventID string, lockClause string) (*DeadLetter, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE event_id = %s%s", deadLetterColumns, s.dlTable, s.dialect.placeholder(1), lockClause)
+	rows, err := q.QueryContext(ctx, query, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letter %s: %w", eventID, err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to get dead letter %s: %w", eventID, err)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrDeadLetterNotFound, eventID)
+	}
+	return scanDeadLetter(rows)
+}
+
+// scanDeadLetter reads a row selected with deadLetterColumns
+func scanDeadLetter(rows *sql.Rows) (*DeadLetter, error) {
+	var (
+		msg                                Message
+		dl                                 = DeadLetter{Message: &msg}
+		producer, correlationID, lastError sql.NullString
+		payload, metadata                  []byte
+		aggregateKey                       sql.NullString
+		sequence                           sql.NullInt64
+	)
+	if err := rows.Scan(&msg.EventID, &msg.EventTopic, &msg.EventDomain, &msg.EventType, &msg.ObjectType,
+		&producer, &correlationID, &payload, &metadata, scanTime{&msg.CreatedAt}, &aggregateKey, &sequence,
+		&dl.Attempts, &lastError, scanTime{&dl.FailedAt}); err != nil {
+		return nil, fmt.Errorf("failed to scan dead letter: %w", err)
+	}
+
+	msg.Producer = producer.String
+	msg.CorrelationID = correlationID.String
+	msg.AggregateKey = aggregateKey.String
+	msg.Sequence = sequence.Int64
+	dl.LastError = lastError.String
+	if len(payload) > 0 {
+		msg.Payload = json.RawMessage(payload)
+	}
+	if len(metadata) > 0 {
+		msg.Metadata = json.RawMessage(metadata)
+	}
+	return &dl, nil
+}
+
+// deadLetterInsertQuery returns the statement storing a dead letter, see deadLetterArgs
+func deadLetterInsertQuery(dialect Dialect, dlTable string) string {
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", dlTable, deadLetterColumns, dialect.placeholders(1, 15))
+}
+
+// deadLetterArgs returns the arguments of deadLetterInsertQuery
+func deadLetterArgs(dialect Dialect, dl *DeadLetter) []any {
+	msg := dl.Message
+	return []any{
+		msg.EventID, msg.EventTopic, msg.EventDomain, msg.EventType, msg.ObjectType, msg.Producer, msg.CorrelationID,
+		dialect.jsonValue(msg.Payload), dialect.jsonValue(msg.Metadata), msg.CreatedAt.UTC(),
+		nullString(msg.AggregateKey), nullSequence(msg), dl.Attempts, dl.LastError, dl.FailedAt,
+	}
+}
+
+// errorText returns the stored text of a failure, truncated to maxLastErrorLength bytes
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	text := err.Error()
+	if len(text) > maxLastErrorLength {
+		text = strings.ToValidUTF8(text[:maxLastErrorLength], "")
+	}
+	return text
+}
+
+// nullString stores an empty string as NULL
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// nullSequence stores the sequence of a message without an aggregate key as NULL
+func nullSequence(msg *Message) sql.NullInt64 {
+	return sql.NullInt64{Int64: msg.Sequence, Valid: msg.AggregateKey != ""}
+}
+
+func stringArgs(values []string) []any {
+	args := make([]any, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
@@ -0,0 +1,212 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var deadLetterRowColumns = []string{"event_id", "event_topic", "event_domain", "event_type", "object_type", "producer", "correlation_id", "payload", "metadata", "created_at", "aggregate_key", "aggregate_sequence", "attempts", "last_error", "failed_at"}
+
+func deadLetterRows(ids ...string) *sqlmock.Rows {
+	rows := sqlmock.NewRows(deadLetterRowColumns)
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, id := range ids {
+		rows.AddRow(id, "test.topic", "test.domain", "test.type", "test.object", "producer", nil, []byte(`{"n":1}`), nil, createdAt, nil, nil, 10, "broker unavailable", createdAt.Add(time.Hour))
+	}
+	return rows
+}
+
+func TestDeadLetterStore_List(t *testing.T) {
+	// Given
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	store, err := NewDeadLetterStore(db)
+	require.NoError(t, err)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT `+deadLetterColumns+` FROM "outbox_message_dead_letter" WHERE event_domain = $1 AND failed_at < $2 ORDER BY failed_at, event_id LIMIT 100 OFFSET 0`)).
+		WithArgs("test.domain", sqlmock.AnyArg()).
+		WillReturnRows(deadLetterRows("id-1", "id-2"))
+
+	// When
+	deadLetters, err := store.List(context.Background(), DeadLetterFilter{EventDomain: "test.domain", FailedBefore: time.Now()})
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 2)
+	assert.Equal(t, "id-1", deadLetters[0].Message.EventID)
+	assert.Equal(t, "producer", deadLetters[0].Message.Producer)
+	assert.JSONEq(t, `{"n":1}`, string(deadLetters[0].Message.Payload))
+	assert.Equal(t, 10, deadLetters[0].Attempts)
+	assert.Equal(t, "broker unavailable", deadLetters[0].LastError)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeadLetterStore_Get(t *testing.T) {
+	// Given
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	store, err := NewDeadLetterStore(db, WithDeadLetterDialect(DialectMySQL))
+	require.NoError(t, err)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM `outbox_message_dead_letter` WHERE event_id = ?")).
+		WithArgs("missing").
+		WillReturnRows(deadLetterRows())
+
+	// When
+	_, err = store.Get(context.Background(), "missing")
+
+	// Then
+	assert.ErrorIs(t, err, ErrDeadLetterNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeadLetterStore_Update(t *testing.T) {
+	t.Run("edits the payload", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		store, err := NewDeadLetterStore(db)
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`WHERE event_id = $1 FOR UPDATE`)).WithArgs("id-1").WillReturnRows(deadLetterRows("id-1"))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "outbox_message_dead_letter" SET event_topic = $1, event_domain = $2, event_type = $3, object_type = $4, producer = $5, correlation_id = $6, payload = $7, metadata = $8 WHERE event_id = $9`)).
+			WithArgs("test.topic", "test.domain", "test.type", "test.object", "producer", "", []byte(`{"n":2}`), nil, "id-1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// When
+		err = store.Update(context.Background(), "id-1", func(msg *Message) error {
+			msg.Payload = json.RawMessage(`{"n":2}`)
+			return nil
+		})
+
+		// Then
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("event id cannot change", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		store, err := NewDeadLetterStore(db)
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`FOR UPDATE`)).WillReturnRows(deadLetterRows("id-1"))
+		mock.ExpectRollback()
+
+		// When
+		err = store.Update(context.Background(), "id-1", func(msg *Message) error {
+			msg.EventID = "id-2"
+			return nil
+		})
+
+		// Then
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeadLetterStore_Replay(t *testing.T) {
+	// Given
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	store, err := NewDeadLetterStore(db)
+	require.NoError(t, err)
+
+	const columns = "event_id, event_topic, event_domain, event_type, object_type, producer, correlation_id, payload, metadata, created_at, aggregate_key, aggregate_sequence"
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_message" (`+columns+`) SELECT `+columns+` FROM "outbox_message_dead_letter" WHERE event_id IN ($1, $2)`)).
+		WithArgs("id-1", "id-2").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "outbox_message_dead_letter" WHERE event_id IN ($1, $2)`)).
+		WithArgs("id-1", "id-2").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	// When
+	replayed, err := store.Replay(context.Background(), "id-1", "id-2")
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, 2, replayed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeadLetterStore_Discard(t *testing.T) {
+	// Given
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	store, err := NewDeadLetterStore(db, WithDeadLetterTableName("app.events"))
+	require.NoError(t, err)
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "app"."events_dead_letter" WHERE event_id IN ($1)`)).
+		WithArgs("id-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// When
+	discarded, err := store.Discard(context.Background(), "id-1")
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, 1, discarded)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeadLetterStore_Quarantine(t *testing.T) {
+	// Given
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	store, err := NewDeadLetterStore(db)
+	require.NoError(t, err)
+
+	msg, err := NewMessageBuilder().
+		SetEventTopic("orders").
+		SetEventDomain("shop").
+		SetEventType("order.created").
+		SetObjectType("order").
+		Build()
+	require.NoError(t, err)
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_message_dead_letter"`)).
+		WithArgs(msg.EventID, "orders", "shop", "order.created", "order", "", "", nil, nil, sqlmock.AnyArg(), nil, nil, 5, "handler failed", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// When
+	err = store.Quarantine(context.Background(), msg, 5, errors.New("handler failed"))
+
+	// Then
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestErrorText(t *testing.T) {
+	long := errors.New(string(make([]byte, maxLastErrorLength+10)))
+
+	assert.Empty(t, errorText(nil))
+	assert.Len(t, errorText(long), maxLastErrorLength)
+}
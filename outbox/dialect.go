@@ -0,0 +1,172 @@
+package outbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect identifies the SQL dialect of the outbox table.
+// Values match the entx database drivers.
+type Dialect string
+
+const (
+	DialectPostgres Dialect = "postgres"
+	DialectMySQL    Dialect = "mysql"
+	DialectSQLite   Dialect = "sqlite"
+)
+
+// DriverNamer is implemented by database handles that know their driver, such as entx.DB.
+// Publisher, Relay and Migrate use it to detect the dialect when none is configured.
+type DriverNamer interface {
+	DriverName() string
+}
+
+// DialectFromDriver returns the dialect of a database/sql driver or entx driver name
+func DialectFromDriver(driverName string) (Dialect, error) {
+	switch strings.ToLower(driverName) {
+	case "postgres", "postgresql", "pgx", "pgx/v5":
+		return DialectPostgres, nil
+	case "mysql":
+		return DialectMySQL, nil
+	case "sqlite", "sqlite3":
+		return DialectSQLite, nil
+	default:
+		return "", fmt.Errorf("unsupported driver '%s'", driverName)
+	}
+}
+
+// detectDialect returns the dialect of db if it implements DriverNamer, or Postgres otherwise
+func detectDialect(db any) (Dialect, error) {
+	namer, ok := db.(DriverNamer)
+	if !ok {
+		return DialectPostgres, nil
+	}
+	return DialectFromDriver(namer.DriverName())
+}
+
+// validate checks that the dialect is supported
+func (d Dialect) validate() error {
+	switch d {
+	case DialectPostgres, DialectMySQL, DialectSQLite:
+		return nil
+	default:
+		return fmt.Errorf("unsupported dialect '%s'", d)
+	}
+}
+
+// placeholder returns the n-th (1-based) positional parameter
+func (d Dialect) placeholder(n int) string {
+	if d == DialectPostgres {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// placeholders returns n comma separated positional parameters starting at the start-th
+func (d Dialect) placeholders(start, n int) string {
+	params := make([]string, n)
+	for i := range params {
+		params[i] = d.placeholder(start + i)
+	}
+	return strings.Join(params, ", ")
+}
+
+// QuoteIdentifier quotes a validated identifier, quoting each part of schema.table separately.
+// Quoted identifiers are case-sensitive.
+func (d Dialect) QuoteIdentifier(identifier string) string {
+	quote := `"`
+	if d == DialectMySQL {
+		quote = "`"
+	}
+
+	parts := strings.Split(identifier, ".")
+	for i, part := range parts {
+		parts[i] = quote + part + quote
+	}
+	return strings.Join(parts, ".")
+}
+
+// JSONType returns the column type used for JSON payloads
+func (d Dialect) JSONType() string {
+	switch d {
+	case DialectPostgres:
+		return "JSONB"
+	case DialectMySQL:
+		return "JSON"
+	default:
+		return "TEXT"
+	}
+}
+
+// lockClause returns the clause claiming rows without waiting for other relays.
+// SQLite has no row locks; its writers are serialized by the database lock.
+func (d Dialect) lockClause() string {
+	if d == DialectSQLite {
+		return ""
+	}
+	return " FOR UPDATE SKIP LOCKED"
+}
+
+// rowLockClause returns the clause locking selected rows until the transaction ends
+func (d Dialect) rowLockClause() string {
+	if d == DialectSQLite {
+		return ""
+	}
+	return " FOR UPDATE"
+}
+
+// jsonValue converts a JSON column value to a driver argument.
+// MySQL rejects binary strings for JSON columns, and SQLite stores them as BLOB.
+func (d Dialect) jsonValue(raw json.RawMessage) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	if d == DialectPostgres {
+		return []byte(raw)
+	}
+	return string(raw)
+}
+
+// timestampFormats are the text forms of timestamp columns, as returned by MySQL
+// without parseTime=true in the DSN and by SQLite
+var timestampFormats = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999",
+	time.RFC3339Nano,
+}
+
+// scanTime scans a timestamp column into t, whether the driver returns a time.Time or text.
+// Text without a zone is UTC, as written by the outbox.
+type scanTime struct {
+	t *time.Time
+}
+
+func (s scanTime) Scan(src any) error {
+	var text string
+	switch v := src.(type) {
+	case time.Time:
+		*s.t = v
+		return nil
+	case nil:
+		*s.t = time.Time{}
+		return nil
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	default:
+		return fmt.Errorf("cannot scan %T into a timestamp", src)
+	}
+
+	for _, layout := range timestampFormats {
+		if t, err := time.ParseInLocation(layout, text, time.UTC); err == nil {
+			*s.t = t
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid timestamp '%s'", text)
+}
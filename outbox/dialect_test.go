@@ -0,0 +1,163 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// namedExecutor is an executor reporting its driver name like entx.DB
+type namedExecutor struct {
+	driver  string
+	queries []string
+	args    [][]interface{}
+}
+
+func (e *namedExecutor) DriverName() string {
+	return e.driver
+}
+
+func (e *namedExecutor) ExecContext(_ context.Context, query string, args ...interface{}) (sql.Result, error) {
+	e.queries = append(e.queries, query)
+	e.args = append(e.args, args)
+	return &MockResult{}, nil
+}
+
+func TestDialectFromDriver(t *testing.T) {
+	tests := []struct {
+		driver   string
+		expected Dialect
+	}{
+		{"postgres", DialectPostgres},
+		{"pgx", DialectPostgres},
+		{"mysql", DialectMySQL},
+		{"sqlite3", DialectSQLite},
+		{"sqlite", DialectSQLite},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.driver, func(t *testing.T) {
+			dialect, err := DialectFromDriver(tt.driver)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, dialect)
+		})
+	}
+
+	_, err := DialectFromDriver("mssql")
+	assert.Error(t, err)
+}
+
+func TestDialect(t *testing.T) {
+	assert.Equal(t, `"app"."outbox"`, DialectPostgres.QuoteIdentifier("app.outbox"))
+	assert.Equal(t, "`app`.`outbox`", DialectMySQL.QuoteIdentifier("app.outbox"))
+	assert.Equal(t, `"outbox"`, DialectSQLite.QuoteIdentifier("outbox"))
+
+	assert.Equal(t, "$2, $3", DialectPostgres.placeholders(2, 2))
+	assert.Equal(t, "?, ?", DialectMySQL.placeholders(2, 2))
+
+	assert.Equal(t, "JSONB", DialectPostgres.JSONType())
+	assert.Equal(t, "JSON", DialectMySQL.JSONType())
+	assert.Equal(t, "TEXT", DialectSQLite.JSONType())
+
+	assert.Equal(t, []byte(`{}`), DialectPostgres.jsonValue(json.RawMessage(`{}`)))
+	assert.Equal(t, `{}`, DialectMySQL.jsonValue(json.RawMessage(`{}`)))
+	assert.Nil(t, DialectSQLite.jsonValue(nil))
+}
+
+func TestPublisherDialect(t *testing.T) {
+	msg := &Message{
+		EventID:     "test-event-1",
+		EventTopic:  "test-topic",
+		EventDomain: "test-domain",
+		EventType:   "test-type",
+		ObjectType:  "test-object",
+		Payload:     json.RawMessage(`{"test": "data"}`),
+		CreatedAt:   time.Now(),
+	}
+
+	t.Run("postgres by default", func(t *testing.T) {
+		// Given
+		executor := &MockExecutor{}
+		pub, err := NewPublisher(executor)
+		require.NoError(t, err)
+
+		// Then
+		assert.Equal(t, `INSERT INTO "outbox_message" (event_id, event_topic, event_domain, event_type, object_type, producer, correlation_id, payload, metadata, created_at, deliver_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`, pub.(*publisher).insertQuery(1))
+	})
+
+	t.Run("detected from driver name", func(t *testing.T) {
+		// Given
+		executor := &namedExecutor{driver: "sqlite"}
+		pub, err := NewPublisher(executor)
+		require.NoError(t, err)
+
+		// When
+		err = pub.Publish(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		require.Len(t, executor.queries, 1)
+		assert.Contains(t, executor.queries[0], `INSERT INTO "outbox_message"`)
+		assert.Contains(t, executor.queries[0], "VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		assert.Equal(t, `{"test": "data"}`, executor.args[0][7])
+	})
+
+	t.Run("explicit dialect wins", func(t *testing.T) {
+		// Given
+		executor := &namedExecutor{driver: "sqlite"}
+
+		// When
+		pub, err := NewPublisher(executor, WithDialect(DialectPostgres))
+
+		// Then
+		require.NoError(t, err)
+		assert.Contains(t, pub.(*publisher).insertQuery(1), "$10")
+	})
+
+	t.Run("unsupported driver", func(t *testing.T) {
+		_, err := NewPublisher(&namedExecutor{driver: "mssql"})
+		assert.Error(t, err)
+
+		_, err = NewPublisher(&MockExecutor{}, WithDialect("oracle"))
+		assert.Error(t, err)
+	})
+}
+
+func TestScanTime(t *testing.T) {
+	want := time.Date(2024, 1, 15, 10, 30, 0, 123456000, time.UTC)
+	tests := []struct {
+		name string
+		src  any
+		want time.Time
+	}{
+		{name: "time", src: want, want: want},
+		{name: "mysql text", src: []byte("2024-01-15 10:30:00.123456"), want: want},
+		{name: "sqlite text", src: "2024-01-15 10:30:00.123456+00:00", want: want},
+		{name: "rfc3339", src: "2024-01-15T10:30:00.123456Z", want: want},
+		{name: "null", src: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			got := time.Now()
+
+			// When
+			err := scanTime{&got}.Scan(tt.src)
+
+			// Then
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "got %s", got)
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		var got time.Time
+		assert.Error(t, scanTime{&got}.Scan("yesterday"))
+		assert.Error(t, scanTime{&got}.Scan(42))
+	})
+}
@@ -0,0 +1,233 @@
+package outbox
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Metadata fields recorded on encrypted messages
+const (
+	MetadataEncryptionKeyID = "encryption_key_id"
+	MetadataEncryption      = "encryption"
+)
+
+// encryptionAlgorithm is recorded in the metadata of encrypted messages
+const encryptionAlgorithm = "AES-256-GCM"
+
+// dataKeySize is the size of the key generated for each message
+const dataKeySize = 32
+
+var (
+	// ErrKeyNotFound is returned by a KeyProvider without the requested key
+	ErrKeyNotFound = errors.New("encryption key not found")
+
+	// ErrDecryptionFailed is returned when an encrypted payload cannot be decrypted
+	ErrDecryptionFailed = errors.New("failed to decrypt payload")
+)
+
+// KeyProvider supplies the AES keys wrapping the per-message data keys of envelope encryption,
+// such as keys held by a key management service
+type KeyProvider interface {
+	// CurrentKey returns the key encrypting new messages and its id
+	CurrentKey(ctx context.Context) (id string, key []byte, err error)
+	// Key returns the key with the id, so messages encrypted with a rotated key can be decrypted
+	Key(ctx context.Context, id string) ([]byte, error)
+}
+
+// StaticKeyProvider serves keys held in memory, such as keys read with ReadKeyFile
+type StaticKeyProvider struct {
+	currentID string
+	keys      map[string][]byte
+}
+
+var _ KeyProvider = (*StaticKeyProvider)(nil)
+
+// NewStaticKeyProvider creates a new StaticKeyProvider encrypting with the key of currentID.
+// Keys must be 16, 24 or 32 bytes long.
+func NewStaticKeyProvider(currentID string, keys map[string][]byte) (*StaticKeyProvider, error) {
+	if _, ok := keys[currentID]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, currentID)
+	}
+
+	copied := make(map[string][]byte, len(keys))
+	for id, key := range keys {
+		if _, err := aes.NewCipher(key); err != nil {
+			return nil, fmt.Errorf("invalid key %s: %w", id, err)
+		}
+		copied[id] = append([]byte(nil), key...)
+	}
+	return &StaticKeyProvider{currentID: currentID, keys: copied}, nil
+}
+
+func (p *StaticKeyProvider) CurrentKey(context.Context) (string, []byte, error) {
+	return p.currentID, p.keys[p.currentID], nil
+}
+
+func (p *StaticKeyProvider) Key(_ context.Context, id string) ([]byte, error) {
+	key, ok := p.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, id)
+	}
+	return key, nil
+}
+
+// ReadKeyFile reads a base64 encoded AES key from a file, such as one written by
+// `openssl rand -base64 32`
+func ReadKeyFile(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key file %s: %w", path, err)
+	}
+	if _, err := aes.NewCipher(key); err != nil {
+		return nil, fmt.Errorf("invalid key file %s: %w", path, err)
+	}
+	return key, nil
+}
+
+// encryptedPayload is the payload of an encrypted message
+type encryptedPayload struct {
+	DataKey    []byte `json:"data_key"`   // Data key encrypted with the key of the provider
+	Ciphertext []byte `json:"ciphertext"` // Payload encrypted with the data key
+}
+
+// PayloadEncryptor encrypts payloads with envelope encryption: each payload is encrypted
+// with a new AES-256-GCM data key, which is encrypted with the current key of the provider.
+// The key id is recorded in the metadata, so keys can be rotated.
+//
+// The encrypted payload is bound to the EventID of the message, so it cannot be
+// moved to another message.
+type PayloadEncryptor struct {
+	keys KeyProvider
+	rand io.Reader
+}
+
+var (
+	_ PayloadTransformer = (*PayloadEncryptor)(nil)
+	_ PayloadRestorer    = (*PayloadEncryptor)(nil)
+)
+
+// NewPayloadEncryptor creates a new PayloadEncryptor with the keys of provider
+func NewPayloadEncryptor(provider KeyProvider) (*PayloadEncryptor, error) {
+	if provider == nil {
+		return nil, fmt.Errorf("key provider cannot be nil")
+	}
+	return &PayloadEncryptor{keys: provider, rand: rand.Reader}, nil
+}
+
+// Transform encrypts the payload of the message; messages without payload are left as is
+func (e *PayloadEncryptor) Transform(ctx context.Context, msg *Message) error {
+	if len(msg.Payload) == 0 {
+		return nil
+	}
+	if _, ok := MetadataString(msg, MetadataEncryptionKeyID); ok {
+		return fmt.Errorf("payload of message %s is already encrypted", msg.EventID)
+	}
+
+	keyID, key, err := e.keys.CurrentKey(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get encryption key: %w", err)
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(e.rand, dataKey); err != nil {
+		return fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	var envelope encryptedPayload
+	if envelope.Ciphertext, err = e.seal(dataKey, msg.Payload, []byte(msg.EventID)); err != nil {
+		return err
+	}
+	if envelope.DataKey, err = e.seal(key, dataKey, []byte(keyID)); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return &MarshalError{Field: "payload", Err: err}
+	}
+	if err := setMetadata(msg, map[string]any{MetadataEncryptionKeyID: keyID, MetadataEncryption: encryptionAlgorithm}); err != nil {
+		return err
+	}
+	msg.Payload = payload
+	return nil
+}
+
+// Restore decrypts the payload of a message encrypted by Transform and removes the
+// encryption fields from its metadata; other messages are left as is
+func (e *PayloadEncryptor) Restore(ctx context.Context, msg *Message) error {
+	keyID, ok := MetadataString(msg, MetadataEncryptionKeyID)
+	if !ok {
+		return nil
+	}
+
+	var envelope encryptedPayload
+	if err := json.Unmarshal(msg.Payload, &envelope); err != nil {
+		return fmt.Errorf("%w: message %s: %v", ErrDecryptionFailed, msg.EventID, err)
+	}
+
+	key, err := e.keys.Key(ctx, keyID)
+	if err != nil {
+		return fmt.Errorf("failed to get encryption key: %w", err)
+	}
+	dataKey, err := open(key, envelope.DataKey, []byte(keyID))
+	if err != nil {
+		return fmt.Errorf("%w: message %s: %v", ErrDecryptionFailed, msg.EventID, err)
+	}
+	payload, err := open(dataKey, envelope.Ciphertext, []byte(msg.EventID))
+	if err != nil {
+		return fmt.Errorf("%w: message %s: %v", ErrDecryptionFailed, msg.EventID, err)
+	}
+
+	if err := setMetadata(msg, map[string]any{MetadataEncryptionKeyID: nil, MetadataEncryption: nil}); err != nil {
+		return err
+	}
+	msg.Payload = payload
+	return nil
+}
+
+// seal encrypts plaintext with AES-GCM, prefixing the result with the nonce
+func (e *PayloadEncryptor) seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := io.ReadFull(e.rand, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts a ciphertext created by seal
+func open(key, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
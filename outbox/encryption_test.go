@@ -0,0 +1,135 @@
+package outbox
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyProvider(t *testing.T, currentID string) *StaticKeyProvider {
+	provider, err := NewStaticKeyProvider(currentID, map[string][]byte{
+		"key-1": []byte(strings.Repeat("1", 32)),
+		"key-2": []byte(strings.Repeat("2", 32)),
+	})
+	require.NoError(t, err)
+	return provider
+}
+
+func TestPayloadEncryptor(t *testing.T) {
+	t.Run("encrypts and restores the payload", func(t *testing.T) {
+		// Given
+		encryptor, err := NewPayloadEncryptor(newTestKeyProvider(t, "key-1"))
+		require.NoError(t, err)
+		msg := newTransformTestMessage(t, `{"email":"jane@example.com"}`)
+
+		// When
+		err = encryptor.Transform(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		assert.NotContains(t, string(msg.Payload), "jane@example.com")
+		assert.True(t, json.Valid(msg.Payload))
+		keyID, ok := MetadataString(msg, MetadataEncryptionKeyID)
+		assert.True(t, ok)
+		assert.Equal(t, "key-1", keyID)
+
+		require.NoError(t, encryptor.Restore(context.Background(), msg))
+		assert.JSONEq(t, `{"email":"jane@example.com"}`, string(msg.Payload))
+		assert.JSONEq(t, `{"tenant":"acme"}`, string(msg.Metadata))
+	})
+
+	t.Run("decrypts messages of rotated keys", func(t *testing.T) {
+		// Given
+		encryptor, err := NewPayloadEncryptor(newTestKeyProvider(t, "key-1"))
+		require.NoError(t, err)
+		msg := newTransformTestMessage(t, `{"n":1}`)
+		require.NoError(t, encryptor.Transform(context.Background(), msg))
+
+		rotated, err := NewPayloadEncryptor(newTestKeyProvider(t, "key-2"))
+		require.NoError(t, err)
+
+		// When
+		err = rotated.Restore(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"n":1}`, string(msg.Payload))
+	})
+
+	t.Run("payload is bound to the event id", func(t *testing.T) {
+		// Given
+		encryptor, err := NewPayloadEncryptor(newTestKeyProvider(t, "key-1"))
+		require.NoError(t, err)
+		msg := newTransformTestMessage(t, `{"n":1}`)
+		require.NoError(t, encryptor.Transform(context.Background(), msg))
+		msg.EventID = "another-event"
+
+		// When
+		err = encryptor.Restore(context.Background(), msg)
+
+		// Then
+		assert.ErrorIs(t, err, ErrDecryptionFailed)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		// Given
+		encryptor, err := NewPayloadEncryptor(newTestKeyProvider(t, "key-1"))
+		require.NoError(t, err)
+		msg := newTransformTestMessage(t, `{"n":1}`)
+		require.NoError(t, setMetadata(msg, map[string]any{MetadataEncryptionKeyID: "key-3"}))
+
+		// When
+		err = encryptor.Restore(context.Background(), msg)
+
+		// Then
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+	})
+
+	t.Run("plain messages are left as is", func(t *testing.T) {
+		// Given
+		encryptor, err := NewPayloadEncryptor(newTestKeyProvider(t, "key-1"))
+		require.NoError(t, err)
+		msg := newTransformTestMessage(t, `{"n":1}`)
+
+		// When
+		err = encryptor.Restore(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"n":1}`, string(msg.Payload))
+	})
+}
+
+func TestNewStaticKeyProvider(t *testing.T) {
+	_, err := NewStaticKeyProvider("missing", map[string][]byte{"key-1": make([]byte, 32)})
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	_, err = NewStaticKeyProvider("key-1", map[string][]byte{"key-1": make([]byte, 7)})
+	assert.Error(t, err)
+}
+
+func TestReadKeyFile(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	key := []byte(strings.Repeat("k", 32))
+	valid := filepath.Join(dir, "valid.key")
+	require.NoError(t, os.WriteFile(valid, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0o600))
+	invalid := filepath.Join(dir, "invalid.key")
+	require.NoError(t, os.WriteFile(invalid, []byte(base64.StdEncoding.EncodeToString([]byte("short"))), 0o600))
+
+	// When
+	read, err := ReadKeyFile(valid)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, key, read)
+	_, err = ReadKeyFile(invalid)
+	assert.Error(t, err)
+}
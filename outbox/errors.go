@@ -3,6 +3,7 @@ package outbox
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 var (
@@ -28,26 +29,55 @@ var (
 	// The timestamp is required to track when the event occurred.
 	ErrInvalidTimestamp = errors.New("timestamp cannot be empty")
 
+	// ErrInvalidAggregateKey is returned when the aggregate key exceeds the column size.
+	ErrInvalidAggregateKey = errors.New("aggregate key is too long")
+
 	ErrInvalidConfig    = errors.New("invalid publisher configuration")
 	ErrPublishFailed    = errors.New("failed to publish message")
 	ErrMarshalFailed    = errors.New("failed to marshal message")
 	ErrExecutorNotFound = errors.New("executor not found")
+
+	// ErrInvalidCloudEvent is returned when a CloudEvent cannot be converted to a Message.
+	ErrInvalidCloudEvent = errors.New("invalid cloud event")
+
+	// ErrDeadLettered is wrapped by the errors reported for messages moved to the dead-letter table.
+	ErrDeadLettered = errors.New("message moved to dead-letter table")
 )
 
 type PublishError struct {
 	MessageID string
 	Err       error
 	Retries   int
+	Attempts  int
 }
 
 func (e *PublishError) Error() string {
-	return fmt.Sprintf("failed to publish message %s after %d retries: %v", e.MessageID, e.Retries, e.Err)
+	if e.Attempts == 0 {
+		return fmt.Sprintf("failed to publish message %s after %d retries: %v", e.MessageID, e.Retries, e.Err)
+	}
+	return fmt.Sprintf("failed to publish message %s after %d attempts: %v", e.MessageID, e.Attempts, e.Err)
 }
 
 func (e *PublishError) Unwrap() error {
 	return e.Err
 }
 
+// BatchPublishError reports a batch of messages that could not be written.
+// A batch is written with a single statement, so none of its messages were stored.
+type BatchPublishError struct {
+	MessageIDs []string // EventIDs of the messages in the failed batch
+	Published  int      // Number of messages written by earlier batches
+	Err        error
+}
+
+func (e *BatchPublishError) Error() string {
+	return fmt.Sprintf("failed to publish messages %s: %v", strings.Join(e.MessageIDs, ", "), e.Err)
+}
+
+func (e *BatchPublishError) Unwrap() error {
+	return e.Err
+}
+
 type MarshalError struct {
 	Field string
 	Err   error
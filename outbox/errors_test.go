@@ -105,3 +105,19 @@ func TestErrorConstants(t *testing.T) {
 	assert.Equal(t, "failed to marshal message", ErrMarshalFailed.Error())
 	assert.Equal(t, "executor not found", ErrExecutorNotFound.Error())
 }
+
+func TestPublishError_Attempts(t *testing.T) {
+	// Given
+	publishErr := &PublishError{
+		MessageID: "test-message",
+		Err:       errors.New("original error"),
+		Retries:   2,
+		Attempts:  3,
+	}
+
+	// When
+	errMsg := publishErr.Error()
+
+	// Then
+	assert.Equal(t, "failed to publish message test-message after 3 attempts: original error", errMsg)
+}
@@ -0,0 +1,256 @@
+// Package kafka dispatches outbox messages to Kafka topics.
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/carped99/gosdk/outbox"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Record headers set on every dispatched message
+const (
+	HeaderEventID       = outbox.AttributeEventID
+	HeaderEventDomain   = outbox.AttributeEventDomain
+	HeaderEventType     = outbox.AttributeEventType
+	HeaderObjectType    = outbox.AttributeObjectType
+	HeaderProducer      = outbox.AttributeProducer
+	HeaderCorrelationID = outbox.AttributeCorrelationID
+	HeaderCreatedAt     = outbox.AttributeCreatedAt
+	HeaderAggregateKey  = outbox.AttributeAggregateKey
+	HeaderSequence      = outbox.AttributeSequence
+	HeaderMetadata      = outbox.AttributeMetadata
+)
+
+// Producer is the part of *kgo.Client used by the Dispatcher.
+// kafkatest.Broker implements it for tests.
+type Producer interface {
+	ProduceSync(ctx context.Context, rs ...*kgo.Record) kgo.ProduceResults
+}
+
+// KeyFunc returns the partition key of a message; a nil key spreads messages over partitions
+type KeyFunc func(msg *outbox.Message) []byte
+
+// KeyByCorrelationID partitions messages by CorrelationID
+func KeyByCorrelationID(msg *outbox.Message) []byte {
+	if msg.CorrelationID == "" {
+		return nil
+	}
+	return []byte(msg.CorrelationID)
+}
+
+// KeyByAggregateKey partitions messages by AggregateKey, so consumers receive the
+// messages of a key in order, and messages without one by CorrelationID
+func KeyByAggregateKey(msg *outbox.Message) []byte {
+	if msg.AggregateKey == "" {
+		return KeyByCorrelationID(msg)
+	}
+	return []byte(msg.AggregateKey)
+}
+
+// KeyByEventID partitions messages by EventID
+func KeyByEventID(msg *outbox.Message) []byte {
+	return []byte(msg.EventID)
+}
+
+// KeyByPayloadField partitions messages by a top-level string or number field of the payload,
+// such as the id of the object the event is about
+func KeyByPayloadField(field string) KeyFunc {
+	return func(msg *outbox.Message) []byte {
+		var payload map[string]json.RawMessage
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return nil
+		}
+
+		raw, ok := payload[field]
+		if !ok {
+			return nil
+		}
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			return []byte(s)
+		}
+		return raw
+	}
+}
+
+type config struct {
+	key         KeyFunc
+	topic       func(msg *outbox.Message) string
+	cloudEvents bool
+}
+
+// Option configures a Dispatcher
+type Option func(*config) error
+
+// WithKeyFunc sets the partition key of dispatched messages, KeyByAggregateKey by default
+func WithKeyFunc(fn KeyFunc) Option {
+	return func(c *config) error {
+		if fn == nil {
+			return fmt.Errorf("key func cannot be nil")
+		}
+		c.key = fn
+		return nil
+	}
+}
+
+// WithTopicFunc sets the topic of dispatched messages, EventTopic by default
+func WithTopicFunc(fn func(msg *outbox.Message) string) Option {
+	return func(c *config) error {
+		if fn == nil {
+			return fmt.Errorf("topic func cannot be nil")
+		}
+		c.topic = fn
+		return nil
+	}
+}
+
+// WithCloudEvents dispatches messages as binary mode CloudEvents with ce_ headers
+func WithCloudEvents() Option {
+	return func(c *config) error {
+		c.cloudEvents = true
+		return nil
+	}
+}
+
+// IdempotentProducerOptions returns client options for an idempotent producer:
+// all in-sync replicas acknowledge every write and retries never duplicate or reorder records.
+// Idempotency is enabled by default in franz-go and must not be disabled with these options.
+func IdempotentProducerOptions() []kgo.Opt {
+	return []kgo.Opt{
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+		kgo.RecordDeliveryTimeout(2 * time.Minute),
+	}
+}
+
+// Dispatcher publishes outbox messages to Kafka.
+// It implements outbox.Dispatcher.
+type Dispatcher struct {
+	producer Producer
+	config   config
+}
+
+var _ outbox.Dispatcher = (*Dispatcher)(nil)
+
+// NewDispatcher creates a new Dispatcher producing with producer, usually a *kgo.Client
+func NewDispatcher(producer Producer, opts ...Option) (*Dispatcher, error) {
+	if producer == nil {
+		return nil, fmt.Errorf("producer cannot be nil")
+	}
+
+	c := config{
+		key: KeyByAggregateKey,
+		topic: func(msg *outbox.Message) string {
+			return msg.EventTopic
+		},
+	}
+	for _, opt := range opts {
+		if err := opt(&c); err != nil {
+			return nil, fmt.Errorf("failed to apply kafka option: %w", err)
+		}
+	}
+
+	return &Dispatcher{producer: producer, config: c}, nil
+}
+
+// Dispatch produces the message and waits for the broker to acknowledge it
+func (d *Dispatcher) Dispatch(ctx context.Context, msg *outbox.Message) error {
+	record, err := d.Record(msg)
+	if err != nil {
+		return err
+	}
+
+	if err := d.producer.ProduceSync(ctx, record).FirstErr(); err != nil {
+		return fmt.Errorf("failed to produce message %s to %s: %w", msg.EventID, record.Topic, err)
+	}
+	return nil
+}
+
+// Record converts the message to a Kafka record
+func (d *Dispatcher) Record(msg *outbox.Message) (*kgo.Record, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("message cannot be nil")
+	}
+
+	topic := d.config.topic(msg)
+	if topic == "" {
+		return nil, outbox.ErrInvalidEventTopic
+	}
+
+	record := &kgo.Record{
+		Topic:     topic,
+		Key:       d.config.key(msg),
+		Timestamp: msg.CreatedAt,
+	}
+
+	if d.config.cloudEvents {
+		headers, value, err := outbox.ToCloudEventKafka(msg)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range headers {
+			record.Headers = append(record.Headers, kgo.RecordHeader{Key: h.Key, Value: h.Value})
+		}
+		record.Value = value
+		return record, nil
+	}
+
+	recordHeaders, err := headers(msg)
+	if err != nil {
+		return nil, err
+	}
+	record.Value = msg.Payload
+	record.Headers = recordHeaders
+	return record, nil
+}
+
+// headers returns the record headers describing the message
+func headers(msg *outbox.Message) ([]kgo.RecordHeader, error) {
+	attributes, err := outbox.MessageAttributes(msg)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]kgo.RecordHeader, 0, len(attributes))
+	for _, a := range attributes {
+		result = append(result, kgo.RecordHeader{Key: a.Key, Value: []byte(a.Value)})
+	}
+	return result, nil
+}
+
+// MessageFromRecord converts a record produced by a Dispatcher back to an outbox message.
+// Records carrying CloudEvents headers are decoded with outbox.FromCloudEventKafka.
+func MessageFromRecord(record *kgo.Record) (*outbox.Message, error) {
+	if record == nil {
+		return nil, fmt.Errorf("record cannot be nil")
+	}
+
+	values := make(map[string]string, len(record.Headers))
+	cloudEvent := make([]outbox.Header, 0, len(record.Headers))
+	for _, h := range record.Headers {
+		values[h.Key] = string(h.Value)
+		cloudEvent = append(cloudEvent, outbox.Header{Key: h.Key, Value: h.Value})
+	}
+	if _, ok := values["ce_specversion"]; ok {
+		return outbox.FromCloudEventKafka(cloudEvent, record.Value)
+	}
+
+	msg, err := outbox.MessageFromAttributes(func(key string) string { return values[key] })
+	if err != nil {
+		return nil, err
+	}
+	msg.EventTopic = record.Topic
+	if msg.CreatedAt.IsZero() {
+		msg.CreatedAt = record.Timestamp
+	}
+	if len(record.Value) > 0 {
+		msg.Payload = append([]byte(nil), record.Value...)
+	}
+
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
@@ -0,0 +1,207 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/carped99/gosdk/outbox"
+	"github.com/carped99/gosdk/outbox/kafka/kafkatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func newTestMessage(t *testing.T, correlationID string) *outbox.Message {
+	msg, err := outbox.NewMessageBuilder().
+		SetEventTopic("orders").
+		SetEventDomain("shop").
+		SetEventType("order.created").
+		SetObjectType("order").
+		SetProducer("order-service").
+		SetCorrelationID(correlationID).
+		SetPayload(json.RawMessage(`{"order_id":"o-1","amount":3}`)).
+		Build()
+	require.NoError(t, err)
+	return msg
+}
+
+func header(record *kgo.Record, key string) string {
+	for _, h := range record.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	t.Run("produces to the event topic with headers", func(t *testing.T) {
+		// Given
+		broker := kafkatest.NewBroker(4)
+		dispatcher, err := NewDispatcher(broker)
+		require.NoError(t, err)
+		msg := newTestMessage(t, "corr-1")
+
+		// When
+		err = dispatcher.Dispatch(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		records := broker.Records("orders")
+		require.Len(t, records, 1)
+		assert.Equal(t, []byte("corr-1"), records[0].Key)
+		assert.JSONEq(t, `{"order_id":"o-1","amount":3}`, string(records[0].Value))
+		assert.Equal(t, msg.EventID, header(records[0], HeaderEventID))
+		assert.Equal(t, "order.created", header(records[0], HeaderEventType))
+		assert.Equal(t, "order-service", header(records[0], HeaderProducer))
+		assert.Equal(t, "corr-1", header(records[0], HeaderCorrelationID))
+	})
+
+	t.Run("keys by aggregate key", func(t *testing.T) {
+		// Given
+		broker := kafkatest.NewBroker(4)
+		dispatcher, err := NewDispatcher(broker)
+		require.NoError(t, err)
+		msg := newTestMessage(t, "corr-1")
+		msg.AggregateKey = "order-1"
+		msg.Sequence = 3
+
+		// When
+		err = dispatcher.Dispatch(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		records := broker.Records("orders")
+		require.Len(t, records, 1)
+		assert.Equal(t, []byte("order-1"), records[0].Key)
+		assert.Equal(t, "order-1", header(records[0], HeaderAggregateKey))
+		assert.Equal(t, "3", header(records[0], HeaderSequence))
+	})
+
+	t.Run("same key goes to the same partition", func(t *testing.T) {
+		// Given
+		broker := kafkatest.NewBroker(8)
+		dispatcher, err := NewDispatcher(broker, WithKeyFunc(KeyByPayloadField("order_id")))
+		require.NoError(t, err)
+
+		// When
+		for i := 0; i < 3; i++ {
+			require.NoError(t, dispatcher.Dispatch(context.Background(), newTestMessage(t, "")))
+		}
+
+		// Then
+		records := broker.Records("orders")
+		require.Len(t, records, 3)
+		for i, r := range records {
+			assert.Equal(t, []byte("o-1"), r.Key)
+			assert.Equal(t, records[0].Partition, r.Partition)
+			assert.Equal(t, int64(i), r.Offset)
+		}
+	})
+
+	t.Run("broker error", func(t *testing.T) {
+		// Given
+		broker := kafkatest.NewBroker(1)
+		broker.Fail(errors.New("not leader for partition"))
+		dispatcher, err := NewDispatcher(broker)
+		require.NoError(t, err)
+
+		// When
+		err = dispatcher.Dispatch(context.Background(), newTestMessage(t, "corr-1"))
+
+		// Then
+		assert.ErrorContains(t, err, "not leader for partition")
+		assert.Empty(t, broker.Records("orders"))
+	})
+
+	t.Run("relayed from the outbox", func(t *testing.T) {
+		// Given
+		broker := kafkatest.NewBroker(1)
+		dispatcher, err := NewDispatcher(broker, WithTopicFunc(func(msg *outbox.Message) string {
+			return msg.EventDomain + "." + msg.EventTopic
+		}))
+		require.NoError(t, err)
+
+		// When
+		var d outbox.Dispatcher = dispatcher
+		err = d.Dispatch(context.Background(), newTestMessage(t, "corr-1"))
+
+		// Then
+		require.NoError(t, err)
+		assert.Len(t, broker.Records("shop.orders"), 1)
+	})
+}
+
+func TestMessageFromRecord(t *testing.T) {
+	for name, opts := range map[string][]Option{
+		"headers":      nil,
+		"cloud events": {WithCloudEvents()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Given
+			broker := kafkatest.NewBroker(1)
+			dispatcher, err := NewDispatcher(broker, opts...)
+			require.NoError(t, err)
+			msg := newTestMessage(t, "corr-1")
+			msg.AggregateKey = "order-1"
+			msg.Sequence = 3
+			require.NoError(t, dispatcher.Dispatch(context.Background(), msg))
+
+			// When
+			decoded, err := MessageFromRecord(broker.Records("orders")[0])
+
+			// Then
+			require.NoError(t, err)
+			assert.Equal(t, msg.EventID, decoded.EventID)
+			assert.Equal(t, msg.EventTopic, decoded.EventTopic)
+			assert.Equal(t, msg.EventDomain, decoded.EventDomain)
+			assert.Equal(t, msg.EventType, decoded.EventType)
+			assert.Equal(t, msg.ObjectType, decoded.ObjectType)
+			assert.Equal(t, msg.Producer, decoded.Producer)
+			assert.Equal(t, msg.CorrelationID, decoded.CorrelationID)
+			assert.Equal(t, "order-1", decoded.AggregateKey)
+			assert.Equal(t, int64(3), decoded.Sequence)
+			assert.JSONEq(t, string(msg.Payload), string(decoded.Payload))
+			assert.WithinDuration(t, msg.CreatedAt, decoded.CreatedAt, time.Microsecond)
+		})
+	}
+}
+
+func TestMessageFromRecord_RestoresPayload(t *testing.T) {
+	// Given
+	compressor, err := outbox.NewPayloadCompressor(outbox.CompressionGzip, outbox.WithCompressionThreshold(64))
+	require.NoError(t, err)
+	payload := `{"items":"` + strings.Repeat("abc", 100) + `"}`
+	msg := newTestMessage(t, "corr-1")
+	msg.Payload = json.RawMessage(payload)
+	msg.Metadata = json.RawMessage(`{"tenant": "acme"}`)
+	require.NoError(t, compressor.Transform(context.Background(), msg))
+
+	broker := kafkatest.NewBroker(1)
+	dispatcher, err := NewDispatcher(broker)
+	require.NoError(t, err)
+	require.NoError(t, dispatcher.Dispatch(context.Background(), msg))
+
+	// When
+	decoded, err := MessageFromRecord(broker.Records("orders")[0])
+	require.NoError(t, err)
+	err = outbox.RestorePayload(context.Background(), decoded, compressor)
+
+	// Then
+	require.NoError(t, err)
+	assert.JSONEq(t, payload, string(decoded.Payload))
+	assert.JSONEq(t, `{"tenant":"acme"}`, string(decoded.Metadata))
+}
+
+func TestIdempotentProducerOptions(t *testing.T) {
+	client, err := kgo.NewClient(append(IdempotentProducerOptions(), kgo.SeedBrokers("127.0.0.1:0"))...)
+	require.NoError(t, err)
+	defer client.Close()
+
+	assert.Equal(t, kgo.AllISRAcks(), client.OptValue(kgo.RequiredAcks))
+	assert.Equal(t, false, client.OptValue(kgo.DisableIdempotentWrite))
+}
@@ -0,0 +1,118 @@
+// Package kafkatest provides an in-process stand-in for a Kafka broker.
+package kafkatest
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Broker is an in-memory broker implementing kafka.Producer.
+// Records are assigned partitions by key hash, or round-robin without a key,
+// and offsets per partition like a real broker.
+type Broker struct {
+	mu         sync.Mutex
+	partitions int32
+	next       int32
+	topics     map[string][][]*kgo.Record
+	records    map[string][]*kgo.Record
+	failures   []error
+}
+
+// NewBroker creates a Broker whose topics have the given number of partitions
+func NewBroker(partitions int32) *Broker {
+	if partitions <= 0 {
+		partitions = 1
+	}
+	return &Broker{
+		partitions: partitions,
+		topics:     make(map[string][][]*kgo.Record),
+		records:    make(map[string][]*kgo.Record),
+	}
+}
+
+// Fail makes the next produce calls fail with the given errors, one error per call
+func (b *Broker) Fail(errs ...error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = append(b.failures, errs...)
+}
+
+// ProduceSync stores the records and returns their results
+func (b *Broker) ProduceSync(ctx context.Context, rs ...*kgo.Record) kgo.ProduceResults {
+	results := make(kgo.ProduceResults, 0, len(rs))
+	if err := ctx.Err(); err != nil {
+		for _, r := range rs {
+			results = append(results, kgo.ProduceResult{Record: r, Err: err})
+		}
+		return results
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.failures) > 0 {
+		err := b.failures[0]
+		b.failures = b.failures[1:]
+		for _, r := range rs {
+			results = append(results, kgo.ProduceResult{Record: r, Err: err})
+		}
+		return results
+	}
+
+	for _, r := range rs {
+		partitions, ok := b.topics[r.Topic]
+		if !ok {
+			partitions = make([][]*kgo.Record, b.partitions)
+			b.topics[r.Topic] = partitions
+		}
+
+		r.Partition = b.partition(r.Key)
+		r.Offset = int64(len(partitions[r.Partition]))
+		if r.Timestamp.IsZero() {
+			r.Timestamp = time.Now()
+		}
+
+		partitions[r.Partition] = append(partitions[r.Partition], r)
+		b.records[r.Topic] = append(b.records[r.Topic], r)
+		results = append(results, kgo.ProduceResult{Record: r})
+	}
+	return results
+}
+
+// Records returns the records of the topic in produce order
+func (b *Broker) Records(topic string) []*kgo.Record {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]*kgo.Record(nil), b.records[topic]...)
+}
+
+// Partition returns the records of a topic partition in offset order
+func (b *Broker) Partition(topic string, partition int32) []*kgo.Record {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	partitions := b.topics[topic]
+	if partition < 0 || int(partition) >= len(partitions) {
+		return nil
+	}
+	return append([]*kgo.Record(nil), partitions[partition]...)
+}
+
+// partition returns the partition of a record key
+func (b *Broker) partition(key []byte) int32 {
+	if key == nil {
+		p := b.next
+		b.next = (b.next + 1) % b.partitions
+		return p
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write(key)
+	return int32(h.Sum32() % uint32(b.partitions))
+}
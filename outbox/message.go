@@ -7,6 +7,15 @@ import (
 	"go.uber.org/multierr"
 )
 
+// Message status values stored in the status column of the outbox table
+const (
+	MessageStatusPending   = "pending"
+	MessageStatusPublished = "published"
+)
+
+// maxAggregateKeyLength is the size of the aggregate_key column
+const maxAggregateKeyLength = 255
+
 // Message represents an event message in the system.
 // It contains all necessary information about an event including its metadata and payload.
 type Message struct {
@@ -20,6 +29,9 @@ type Message struct {
 	Payload       json.RawMessage `json:"payload,omitempty"`        // Event data in JSON format
 	Metadata      json.RawMessage `json:"metadata,omitempty"`       // Additional event information
 	CreatedAt     time.Time       `json:"created_at"`               // When the event was created
+	AggregateKey  string          `json:"aggregate_key,omitempty"`  // Messages sharing the key are delivered one at a time, in order
+	Sequence      int64           `json:"sequence,omitempty"`       // Position within the aggregate key, assigned when published
+	DeliverAt     time.Time       `json:"-"`                        // When the relay may dispatch the message, at once when zero
 }
 
 // validate checks if all required fields of the Message are properly set.
@@ -46,6 +58,10 @@ func (m *Message) validate() error {
 		err = multierr.Append(err, ErrInvalidObjectType)
 	}
 
+	if len(m.AggregateKey) > maxAggregateKeyLength {
+		err = multierr.Append(err, ErrInvalidAggregateKey)
+	}
+
 	if m.CreatedAt.IsZero() {
 		err = multierr.Append(err, ErrInvalidTimestamp)
 	}
@@ -53,6 +69,12 @@ func (m *Message) validate() error {
 	return err
 }
 
+// Validate checks if all required fields of the Message are properly set,
+// for messages built without MessageBuilder such as those read from a broker.
+func (m *Message) Validate() error {
+	return m.validate()
+}
+
 // UnmarshalMessage unmarshal a JSON byte slice into a Message struct.
 func UnmarshalMessage(data []byte) (*Message, error) {
 	var msg Message
@@ -11,7 +11,9 @@ import (
 // It provides a fluent interface for setting message properties and ensures
 // all required fields are properly set before creating the message.
 type MessageBuilder struct {
-	message *Message
+	message       *Message
+	schemas       *SchemaRegistry
+	schemaVersion int
 }
 
 // NewMessageBuilder creates a new messageBuilder instance.
@@ -66,6 +68,24 @@ func (b *MessageBuilder) SetCorrelationID(correlationID string) *MessageBuilder
 	return b
 }
 
+// SetAggregateKey sets the key, such as an order ID, whose messages are delivered in publish order.
+// The publisher writes them in a transaction: the caller's, or one it begins on a *sql.DB.
+func (b *MessageBuilder) SetAggregateKey(key string) *MessageBuilder {
+	b.message.AggregateKey = key
+	return b
+}
+
+// SetDeliverAt schedules the message: the relay dispatches it once the time has come.
+func (b *MessageBuilder) SetDeliverAt(deliverAt time.Time) *MessageBuilder {
+	b.message.DeliverAt = deliverAt.UTC()
+	return b
+}
+
+// SetDelay schedules the message to be dispatched after delay from now.
+func (b *MessageBuilder) SetDelay(delay time.Duration) *MessageBuilder {
+	return b.SetDeliverAt(time.Now().Add(delay))
+}
+
 // SetPayload sets the event data in JSON format.
 func (b *MessageBuilder) SetPayload(payload json.RawMessage) *MessageBuilder {
 	b.message.Payload = payload
@@ -84,6 +104,19 @@ func (b *MessageBuilder) SetCreatedAt(value time.Time) *MessageBuilder {
 	return b
 }
 
+// SetSchemaRegistry enables payload validation against the registry in Build.
+func (b *MessageBuilder) SetSchemaRegistry(registry *SchemaRegistry) *MessageBuilder {
+	b.schemas = registry
+	return b
+}
+
+// SetSchemaVersion records the schema version of the payload in the metadata, so the
+// schema registry validates against it instead of the latest version.
+func (b *MessageBuilder) SetSchemaVersion(version int) *MessageBuilder {
+	b.schemaVersion = version
+	return b
+}
+
 // Build validates all required fields and creates a new Message.
 // Returns an error if any required field is missing or invalid.
 func (b *MessageBuilder) Build() (*Message, error) {
@@ -99,6 +132,27 @@ func (b *MessageBuilder) Build() (*Message, error) {
 		return nil, err
 	}
 
+	if b.schemaVersion != 0 {
+		if b.schemaVersion < 0 {
+			return nil, fmt.Errorf("schema version must be positive, got %d", b.schemaVersion)
+		}
+		if err := setMetadata(b.message, map[string]any{MetadataSchemaVersion: b.schemaVersion}); err != nil {
+			return nil, err
+		}
+	}
+
+	if b.schemas != nil {
+		version, err := b.schemas.resolve(b.message)
+		if err != nil {
+			return nil, err
+		}
+		if version > 0 {
+			if err := setMetadata(b.message, map[string]any{MetadataSchemaVersion: version}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	// 메시지 복사본 생성
 	message := *b.message
 	return &message, nil
@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMessageBuilder(t *testing.T) {
@@ -100,3 +101,21 @@ func TestMessageBuilder_Chaining(t *testing.T) {
 	assert.Equal(t, "test.type", builder.message.EventType)
 	assert.Equal(t, "test.object", builder.message.ObjectType)
 }
+
+func TestMessageBuilder_Schedule(t *testing.T) {
+	builder := NewMessageBuilder().
+		SetEventTopic("invitations").
+		SetEventDomain("iam").
+		SetEventType("invitation.expired").
+		SetObjectType("invitation")
+
+	msg, err := builder.SetDelay(time.Hour).Build()
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), msg.DeliverAt, time.Second)
+
+	deliverAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.FixedZone("KST", 9*60*60))
+	msg, err = builder.SetDeliverAt(deliverAt).Build()
+	require.NoError(t, err)
+	assert.True(t, deliverAt.Equal(msg.DeliverAt))
+	assert.Equal(t, time.UTC, msg.DeliverAt.Location())
+}
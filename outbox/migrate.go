@@ -0,0 +1,390 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"testing/fstest"
+	"text/template"
+	"time"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// MigrationDB executes migrations and reads the applied versions, such as *sql.DB
+type MigrationDB interface {
+	Executor
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+type migrateConfig struct {
+	dialect         Dialect
+	tableName       string
+	migrationsTable string
+}
+
+// MigrateOption defines a function that configures Migrate
+type MigrateOption func(*migrateConfig) error
+
+// WithMigrationDialect sets the dialect of the migrations.
+// Without it the dialect is detected from a db implementing DriverNamer, or Postgres.
+func WithMigrationDialect(dialect Dialect) MigrateOption {
+	return func(c *migrateConfig) error {
+		if err := dialect.validate(); err != nil {
+			return err
+		}
+		c.dialect = dialect
+		return nil
+	}
+}
+
+// WithMigrationTableName sets the outbox table name.
+// It must match the name given to WithTableName.
+func WithMigrationTableName(tableName string) MigrateOption {
+	return func(c *migrateConfig) error {
+		sanitizedTableName, err := sanitizeTableName(tableName)
+		if err != nil {
+			return fmt.Errorf("invalid table name: %w", err)
+		}
+		c.tableName = sanitizedTableName
+		return nil
+	}
+}
+
+// WithMigrationsTable sets the table recording applied migrations, <table>_migrations by default
+func WithMigrationsTable(tableName string) MigrateOption {
+	return func(c *migrateConfig) error {
+		sanitizedTableName, err := sanitizeTableName(tableName)
+		if err != nil {
+			return fmt.Errorf("invalid migrations table name: %w", err)
+		}
+		c.migrationsTable = sanitizedTableName
+		return nil
+	}
+}
+
+// migrationData is passed to the migration templates
+type migrationData struct {
+	dialect   Dialect
+	tableName string
+}
+
+// Table returns the quoted outbox table name
+func (d migrationData) Table() string {
+	return d.dialect.QuoteIdentifier(d.tableName)
+}
+
+// DeadLetterTable returns the quoted dead-letter table name
+func (d migrationData) DeadLetterTable() string {
+	return d.dialect.QuoteIdentifier(deadLetterTableName(d.tableName))
+}
+
+// ArchiveTable returns the quoted table keeping published messages removed by the Cleaner
+func (d migrationData) ArchiveTable() string {
+	return d.dialect.QuoteIdentifier(archiveTableName(d.tableName))
+}
+
+// SequenceTable returns the quoted table holding the last sequence of each aggregate key
+func (d migrationData) SequenceTable() string {
+	return d.dialect.QuoteIdentifier(sequenceTableName(d.tableName))
+}
+
+// JSON returns the column type of JSON columns
+func (d migrationData) JSON() string {
+	return d.dialect.JSONType()
+}
+
+// Index returns the name of an index on the outbox table
+func (d migrationData) Index(suffix string) string {
+	return "idx_" + strings.ReplaceAll(d.tableName, ".", "_") + "_" + suffix
+}
+
+// MigrationFS returns the outbox migrations of the dialect rendered for the table,
+// as .sql files at the root of the returned file system.
+//
+// The files can be applied with entx.Migrate(ctx, db, fsys, "."), which runs every
+// file on each call; Migrate records applied versions and runs each file once.
+func MigrationFS(dialect Dialect, tableName string) (fs.FS, error) {
+	if err := dialect.validate(); err != nil {
+		return nil, err
+	}
+	sanitizedTableName, err := sanitizeTableName(tableName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid table name: %w", err)
+	}
+
+	migrations, err := renderMigrations(dialect, sanitizedTableName)
+	if err != nil {
+		return nil, err
+	}
+
+	fsys := make(fstest.MapFS, len(migrations))
+	for _, m := range migrations {
+		fsys[m.version] = &fstest.MapFile{Data: []byte(m.sql), Mode: 0o444}
+	}
+	return fsys, nil
+}
+
+// Migrate creates or upgrades the outbox table.
+// Applied versions are recorded in the migrations table, so Migrate can run on every start.
+// Concurrent runs wait for each other on an advisory lock, held on a single connection
+// when db is a pool such as *sql.DB.
+func Migrate(ctx context.Context, db MigrationDB, opts ...MigrateOption) error {
+	if db == nil {
+		return fmt.Errorf("db cannot be nil")
+	}
+
+	config := migrateConfig{
+		tableName: defaultTableName,
+	}
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return fmt.Errorf("failed to apply migrate option: %w", err)
+		}
+	}
+	if config.dialect == "" {
+		dialect, err := detectDialect(db)
+		if err != nil {
+			return fmt.Errorf("failed to detect dialect: %w", err)
+		}
+		config.dialect = dialect
+	}
+	if config.migrationsTable == "" {
+		config.migrationsTable = config.tableName + "_migrations"
+	}
+
+	migrations, err := renderMigrations(config.dialect, config.tableName)
+	if err != nil {
+		return err
+	}
+
+	// Session locks are held by a connection, so the run uses a single one
+	if pool, ok := db.(interface {
+		Conn(ctx context.Context) (*sql.Conn, error)
+	}); ok {
+		conn, err := pool.Conn(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get migration connection: %w", err)
+		}
+		defer conn.Close()
+		db = conn
+	}
+
+	unlock, err := lockMigrations(ctx, db, config.dialect, config.migrationsTable)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	migrationsTable := config.dialect.QuoteIdentifier(config.migrationsTable)
+
+	const createQuery = "CREATE TABLE IF NOT EXISTS %s (version VARCHAR(255) NOT NULL PRIMARY KEY, applied_at TIMESTAMP NOT NULL)"
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(createQuery, migrationsTable)); err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	applied, err := appliedMigrations(ctx, db, migrationsTable)
+	if err != nil {
+		return err
+	}
+
+	const insertQuery = "INSERT INTO %s (version, applied_at) VALUES (%s)"
+	recordQuery := fmt.Sprintf(insertQuery, migrationsTable, config.dialect.placeholders(1, 2))
+
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+		if err := applyMigration(ctx, db, config.dialect, m, recordQuery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMigration runs the statements of the migration and records its version.
+// Postgres and SQLite run them in one transaction. MySQL commits each DDL statement
+// implicitly, so statements adding an existing column or index are skipped instead,
+// letting a partly applied migration run again.
+func applyMigration(ctx context.Context, db MigrationDB, dialect Dialect, m migration, recordQuery string) error {
+	apply := func(exec Executor) error {
+		for _, stmt := range splitStatements(m.sql) {
+			if _, err := exec.ExecContext(ctx, stmt); err != nil {
+				if dialect == DialectMySQL && isMySQLDuplicateError(err) {
+					continue
+				}
+				return fmt.Errorf("migration %s failed: %w", m.version, err)
+			}
+		}
+		if _, err := exec.ExecContext(ctx, recordQuery, m.version, time.Now().UTC()); err != nil {
+			return fmt.Errorf("failed to record migration %s: %w", m.version, err)
+		}
+		return nil
+	}
+
+	beginner, ok := db.(TxBeginner)
+	if dialect == DialectMySQL || !ok {
+		return apply(db)
+	}
+
+	tx, err := beginner.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %s: %w", m.version, err)
+	}
+	if err := apply(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", m.version, err)
+	}
+	return nil
+}
+
+// isMySQLDuplicateError reports whether a statement failed with ER_DUP_FIELDNAME (1060)
+// or ER_DUP_KEYNAME (1061). The error is matched by message so the driver is not imported.
+func isMySQLDuplicateError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "Error 1060") || strings.Contains(msg, "Error 1061")
+}
+
+// lockMigrations takes a session lock named after the migrations table, so concurrent
+// runs apply each migration once. SQLite serializes writers and takes no lock.
+// The returned func releases the lock.
+func lockMigrations(ctx context.Context, db MigrationDB, dialect Dialect, migrationsTable string) (func(), error) {
+	switch dialect {
+	case DialectPostgres:
+		key := migrationLockKey(migrationsTable)
+		if _, err := db.ExecContext(ctx, "SELECT pg_advisory_lock($1)", key); err != nil {
+			return nil, fmt.Errorf("failed to lock migrations: %w", err)
+		}
+		return func() {
+			_, _ = db.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", key)
+		}, nil
+
+	case DialectMySQL:
+		name := migrationLockName(migrationsTable)
+		rows, err := db.QueryContext(ctx, "SELECT GET_LOCK(?, -1)", name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock migrations: %w", err)
+		}
+		var locked sql.NullInt64
+		if rows.Next() {
+			err = rows.Scan(&locked)
+		}
+		if closeErr := rows.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock migrations: %w", err)
+		}
+		if locked.Int64 != 1 {
+			return nil, fmt.Errorf("failed to lock migrations: lock %s not acquired", name)
+		}
+		return func() {
+			_, _ = db.ExecContext(context.WithoutCancel(ctx), "SELECT RELEASE_LOCK(?)", name)
+		}, nil
+
+	default:
+		return func() {}, nil
+	}
+}
+
+// migrationLockKey returns the Postgres advisory lock key of the migrations table
+func migrationLockKey(migrationsTable string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte("outbox:" + migrationsTable))
+	return int64(h.Sum64())
+}
+
+// migrationLockName returns the MySQL lock name of the migrations table, at most 64 characters
+func migrationLockName(migrationsTable string) string {
+	name := "outbox:" + migrationsTable
+	if len(name) > 64 {
+		name = fmt.Sprintf("outbox:%x", migrationLockKey(migrationsTable))
+	}
+	return name
+}
+
+// migration is a rendered migration file
+type migration struct {
+	version string
+	sql     string
+}
+
+// renderMigrations renders the embedded migrations of the dialect in file name order
+func renderMigrations(dialect Dialect, tableName string) ([]migration, error) {
+	dir := path.Join("migrations", string(dialect))
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations (%s): %w", dir, err)
+	}
+
+	var migrations []migration
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		content, err := fs.ReadFile(migrationFiles, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration (%s): %w", entry.Name(), err)
+		}
+
+		tmpl, err := template.New(entry.Name()).Parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse migration (%s): %w", entry.Name(), err)
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, migrationData{dialect: dialect, tableName: tableName}); err != nil {
+			return nil, fmt.Errorf("failed to render migration (%s): %w", entry.Name(), err)
+		}
+
+		migrations = append(migrations, migration{version: entry.Name(), sql: buf.String()})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+	return migrations, nil
+}
+
+// appliedMigrations returns the recorded migration versions
+func appliedMigrations(ctx context.Context, db MigrationDB, migrationsTable string) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT version FROM %s", migrationsTable))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// splitStatements splits a migration into statements, since not every driver
+// accepts several statements in one call
+func splitStatements(script string) []string {
+	var statements []string
+	for _, stmt := range strings.Split(script, ";") {
+		if stmt = strings.TrimSpace(stmt); stmt != "" {
+			statements = append(statements, stmt)
+		}
+	}
+	return statements
+}
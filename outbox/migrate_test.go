@@ -0,0 +1,282 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrationFS(t *testing.T) {
+	for _, dialect := range []Dialect{DialectPostgres, DialectMySQL, DialectSQLite} {
+		t.Run(string(dialect), func(t *testing.T) {
+			// When
+			fsys, err := MigrationFS(dialect, "app.events_outbox")
+
+			// Then
+			require.NoError(t, err)
+			content, err := fs.ReadFile(fsys, "0001_create_outbox_message.sql")
+			require.NoError(t, err)
+
+			sql := string(content)
+			assert.Contains(t, sql, "CREATE TABLE IF NOT EXISTS "+dialect.QuoteIdentifier("app.events_outbox")+" (")
+			assert.Contains(t, sql, "payload        "+dialect.JSONType())
+			assert.Contains(t, sql, "idx_app_events_outbox_unpublished")
+			for _, column := range []string{"status", "attempts", "published_at", "created_at", "payload", "metadata"} {
+				assert.Contains(t, sql, column)
+			}
+			assert.NotContains(t, sql, "{{")
+
+			content, err = fs.ReadFile(fsys, "0002_add_dead_letter.sql")
+			require.NoError(t, err)
+			assert.Contains(t, string(content), "CREATE TABLE IF NOT EXISTS "+dialect.QuoteIdentifier("app.events_outbox_dead_letter")+" (")
+			assert.Contains(t, string(content), "next_attempt_at")
+
+			content, err = fs.ReadFile(fsys, "0003_add_aggregate_ordering.sql")
+			require.NoError(t, err)
+			assert.Contains(t, string(content), "CREATE TABLE IF NOT EXISTS "+dialect.QuoteIdentifier("app.events_outbox_sequence")+" (")
+			assert.Contains(t, string(content), "idx_app_events_outbox_aggregate")
+
+			content, err = fs.ReadFile(fsys, "0004_add_deliver_at.sql")
+			require.NoError(t, err)
+			assert.Contains(t, string(content), "deliver_at")
+
+			content, err = fs.ReadFile(fsys, "0005_add_archive.sql")
+			require.NoError(t, err)
+			assert.Contains(t, string(content), "CREATE TABLE IF NOT EXISTS "+dialect.QuoteIdentifier("app.events_outbox_archive")+" (")
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := MigrationFS("oracle", defaultTableName)
+		assert.Error(t, err)
+
+		_, err = MigrationFS(DialectPostgres, "users; DROP TABLE users")
+		assert.Error(t, err)
+	})
+}
+
+func TestMigrate(t *testing.T) {
+	t.Run("applies pending migrations", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1)")).WithArgs(migrationLockKey("outbox_message_migrations")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS "outbox_message_migrations"`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT version FROM "outbox_message_migrations"`)).WillReturnRows(sqlmock.NewRows([]string{"version"}))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS "outbox_message" (`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("CREATE INDEX IF NOT EXISTS idx_outbox_message_unpublished")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("CREATE INDEX IF NOT EXISTS idx_outbox_message_status")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("CREATE INDEX IF NOT EXISTS idx_outbox_message_published_at")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_message_migrations" (version, applied_at) VALUES ($1, $2)`)).
+			WithArgs("0001_create_outbox_message.sql", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`ALTER TABLE "outbox_message" ADD COLUMN IF NOT EXISTS last_error`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`ALTER TABLE "outbox_message" ADD COLUMN IF NOT EXISTS next_attempt_at`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS "outbox_message_dead_letter" (`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("CREATE INDEX IF NOT EXISTS idx_outbox_message_dead_letter_failed_at")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_message_migrations"`)).
+			WithArgs("0002_add_dead_letter.sql", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`ALTER TABLE "outbox_message" ADD COLUMN IF NOT EXISTS aggregate_key`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`ALTER TABLE "outbox_message" ADD COLUMN IF NOT EXISTS aggregate_sequence`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_message_aggregate ON "outbox_message" (aggregate_key, aggregate_sequence)`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`ALTER TABLE "outbox_message_dead_letter" ADD COLUMN IF NOT EXISTS aggregate_key`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`ALTER TABLE "outbox_message_dead_letter" ADD COLUMN IF NOT EXISTS aggregate_sequence`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS "outbox_message_sequence" (`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_message_migrations"`)).
+			WithArgs("0003_add_aggregate_ordering.sql", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`ALTER TABLE "outbox_message" ADD COLUMN IF NOT EXISTS deliver_at TIMESTAMPTZ`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`CREATE INDEX IF NOT EXISTS idx_outbox_message_scheduled ON "outbox_message" (deliver_at)`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_message_migrations"`)).
+			WithArgs("0004_add_deliver_at.sql", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS "outbox_message_archive" (`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`CREATE INDEX IF NOT EXISTS idx_outbox_message_archive_published_at ON "outbox_message_archive" (published_at)`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_message_migrations"`)).
+			WithArgs("0005_add_archive.sql", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// When
+		err = Migrate(context.Background(), db)
+
+		// Then
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("skips applied migrations", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, -1)")).WithArgs("outbox:custom_migrations").
+			WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(1))
+		mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS `custom_migrations`")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM `custom_migrations`")).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("0001_create_outbox_message.sql"))
+		mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE `custom`")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS `custom_dead_letter`")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `custom_migrations`")).
+			WithArgs("0002_add_dead_letter.sql", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE `custom`")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE `custom_dead_letter`")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS `custom_sequence`")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `custom_migrations`")).
+			WithArgs("0003_add_aggregate_ordering.sql", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE `custom`")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `custom_migrations`")).
+			WithArgs("0004_add_deliver_at.sql", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS `custom_archive`")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `custom_migrations`")).
+			WithArgs("0005_add_archive.sql", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).WithArgs("outbox:custom_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// When
+		err = Migrate(context.Background(), db, WithMigrationDialect(DialectMySQL), WithMigrationTableName("custom"))
+
+		// Then
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed migration is rolled back", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS "outbox_message_migrations"`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT version FROM "outbox_message_migrations"`)).WillReturnRows(sqlmock.NewRows([]string{"version"}))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS "outbox_message" (`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("CREATE INDEX IF NOT EXISTS idx_outbox_message_unpublished")).WillReturnError(errors.New("disk full"))
+		mock.ExpectRollback()
+		mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// When
+		err = Migrate(context.Background(), db)
+
+		// Then
+		assert.ErrorContains(t, err, "migration 0001_create_outbox_message.sql failed: disk full")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("mysql skips existing columns", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, -1)")).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(1))
+		mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS `outbox_message_migrations`")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM `outbox_message_migrations`")).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).
+				AddRow("0001_create_outbox_message.sql").AddRow("0002_add_dead_letter.sql").AddRow("0003_add_aggregate_ordering.sql").AddRow("0005_add_archive.sql"))
+		mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE `outbox_message`")).
+			WillReturnError(errors.New("Error 1060 (42S21): Duplicate column name 'deliver_at'"))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `outbox_message_migrations`")).
+			WithArgs("0004_add_deliver_at.sql", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// When
+		err = Migrate(context.Background(), db, WithMigrationDialect(DialectMySQL))
+
+		// Then
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("mysql lock not acquired", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, -1)")).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(nil))
+
+		// When
+		err = Migrate(context.Background(), db, WithMigrationDialect(DialectMySQL))
+
+		// Then
+		assert.ErrorContains(t, err, "failed to lock migrations")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		// Given
+		db := openSQLite(t)
+		ctx := context.Background()
+
+		// When
+		require.NoError(t, Migrate(ctx, db, WithMigrationDialect(DialectSQLite)))
+		err := Migrate(ctx, db, WithMigrationDialect(DialectSQLite))
+
+		// Then
+		require.NoError(t, err)
+		var versions int
+		require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM "outbox_message_migrations"`).Scan(&versions))
+		assert.Equal(t, 5, versions)
+
+		publisher, err := NewPublisher(db, WithDialect(DialectSQLite))
+		require.NoError(t, err)
+		msg, err := NewMessageBuilder().
+			SetEventTopic("orders").
+			SetEventDomain("shop").
+			SetEventType("order.created").
+			SetObjectType("order").
+			SetAggregateKey("order-1").
+			Build()
+		require.NoError(t, err)
+		require.NoError(t, publisher.Publish(ctx, msg))
+		for _, table := range []string{"outbox_message", "outbox_message_sequence"} {
+			var count int
+			require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM "`+table+`"`).Scan(&count))
+			assert.Equal(t, 1, count, table)
+		}
+		for _, table := range []string{"outbox_message_dead_letter", "outbox_message_archive"} {
+			var count int
+			require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM "`+table+`"`).Scan(&count))
+			assert.Zero(t, count, table)
+		}
+	})
+
+	t.Run("invalid dialect", func(t *testing.T) {
+		// Given
+		db, _, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		// When
+		err = Migrate(context.Background(), db, WithMigrationDialect("oracle"))
+
+		// Then
+		assert.Error(t, err)
+	})
+}
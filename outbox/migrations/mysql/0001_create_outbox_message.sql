@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS {{.Table}} (
+    event_id       VARCHAR(64)  NOT NULL,
+    event_topic    VARCHAR(255) NOT NULL,
+    event_domain   VARCHAR(255) NOT NULL,
+    event_type     VARCHAR(255) NOT NULL,
+    object_type    VARCHAR(255) NOT NULL,
+    producer       VARCHAR(255) NOT NULL DEFAULT '',
+    correlation_id VARCHAR(255) NOT NULL DEFAULT '',
+    payload        {{.JSON}}    NULL,
+    metadata       {{.JSON}}    NULL,
+    status         VARCHAR(16)  NOT NULL DEFAULT 'pending',
+    attempts       INT          NOT NULL DEFAULT 0,
+    created_at     DATETIME(6)  NOT NULL,
+    published_at   DATETIME(6)  NULL,
+    PRIMARY KEY (event_id),
+    INDEX {{.Index "unpublished"}} (published_at, created_at, event_id),
+    INDEX {{.Index "status"}} (status, created_at)
+);
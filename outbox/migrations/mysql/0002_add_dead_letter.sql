@@ -0,0 +1,21 @@
+ALTER TABLE {{.Table}}
+    ADD COLUMN last_error      TEXT        NULL,
+    ADD COLUMN next_attempt_at DATETIME(6) NULL;
+
+CREATE TABLE IF NOT EXISTS {{.DeadLetterTable}} (
+    event_id       VARCHAR(64)  NOT NULL,
+    event_topic    VARCHAR(255) NOT NULL,
+    event_domain   VARCHAR(255) NOT NULL,
+    event_type     VARCHAR(255) NOT NULL,
+    object_type    VARCHAR(255) NOT NULL,
+    producer       VARCHAR(255) NOT NULL DEFAULT '',
+    correlation_id VARCHAR(255) NOT NULL DEFAULT '',
+    payload        {{.JSON}}    NULL,
+    metadata       {{.JSON}}    NULL,
+    attempts       INT          NOT NULL DEFAULT 0,
+    last_error     TEXT         NULL,
+    created_at     DATETIME(6)  NOT NULL,
+    failed_at      DATETIME(6)  NOT NULL,
+    PRIMARY KEY (event_id),
+    INDEX {{.Index "dead_letter_failed_at"}} (failed_at, event_id)
+);
@@ -0,0 +1,14 @@
+ALTER TABLE {{.Table}}
+    ADD COLUMN aggregate_key      VARCHAR(255) NULL,
+    ADD COLUMN aggregate_sequence BIGINT       NULL,
+    ADD UNIQUE INDEX {{.Index "aggregate"}} (aggregate_key, aggregate_sequence);
+
+ALTER TABLE {{.DeadLetterTable}}
+    ADD COLUMN aggregate_key      VARCHAR(255) NULL,
+    ADD COLUMN aggregate_sequence BIGINT       NULL;
+
+CREATE TABLE IF NOT EXISTS {{.SequenceTable}} (
+    aggregate_key VARCHAR(255) NOT NULL,
+    last_sequence BIGINT       NOT NULL,
+    PRIMARY KEY (aggregate_key)
+);
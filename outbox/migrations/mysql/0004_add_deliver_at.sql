@@ -0,0 +1,3 @@
+ALTER TABLE {{.Table}}
+    ADD COLUMN deliver_at DATETIME(6) NULL,
+    ADD INDEX {{.Index "scheduled"}} (published_at, deliver_at);
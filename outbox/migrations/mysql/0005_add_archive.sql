@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS {{.ArchiveTable}} (
+    event_id           VARCHAR(64)  NOT NULL,
+    event_topic        VARCHAR(255) NOT NULL,
+    event_domain       VARCHAR(255) NOT NULL,
+    event_type         VARCHAR(255) NOT NULL,
+    object_type        VARCHAR(255) NOT NULL,
+    producer           VARCHAR(255) NOT NULL DEFAULT '',
+    correlation_id     VARCHAR(255) NOT NULL DEFAULT '',
+    payload            {{.JSON}}    NULL,
+    metadata           {{.JSON}}    NULL,
+    aggregate_key      VARCHAR(255) NULL,
+    aggregate_sequence BIGINT       NULL,
+    created_at         DATETIME(6)  NOT NULL,
+    published_at       DATETIME(6)  NOT NULL,
+    PRIMARY KEY (event_id),
+    INDEX {{.Index "archive_published_at"}} (published_at)
+);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS {{.Table}} (
+    event_id       VARCHAR(64)  NOT NULL PRIMARY KEY,
+    event_topic    VARCHAR(255) NOT NULL,
+    event_domain   VARCHAR(255) NOT NULL,
+    event_type     VARCHAR(255) NOT NULL,
+    object_type    VARCHAR(255) NOT NULL,
+    producer       VARCHAR(255) NOT NULL DEFAULT '',
+    correlation_id VARCHAR(255) NOT NULL DEFAULT '',
+    payload        {{.JSON}},
+    metadata       {{.JSON}},
+    status         VARCHAR(16)  NOT NULL DEFAULT 'pending',
+    attempts       INTEGER      NOT NULL DEFAULT 0,
+    created_at     TIMESTAMPTZ  NOT NULL,
+    published_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS {{.Index "unpublished"}} ON {{.Table}} (created_at, event_id) WHERE published_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS {{.Index "status"}} ON {{.Table}} (status, created_at);
+
+CREATE INDEX IF NOT EXISTS {{.Index "published_at"}} ON {{.Table}} (published_at) WHERE published_at IS NOT NULL;
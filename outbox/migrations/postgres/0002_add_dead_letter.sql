@@ -0,0 +1,21 @@
+ALTER TABLE {{.Table}} ADD COLUMN IF NOT EXISTS last_error TEXT;
+
+ALTER TABLE {{.Table}} ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS {{.DeadLetterTable}} (
+    event_id       VARCHAR(64)  NOT NULL PRIMARY KEY,
+    event_topic    VARCHAR(255) NOT NULL,
+    event_domain   VARCHAR(255) NOT NULL,
+    event_type     VARCHAR(255) NOT NULL,
+    object_type    VARCHAR(255) NOT NULL,
+    producer       VARCHAR(255) NOT NULL DEFAULT '',
+    correlation_id VARCHAR(255) NOT NULL DEFAULT '',
+    payload        {{.JSON}},
+    metadata       {{.JSON}},
+    attempts       INTEGER      NOT NULL DEFAULT 0,
+    last_error     TEXT,
+    created_at     TIMESTAMPTZ  NOT NULL,
+    failed_at      TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS {{.Index "dead_letter_failed_at"}} ON {{.DeadLetterTable}} (failed_at, event_id);
@@ -0,0 +1,14 @@
+ALTER TABLE {{.Table}} ADD COLUMN IF NOT EXISTS aggregate_key VARCHAR(255);
+
+ALTER TABLE {{.Table}} ADD COLUMN IF NOT EXISTS aggregate_sequence BIGINT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS {{.Index "aggregate"}} ON {{.Table}} (aggregate_key, aggregate_sequence);
+
+ALTER TABLE {{.DeadLetterTable}} ADD COLUMN IF NOT EXISTS aggregate_key VARCHAR(255);
+
+ALTER TABLE {{.DeadLetterTable}} ADD COLUMN IF NOT EXISTS aggregate_sequence BIGINT;
+
+CREATE TABLE IF NOT EXISTS {{.SequenceTable}} (
+    aggregate_key VARCHAR(255) NOT NULL PRIMARY KEY,
+    last_sequence BIGINT       NOT NULL
+);
@@ -0,0 +1,3 @@
+ALTER TABLE {{.Table}} ADD COLUMN IF NOT EXISTS deliver_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS {{.Index "scheduled"}} ON {{.Table}} (deliver_at) WHERE published_at IS NULL AND deliver_at IS NOT NULL;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS {{.ArchiveTable}} (
+    event_id           VARCHAR(64)  NOT NULL PRIMARY KEY,
+    event_topic        VARCHAR(255) NOT NULL,
+    event_domain       VARCHAR(255) NOT NULL,
+    event_type         VARCHAR(255) NOT NULL,
+    object_type        VARCHAR(255) NOT NULL,
+    producer           VARCHAR(255) NOT NULL DEFAULT '',
+    correlation_id     VARCHAR(255) NOT NULL DEFAULT '',
+    payload            {{.JSON}},
+    metadata           {{.JSON}},
+    aggregate_key      VARCHAR(255),
+    aggregate_sequence BIGINT,
+    created_at         TIMESTAMPTZ  NOT NULL,
+    published_at       TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS {{.Index "archive_published_at"}} ON {{.ArchiveTable}} (published_at);
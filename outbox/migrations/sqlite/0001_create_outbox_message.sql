@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS {{.Table}} (
+    event_id       TEXT    NOT NULL PRIMARY KEY,
+    event_topic    TEXT    NOT NULL,
+    event_domain   TEXT    NOT NULL,
+    event_type     TEXT    NOT NULL,
+    object_type    TEXT    NOT NULL,
+    producer       TEXT    NOT NULL DEFAULT '',
+    correlation_id TEXT    NOT NULL DEFAULT '',
+    payload        {{.JSON}},
+    metadata       {{.JSON}},
+    status         TEXT    NOT NULL DEFAULT 'pending',
+    attempts       INTEGER NOT NULL DEFAULT 0,
+    created_at     TIMESTAMP NOT NULL,
+    published_at   TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS {{.Index "unpublished"}} ON {{.Table}} (created_at, event_id) WHERE published_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS {{.Index "status"}} ON {{.Table}} (status, created_at);
+
+CREATE INDEX IF NOT EXISTS {{.Index "published_at"}} ON {{.Table}} (published_at) WHERE published_at IS NOT NULL;
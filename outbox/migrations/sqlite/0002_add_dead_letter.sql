@@ -0,0 +1,21 @@
+ALTER TABLE {{.Table}} ADD COLUMN last_error TEXT;
+
+ALTER TABLE {{.Table}} ADD COLUMN next_attempt_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS {{.DeadLetterTable}} (
+    event_id       TEXT    NOT NULL PRIMARY KEY,
+    event_topic    TEXT    NOT NULL,
+    event_domain   TEXT    NOT NULL,
+    event_type     TEXT    NOT NULL,
+    object_type    TEXT    NOT NULL,
+    producer       TEXT    NOT NULL DEFAULT '',
+    correlation_id TEXT    NOT NULL DEFAULT '',
+    payload        {{.JSON}},
+    metadata       {{.JSON}},
+    attempts       INTEGER NOT NULL DEFAULT 0,
+    last_error     TEXT,
+    created_at     TIMESTAMP NOT NULL,
+    failed_at      TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS {{.Index "dead_letter_failed_at"}} ON {{.DeadLetterTable}} (failed_at, event_id);
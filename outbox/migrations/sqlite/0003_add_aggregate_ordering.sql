@@ -0,0 +1,14 @@
+ALTER TABLE {{.Table}} ADD COLUMN aggregate_key TEXT;
+
+ALTER TABLE {{.Table}} ADD COLUMN aggregate_sequence INTEGER;
+
+CREATE UNIQUE INDEX IF NOT EXISTS {{.Index "aggregate"}} ON {{.Table}} (aggregate_key, aggregate_sequence);
+
+ALTER TABLE {{.DeadLetterTable}} ADD COLUMN aggregate_key TEXT;
+
+ALTER TABLE {{.DeadLetterTable}} ADD COLUMN aggregate_sequence INTEGER;
+
+CREATE TABLE IF NOT EXISTS {{.SequenceTable}} (
+    aggregate_key TEXT    NOT NULL PRIMARY KEY,
+    last_sequence INTEGER NOT NULL
+);
@@ -0,0 +1,3 @@
+ALTER TABLE {{.Table}} ADD COLUMN deliver_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS {{.Index "scheduled"}} ON {{.Table}} (deliver_at) WHERE published_at IS NULL AND deliver_at IS NOT NULL;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS {{.ArchiveTable}} (
+    event_id           TEXT      NOT NULL PRIMARY KEY,
+    event_topic        TEXT      NOT NULL,
+    event_domain       TEXT      NOT NULL,
+    event_type         TEXT      NOT NULL,
+    object_type        TEXT      NOT NULL,
+    producer           TEXT      NOT NULL DEFAULT '',
+    correlation_id     TEXT      NOT NULL DEFAULT '',
+    payload            {{.JSON}},
+    metadata           {{.JSON}},
+    aggregate_key      TEXT,
+    aggregate_sequence INTEGER,
+    created_at         TIMESTAMP NOT NULL,
+    published_at       TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS {{.Index "archive_published_at"}} ON {{.ArchiveTable}} (published_at);
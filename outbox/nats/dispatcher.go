@@ -0,0 +1,167 @@
+// Package nats dispatches outbox messages to NATS JetStream subjects.
+package nats
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/carped99/gosdk/outbox"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Message headers set on every dispatched message.
+// The EventID is also set as jetstream.MsgIDHeader for deduplication.
+const (
+	HeaderEventID       = outbox.AttributeEventID
+	HeaderEventDomain   = outbox.AttributeEventDomain
+	HeaderEventType     = outbox.AttributeEventType
+	HeaderObjectType    = outbox.AttributeObjectType
+	HeaderProducer      = outbox.AttributeProducer
+	HeaderCorrelationID = outbox.AttributeCorrelationID
+	HeaderCreatedAt     = outbox.AttributeCreatedAt
+	HeaderMetadata      = outbox.AttributeMetadata
+	HeaderAggregateKey  = outbox.AttributeAggregateKey
+	HeaderSequence      = outbox.AttributeSequence
+)
+
+// Publisher is the part of jetstream.JetStream used by the Dispatcher
+type Publisher interface {
+	PublishMsg(ctx context.Context, msg *natsgo.Msg, opts ...jetstream.PublishOpt) (*jetstream.PubAck, error)
+}
+
+type config struct {
+	subject func(msg *outbox.Message) string
+	opts    []jetstream.PublishOpt
+}
+
+// Option configures a Dispatcher
+type Option func(*config) error
+
+// WithSubjectFunc sets the subject of dispatched messages, EventTopic by default
+func WithSubjectFunc(fn func(msg *outbox.Message) string) Option {
+	return func(c *config) error {
+		if fn == nil {
+			return fmt.Errorf("subject func cannot be nil")
+		}
+		c.subject = fn
+		return nil
+	}
+}
+
+// WithSubjectPrefix prefixes the EventTopic with prefix and a dot, such as "events.orders"
+func WithSubjectPrefix(prefix string) Option {
+	return func(c *config) error {
+		if prefix == "" {
+			return fmt.Errorf("subject prefix cannot be empty")
+		}
+		c.subject = func(msg *outbox.Message) string {
+			return prefix + "." + msg.EventTopic
+		}
+		return nil
+	}
+}
+
+// WithPublishOptions adds options to every publish, such as jetstream.WithExpectStream
+func WithPublishOptions(opts ...jetstream.PublishOpt) Option {
+	return func(c *config) error {
+		c.opts = append(c.opts, opts...)
+		return nil
+	}
+}
+
+// Dispatcher publishes outbox messages to JetStream.
+// It implements outbox.Dispatcher.
+//
+// Messages carry their EventID in the Nats-Msg-Id header, so a message relayed again
+// within the duplicate window of the stream is stored once.
+type Dispatcher struct {
+	publisher Publisher
+	config    config
+}
+
+var _ outbox.Dispatcher = (*Dispatcher)(nil)
+
+// NewDispatcher creates a new Dispatcher publishing with publisher, usually a jetstream.JetStream
+func NewDispatcher(publisher Publisher, opts ...Option) (*Dispatcher, error) {
+	if publisher == nil {
+		return nil, fmt.Errorf("publisher cannot be nil")
+	}
+
+	c := config{
+		subject: func(msg *outbox.Message) string {
+			return msg.EventTopic
+		},
+	}
+	for _, opt := range opts {
+		if err := opt(&c); err != nil {
+			return nil, fmt.Errorf("failed to apply nats option: %w", err)
+		}
+	}
+
+	return &Dispatcher{publisher: publisher, config: c}, nil
+}
+
+// Dispatch publishes the message and waits for the stream to acknowledge it.
+// A duplicate acknowledgement counts as delivered.
+func (d *Dispatcher) Dispatch(ctx context.Context, msg *outbox.Message) error {
+	m, err := d.Msg(msg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := d.publisher.PublishMsg(ctx, m, d.config.opts...); err != nil {
+		return fmt.Errorf("failed to publish message %s to %s: %w", msg.EventID, m.Subject, err)
+	}
+	return nil
+}
+
+// Msg converts the message to a NATS message
+func (d *Dispatcher) Msg(msg *outbox.Message) (*natsgo.Msg, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("message cannot be nil")
+	}
+
+	subject := d.config.subject(msg)
+	if subject == "" {
+		return nil, outbox.ErrInvalidEventTopic
+	}
+
+	m := natsgo.NewMsg(subject)
+	m.Data = msg.Payload
+	m.Header.Set(jetstream.MsgIDHeader, msg.EventID)
+
+	attributes, err := outbox.MessageAttributes(msg)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range attributes {
+		m.Header.Set(a.Key, a.Value)
+	}
+	return m, nil
+}
+
+// MessageFromMsg converts a NATS message published by a Dispatcher back to an outbox message.
+// The EventTopic is the subject, unless the subject was changed with WithSubjectFunc or WithSubjectPrefix.
+func MessageFromMsg(m *natsgo.Msg) (*outbox.Message, error) {
+	if m == nil {
+		return nil, fmt.Errorf("nats message cannot be nil")
+	}
+
+	msg, err := outbox.MessageFromAttributes(m.Header.Get)
+	if err != nil {
+		return nil, err
+	}
+	msg.EventTopic = m.Subject
+	if msg.EventID == "" {
+		msg.EventID = m.Header.Get(jetstream.MsgIDHeader)
+	}
+	if len(m.Data) > 0 {
+		msg.Payload = append([]byte(nil), m.Data...)
+	}
+
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
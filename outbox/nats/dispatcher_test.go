@@ -0,0 +1,192 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/carped99/gosdk/outbox"
+	"github.com/nats-io/nats-server/v2/server"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runJetStream starts an embedded NATS server with JetStream and a stream capturing subjects
+func runJetStream(t *testing.T, subjects ...string) (jetstream.JetStream, jetstream.Stream) {
+	t.Helper()
+
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+	srv.Start()
+	t.Cleanup(srv.Shutdown)
+	require.True(t, srv.ReadyForConnections(5*time.Second))
+
+	nc, err := natsgo.Connect(srv.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(nc.Close)
+
+	js, err := jetstream.New(nc)
+	require.NoError(t, err)
+
+	stream, err := js.CreateStream(context.Background(), jetstream.StreamConfig{
+		Name:       "EVENTS",
+		Subjects:   subjects,
+		Duplicates: time.Minute,
+	})
+	require.NoError(t, err)
+	return js, stream
+}
+
+func newTestMessage(t *testing.T) *outbox.Message {
+	msg, err := outbox.NewMessageBuilder().
+		SetEventTopic("orders").
+		SetEventDomain("shop").
+		SetEventType("order.created").
+		SetObjectType("order").
+		SetProducer("order-service").
+		SetCorrelationID("corr-1").
+		SetPayload(json.RawMessage(`{"order_id":"o-1"}`)).
+		Build()
+	require.NoError(t, err)
+	return msg
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	t.Run("publishes to the event topic subject", func(t *testing.T) {
+		// Given
+		js, stream := runJetStream(t, "orders")
+		dispatcher, err := NewDispatcher(js)
+		require.NoError(t, err)
+		msg := newTestMessage(t)
+
+		// When
+		err = dispatcher.Dispatch(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		stored, err := stream.GetMsg(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, "orders", stored.Subject)
+		assert.JSONEq(t, `{"order_id":"o-1"}`, string(stored.Data))
+		assert.Equal(t, msg.EventID, stored.Header.Get(jetstream.MsgIDHeader))
+		assert.Equal(t, "order.created", stored.Header.Get(HeaderEventType))
+		assert.Equal(t, "corr-1", stored.Header.Get(HeaderCorrelationID))
+	})
+
+	t.Run("deduplicates relayed messages by event id", func(t *testing.T) {
+		// Given
+		js, stream := runJetStream(t, "orders")
+		dispatcher, err := NewDispatcher(js)
+		require.NoError(t, err)
+		msg := newTestMessage(t)
+
+		// When
+		require.NoError(t, dispatcher.Dispatch(context.Background(), msg))
+		err = dispatcher.Dispatch(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		info, err := stream.Info(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), info.State.Msgs)
+	})
+
+	t.Run("subject prefix", func(t *testing.T) {
+		// Given
+		js, stream := runJetStream(t, "events.>")
+		dispatcher, err := NewDispatcher(js, WithSubjectPrefix("events"), WithPublishOptions(jetstream.WithExpectStream("EVENTS")))
+		require.NoError(t, err)
+
+		// When
+		err = dispatcher.Dispatch(context.Background(), newTestMessage(t))
+
+		// Then
+		require.NoError(t, err)
+		stored, err := stream.GetMsg(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, "events.orders", stored.Subject)
+	})
+
+	t.Run("no stream for the subject", func(t *testing.T) {
+		// Given
+		js, _ := runJetStream(t, "orders")
+		dispatcher, err := NewDispatcher(js, WithSubjectPrefix("unknown"))
+		require.NoError(t, err)
+
+		// When
+		err = dispatcher.Dispatch(context.Background(), newTestMessage(t))
+
+		// Then
+		assert.ErrorIs(t, err, jetstream.ErrNoStreamResponse)
+	})
+}
+
+func TestMessageFromMsg(t *testing.T) {
+	// Given
+	js, _ := runJetStream(t, "orders")
+	dispatcher, err := NewDispatcher(js)
+	require.NoError(t, err)
+	msg := newTestMessage(t)
+	msg.Metadata = json.RawMessage(`{"trace_id":"t-1"}`)
+	msg.AggregateKey = "order-1"
+	msg.Sequence = 3
+	m, err := dispatcher.Msg(msg)
+	require.NoError(t, err)
+
+	// When
+	decoded, err := MessageFromMsg(m)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, msg.EventID, decoded.EventID)
+	assert.Equal(t, msg.EventTopic, decoded.EventTopic)
+	assert.Equal(t, msg.EventDomain, decoded.EventDomain)
+	assert.Equal(t, msg.EventType, decoded.EventType)
+	assert.Equal(t, msg.ObjectType, decoded.ObjectType)
+	assert.Equal(t, msg.Producer, decoded.Producer)
+	assert.Equal(t, msg.CorrelationID, decoded.CorrelationID)
+	assert.JSONEq(t, string(msg.Payload), string(decoded.Payload))
+	assert.JSONEq(t, string(msg.Metadata), string(decoded.Metadata))
+	assert.Equal(t, "order-1", decoded.AggregateKey)
+	assert.Equal(t, int64(3), decoded.Sequence)
+	assert.WithinDuration(t, msg.CreatedAt, decoded.CreatedAt, time.Microsecond)
+}
+
+func TestMessageFromMsg_RestoresPayload(t *testing.T) {
+	// Given
+	compressor, err := outbox.NewPayloadCompressor(outbox.CompressionZstd, outbox.WithCompressionThreshold(64))
+	require.NoError(t, err)
+	payload := `{"items":"` + strings.Repeat("abc", 100) + `"}`
+	msg := newTestMessage(t)
+	msg.Payload = json.RawMessage(payload)
+	msg.Metadata = json.RawMessage(`{"tenant": "acme"}`)
+	require.NoError(t, compressor.Transform(context.Background(), msg))
+
+	js, stream := runJetStream(t, "orders")
+	dispatcher, err := NewDispatcher(js)
+	require.NoError(t, err)
+	require.NoError(t, dispatcher.Dispatch(context.Background(), msg))
+	stored, err := stream.GetMsg(context.Background(), 1)
+	require.NoError(t, err)
+
+	// When
+	decoded, err := MessageFromMsg(&natsgo.Msg{Subject: stored.Subject, Header: stored.Header, Data: stored.Data})
+	require.NoError(t, err)
+	err = outbox.RestorePayload(context.Background(), decoded, compressor)
+
+	// Then
+	require.NoError(t, err)
+	assert.JSONEq(t, payload, string(decoded.Payload))
+	assert.JSONEq(t, `{"tenant":"acme"}`, string(decoded.Metadata))
+}
@@ -0,0 +1,219 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MetadataBlobKey records the blob store key of an offloaded payload
+const MetadataBlobKey = "payload_blob_key"
+
+// defaultOffloadThreshold is the payload size from which payloads are offloaded
+const defaultOffloadThreshold = 256 << 10
+
+// ErrBlobNotFound is returned by a BlobStore without the requested blob
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore stores the payloads offloaded by a PayloadOffloader, such as an object storage bucket
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// FileBlobStore stores blobs as files in a directory
+type FileBlobStore struct {
+	dir string
+}
+
+var _ BlobStore = (*FileBlobStore)(nil)
+
+// NewFileBlobStore creates a new FileBlobStore writing to dir, created when missing
+func NewFileBlobStore(dir string) (*FileBlobStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("directory cannot be empty")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	return &FileBlobStore{dir: dir}, nil
+}
+
+// Put writes the blob to a temporary file and renames it, so readers never see a partial blob
+func (s *FileBlobStore) Put(_ context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(s.dir, "."+key+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create blob %s: %w", key, err)
+	}
+	defer func() { _ = os.Remove(file.Name()) }()
+
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write blob %s: %w", key, err)
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to sync blob %s: %w", key, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close blob %s: %w", key, err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to write blob %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *FileBlobStore) Get(_ context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrBlobNotFound, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", key, err)
+	}
+	return data, nil
+}
+
+// Delete removes the blob; deleting a missing blob is not an error
+func (s *FileBlobStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *FileBlobStore) path(key string) (string, error) {
+	if key == "" || key == "." || key == ".." || strings.HasPrefix(key, ".") || strings.ContainsAny(key, `/\`) {
+		return "", fmt.Errorf("invalid blob key '%s'", key)
+	}
+	return filepath.Join(s.dir, key), nil
+}
+
+// offloadedPayload is the payload of an offloaded message
+type offloadedPayload struct {
+	BlobKey string `json:"blob_key"`
+	Size    int    `json:"size"`
+	SHA256  []byte `json:"sha256"`
+}
+
+type offloaderConfig struct {
+	threshold int
+}
+
+// OffloaderOption defines a function that configures a PayloadOffloader
+type OffloaderOption func(*offloaderConfig) error
+
+// WithOffloadThreshold sets the payload size in bytes from which payloads are offloaded, 256 KiB by default
+func WithOffloadThreshold(threshold int) OffloaderOption {
+	return func(c *offloaderConfig) error {
+		if threshold <= 0 {
+			return fmt.Errorf("offload threshold must be greater than 0")
+		}
+		c.threshold = threshold
+		return nil
+	}
+}
+
+// PayloadOffloader moves payloads above a size threshold to a BlobStore, keyed by EventID,
+// keeping only a reference in the message. Restore reads the payload back from the store.
+//
+// Blobs are not deleted with their messages; the blob of a message whose publish
+// failed, or that was removed by retention, should be expired by the store.
+// Placed before a PayloadCompressor, the threshold applies to the original payload size.
+type PayloadOffloader struct {
+	store  BlobStore
+	config offloaderConfig
+}
+
+var (
+	_ PayloadTransformer = (*PayloadOffloader)(nil)
+	_ PayloadRestorer    = (*PayloadOffloader)(nil)
+)
+
+// NewPayloadOffloader creates a new PayloadOffloader storing payloads in store
+func NewPayloadOffloader(store BlobStore, opts ...OffloaderOption) (*PayloadOffloader, error) {
+	if store == nil {
+		return nil, fmt.Errorf("blob store cannot be nil")
+	}
+
+	config := offloaderConfig{threshold: defaultOffloadThreshold}
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return nil, fmt.Errorf("failed to apply offloader option: %w", err)
+		}
+	}
+	return &PayloadOffloader{store: store, config: config}, nil
+}
+
+// Transform stores the payload of the message when it reaches the threshold
+func (o *PayloadOffloader) Transform(ctx context.Context, msg *Message) error {
+	if len(msg.Payload) < o.config.threshold {
+		return nil
+	}
+	if _, ok := MetadataString(msg, MetadataBlobKey); ok {
+		return fmt.Errorf("payload of message %s is already offloaded", msg.EventID)
+	}
+
+	digest := sha256.Sum256(msg.Payload)
+	payload, err := json.Marshal(offloadedPayload{BlobKey: msg.EventID, Size: len(msg.Payload), SHA256: digest[:]})
+	if err != nil {
+		return &MarshalError{Field: "payload", Err: err}
+	}
+	if err := setMetadata(msg, map[string]any{MetadataBlobKey: msg.EventID}); err != nil {
+		return err
+	}
+
+	if err := o.store.Put(ctx, msg.EventID, msg.Payload); err != nil {
+		return fmt.Errorf("failed to offload payload of message %s: %w", msg.EventID, err)
+	}
+	msg.Payload = payload
+	return nil
+}
+
+// Restore reads the payload of a message offloaded by Transform and removes the
+// reference from its metadata; other messages are left as is
+func (o *PayloadOffloader) Restore(ctx context.Context, msg *Message) error {
+	if _, ok := MetadataString(msg, MetadataBlobKey); !ok {
+		return nil
+	}
+
+	var ref offloadedPayload
+	if err := json.Unmarshal(msg.Payload, &ref); err != nil {
+		return fmt.Errorf("invalid payload reference of message %s: %w", msg.EventID, err)
+	}
+	payload, err := o.store.Get(ctx, ref.BlobKey)
+	if err != nil {
+		return fmt.Errorf("failed to load payload of message %s: %w", msg.EventID, err)
+	}
+	digest := sha256.Sum256(payload)
+	if len(payload) != ref.Size || !bytes.Equal(digest[:], ref.SHA256) {
+		return fmt.Errorf("payload of message %s does not match its reference", msg.EventID)
+	}
+
+	if err := setMetadata(msg, map[string]any{MetadataBlobKey: nil}); err != nil {
+		return err
+	}
+	msg.Payload = payload
+	return nil
+}
@@ -0,0 +1,141 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadOffloader(t *testing.T) {
+	large := `{"report":"` + strings.Repeat("x", 2048) + `"}`
+
+	t.Run("offloads and restores the payload", func(t *testing.T) {
+		// Given
+		dir := t.TempDir()
+		store, err := NewFileBlobStore(dir)
+		require.NoError(t, err)
+		offloader, err := NewPayloadOffloader(store, WithOffloadThreshold(1024))
+		require.NoError(t, err)
+		msg := newTransformTestMessage(t, large)
+
+		// When
+		err = offloader.Transform(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		assert.Less(t, len(msg.Payload), 1024)
+		assert.True(t, json.Valid(msg.Payload))
+		key, ok := MetadataString(msg, MetadataBlobKey)
+		assert.True(t, ok)
+		assert.Equal(t, msg.EventID, key)
+		stored, err := os.ReadFile(filepath.Join(dir, msg.EventID))
+		require.NoError(t, err)
+		assert.JSONEq(t, large, string(stored))
+
+		require.NoError(t, offloader.Restore(context.Background(), msg))
+		assert.JSONEq(t, large, string(msg.Payload))
+		assert.JSONEq(t, `{"tenant":"acme"}`, string(msg.Metadata))
+	})
+
+	t.Run("payloads below the threshold are left as is", func(t *testing.T) {
+		// Given
+		store, err := NewFileBlobStore(t.TempDir())
+		require.NoError(t, err)
+		offloader, err := NewPayloadOffloader(store)
+		require.NoError(t, err)
+		msg := newTransformTestMessage(t, large)
+
+		// When
+		err = offloader.Transform(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		assert.JSONEq(t, large, string(msg.Payload))
+	})
+
+	t.Run("tampered blob", func(t *testing.T) {
+		// Given
+		store, err := NewFileBlobStore(t.TempDir())
+		require.NoError(t, err)
+		offloader, err := NewPayloadOffloader(store, WithOffloadThreshold(1024))
+		require.NoError(t, err)
+		msg := newTransformTestMessage(t, large)
+		require.NoError(t, offloader.Transform(context.Background(), msg))
+		require.NoError(t, store.Put(context.Background(), msg.EventID, []byte(`{}`)))
+
+		// When
+		err = offloader.Restore(context.Background(), msg)
+
+		// Then
+		assert.Error(t, err)
+	})
+
+	t.Run("missing blob", func(t *testing.T) {
+		// Given
+		store, err := NewFileBlobStore(t.TempDir())
+		require.NoError(t, err)
+		offloader, err := NewPayloadOffloader(store, WithOffloadThreshold(1024))
+		require.NoError(t, err)
+		msg := newTransformTestMessage(t, large)
+		require.NoError(t, offloader.Transform(context.Background(), msg))
+		require.NoError(t, store.Delete(context.Background(), msg.EventID))
+
+		// When
+		err = offloader.Restore(context.Background(), msg)
+
+		// Then
+		assert.ErrorIs(t, err, ErrBlobNotFound)
+	})
+}
+
+func TestPublisher_Publish_OffloadAndCompress(t *testing.T) {
+	// Given
+	store, err := NewFileBlobStore(t.TempDir())
+	require.NoError(t, err)
+	offloader, err := NewPayloadOffloader(store, WithOffloadThreshold(64<<10))
+	require.NoError(t, err)
+	compressor, err := NewPayloadCompressor(CompressionZstd, WithCompressionThreshold(1024))
+	require.NoError(t, err)
+
+	executor := &namedExecutor{driver: "postgres"}
+	publisher, err := NewPublisher(executor, WithPayloadTransformers(offloader, compressor))
+	require.NoError(t, err)
+
+	huge := newTransformTestMessage(t, `{"data":"`+strings.Repeat("h", 128<<10)+`"}`)
+	medium := newTransformTestMessage(t, `{"data":"`+strings.Repeat("m", 8<<10)+`"}`)
+
+	// When
+	err = publisher.Publish(context.Background(), huge, medium)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, executor.args, 1)
+	for i, original := range []*Message{huge, medium} {
+		offset := i * insertColumns
+		stored := &Message{
+			EventID:  original.EventID,
+			Payload:  executor.args[0][offset+7].([]byte),
+			Metadata: executor.args[0][offset+8].([]byte),
+		}
+		assert.Less(t, len(stored.Payload), 1024)
+
+		require.NoError(t, RestorePayload(context.Background(), stored, offloader, compressor))
+		assert.Equal(t, string(original.Payload), string(stored.Payload))
+		assert.JSONEq(t, `{"tenant":"acme"}`, string(stored.Metadata))
+	}
+}
+
+func TestFileBlobStore_InvalidKey(t *testing.T) {
+	store, err := NewFileBlobStore(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "..", "../escape", "a/b", ".hidden"} {
+		assert.Error(t, store.Put(context.Background(), key, []byte("x")), key)
+	}
+}
@@ -0,0 +1,25 @@
+package outbox
+
+import "fmt"
+
+// sequenceTableName returns the table holding the last sequence of each aggregate key
+func sequenceTableName(tableName string) string {
+	return tableName + "_sequence"
+}
+
+// sequenceUpsertQuery returns the statement adding a number of sequences to an aggregate key,
+// creating the key on first use. Its arguments are the key and the number of sequences.
+func sequenceUpsertQuery(dialect Dialect, sequenceTable string) string {
+	values := dialect.placeholders(1, 2)
+	switch dialect {
+	case DialectMySQL:
+		return fmt.Sprintf("INSERT INTO %s (aggregate_key, last_sequence) VALUES (%s) ON DUPLICATE KEY UPDATE last_sequence = last_sequence + VALUES(last_sequence)",
+			sequenceTable, values)
+	case DialectSQLite:
+		return fmt.Sprintf("INSERT INTO %s (aggregate_key, last_sequence) VALUES (%s) ON CONFLICT (aggregate_key) DO UPDATE SET last_sequence = last_sequence + excluded.last_sequence",
+			sequenceTable, values)
+	default:
+		return fmt.Sprintf("INSERT INTO %s (aggregate_key, last_sequence) VALUES (%s) ON CONFLICT (aggregate_key) DO UPDATE SET last_sequence = %s.last_sequence + excluded.last_sequence",
+			sequenceTable, values, sequenceTable)
+	}
+}
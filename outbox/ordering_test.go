@@ -0,0 +1,237 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newOrderedMessage(t *testing.T, aggregateKey string) *Message {
+	msg, err := NewMessageBuilder().
+		SetEventTopic("orders").
+		SetEventDomain("shop").
+		SetEventType("order.updated").
+		SetObjectType("order").
+		SetAggregateKey(aggregateKey).
+		Build()
+	require.NoError(t, err)
+	return msg
+}
+
+func TestPublisher_Publish_AggregateKey(t *testing.T) {
+	// Given
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	pub, err := NewPublisher(db)
+	require.NoError(t, err)
+
+	first, unkeyed, second, other := newOrderedMessage(t, "order-1"), newOrderedMessage(t, ""), newOrderedMessage(t, "order-1"), newOrderedMessage(t, "order-2")
+
+	const upsert = `INSERT INTO "outbox_message_sequence" (aggregate_key, last_sequence) VALUES ($1, $2) ON CONFLICT (aggregate_key) DO UPDATE SET last_sequence = "outbox_message_sequence".last_sequence + excluded.last_sequence`
+	const sequence = `(SELECT last_sequence FROM "outbox_message_sequence" WHERE aggregate_key = `
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(upsert)).WithArgs("order-1", 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(upsert)).WithArgs("order-2", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_message" (event_id, event_topic, event_domain, event_type, object_type, producer, correlation_id, payload, metadata, created_at, deliver_at, aggregate_key, aggregate_sequence) VALUES ` +
+		`($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, ` + sequence + `$13) - $14), ` +
+		`($15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, NULL, NULL), ` +
+		`($26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, ` + sequence + `$38) - $39), ` +
+		`($40, $41, $42, $43, $44, $45, $46, $47, $48, $49, $50, $51, ` + sequence + `$52) - $53)`)).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectCommit()
+
+	// When
+	err = pub.Publish(context.Background(), first, unkeyed, second, other)
+
+	// Then
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// namedTx is a namedExecutor standing for a transaction
+type namedTx struct {
+	*namedExecutor
+}
+
+func (namedTx) Commit() error   { return nil }
+func (namedTx) Rollback() error { return nil }
+
+func TestPublisher_Publish_AggregateKeyOffsets(t *testing.T) {
+	// Given
+	executor := &namedExecutor{driver: "mysql"}
+	pub, err := NewPublisher(namedTx{executor})
+	require.NoError(t, err)
+
+	// When
+	err = pub.Publish(context.Background(), newOrderedMessage(t, "order-1"), newOrderedMessage(t, "order-1"), newOrderedMessage(t, "order-1"))
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, executor.queries, 2)
+	assert.Equal(t, "INSERT INTO `outbox_message_sequence` (aggregate_key, last_sequence) VALUES (?, ?) ON DUPLICATE KEY UPDATE last_sequence = last_sequence + VALUES(last_sequence)", executor.queries[0])
+	assert.Equal(t, []interface{}{"order-1", 3}, executor.args[0])
+
+	// The earliest message takes the lowest sequence
+	args := executor.args[1]
+	assert.Equal(t, 2, args[13])
+	assert.Equal(t, 1, args[27])
+	assert.Equal(t, 0, args[41])
+	assert.Equal(t, 3, strings.Count(executor.queries[1], "(SELECT last_sequence FROM `outbox_message_sequence` WHERE aggregate_key = ?) - ?"))
+}
+
+func TestPublisher_Publish_AggregateKeyTransaction(t *testing.T) {
+	t.Run("failed insert rolls back the reservation", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		pub, err := NewPublisher(db, WithRetryPolicy(NoRetry()))
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_message_sequence"`)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_message"`)).WillReturnError(errors.New("unique violation"))
+		mock.ExpectRollback()
+
+		// When
+		err = pub.Publish(context.Background(), newOrderedMessage(t, "order-1"))
+
+		// Then
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("executor without transactions", func(t *testing.T) {
+		// Given
+		executor := &namedExecutor{driver: "postgres"}
+		pub, err := NewPublisher(executor)
+		require.NoError(t, err)
+
+		// When
+		err = pub.Publish(context.Background(), newOrderedMessage(t, "order-1"))
+
+		// Then
+		assert.ErrorIs(t, err, ErrNoTransaction)
+		assert.Empty(t, executor.queries)
+	})
+}
+
+func TestPublisher_Publish_ConcurrentAggregateKey(t *testing.T) {
+	// Given
+	db := openSQLite(t)
+	require.NoError(t, Migrate(context.Background(), db, WithMigrationDialect(DialectSQLite)))
+
+	const publishers, batches, batchSize = 4, 25, 3
+	var wg sync.WaitGroup
+	errs := make(chan error, publishers*batches)
+	for i := 0; i < publishers; i++ {
+		pub, err := NewPublisher(db, WithDialect(DialectSQLite), WithRetryPolicy(NoRetry()))
+		require.NoError(t, err)
+
+		messages := make([]*Message, batches*batchSize)
+		for j := range messages {
+			messages[j] = newOrderedMessage(t, "order-1")
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < len(messages); j += batchSize {
+				errs <- pub.Publish(context.Background(), messages[j:j+batchSize]...)
+			}
+		}()
+	}
+
+	// When
+	wg.Wait()
+	close(errs)
+
+	// Then
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	rows, err := db.Query(`SELECT aggregate_sequence FROM "outbox_message" WHERE aggregate_key = ? ORDER BY aggregate_sequence`, "order-1")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var sequences []int
+	for rows.Next() {
+		var sequence int
+		require.NoError(t, rows.Scan(&sequence))
+		sequences = append(sequences, sequence)
+	}
+	require.NoError(t, rows.Err())
+
+	expected := make([]int, publishers*batches*batchSize)
+	for i := range expected {
+		expected[i] = i + 1
+	}
+	assert.Equal(t, expected, sequences)
+}
+
+func TestMessage_ValidateAggregateKey(t *testing.T) {
+	_, err := NewMessageBuilder().
+		SetEventTopic("orders").
+		SetEventDomain("shop").
+		SetEventType("order.updated").
+		SetObjectType("order").
+		SetAggregateKey(strings.Repeat("k", maxAggregateKeyLength+1)).
+		Build()
+
+	assert.ErrorIs(t, err, ErrInvalidAggregateKey)
+}
+
+func TestRelayBatch_AggregateKey(t *testing.T) {
+	// Given
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	var reported []error
+	dispatcher := &recordingDispatcher{failOn: "id-1"}
+	relay, err := NewRelay(db, dispatcher, WithRelayErrorHandler(func(err error) {
+		reported = append(reported, err)
+	}))
+	require.NoError(t, err)
+
+	rows := sqlmock.NewRows(relayColumns).
+		AddRow("id-1", "test.topic", "test.domain", "test.type", "test.object", nil, nil, nil, nil, time.Now(), 0, "order-1", 4).
+		AddRow("id-2", "test.topic", "test.domain", "test.type", "test.object", nil, nil, nil, nil, time.Now(), 0, "order-2", 7)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).WillReturnRows(rows)
+	mock.ExpectExec(regexp.QuoteMeta(`SET attempts = $1`)).
+		WithArgs(1, "broker unavailable", sqlmock.AnyArg(), "id-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("WHERE event_id IN ($2)")).
+		WithArgs(sqlmock.AnyArg(), "id-2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// When
+	relayed, err := relay.RelayBatch(context.Background())
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, 1, relayed)
+	require.Len(t, dispatcher.messages, 1)
+	assert.Equal(t, "order-2", dispatcher.messages[0].AggregateKey)
+	assert.Equal(t, int64(7), dispatcher.messages[0].Sequence)
+	require.Len(t, reported, 1)
+	var publishErr *PublishError
+	require.ErrorAs(t, reported[0], &publishErr)
+	assert.Equal(t, "id-1", publishErr.MessageID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"fmt"
 	"regexp"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -13,6 +15,16 @@ type Publisher interface {
 	Close() error
 }
 
+// Canceler cancels messages before dispatch. The Publisher returned by NewPublisher
+// and NewTxPublisher implements it:
+//
+//	cancelled, err := pub.(outbox.Canceler).Cancel(ctx, eventID)
+type Canceler interface {
+	// Cancel deletes messages not dispatched yet, such as scheduled messages no longer
+	// needed, and returns the number of messages cancelled.
+	Cancel(ctx context.Context, eventIDs ...string) (int, error)
+}
+
 type Executor interface {
 	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 }
@@ -20,22 +32,46 @@ type Executor interface {
 type publisher struct {
 	executor Executor
 	config   publisherConfig
-	query    string // Pre-built parameterized query to prevent SQL injection
+	table    string // Quoted table name
+	query    string // Pre-built parameterized query for a full batch to prevent SQL injection
+	sequence string // Statement reserving sequences of an aggregate key
 }
 
+var (
+	_ Publisher = (*publisher)(nil)
+	_ Canceler  = (*publisher)(nil)
+)
+
 var (
 	defaultTableName  = "outbox_message"
 	defaultBatchSize  = 10
 	defaultMaxRetries = 3
 
+	// Columns written per message
+	insertColumns = 11
+
+	// Bind parameters per message with an aggregate key, which adds the key and its sequence
+	orderedInsertParameters = insertColumns + 3
+
+	// Maximum number of bind parameters in a single statement (Postgres and MySQL limit)
+	maxQueryParameters = 65535
+
 	// Table name validation regex - only allows alphanumeric, underscore, and dot
 	tableNameRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*(\.[a-zA-Z][a-zA-Z0-9_]*)*$`)
 )
 
+// insertColumnList lists the columns written per message in argument order
+const insertColumnList = "event_id, event_topic, event_domain, event_type, object_type, producer, correlation_id, payload, metadata, created_at, deliver_at"
+
 type publisherConfig struct {
-	tableName  string
-	batchSize  int
-	maxRetries int
+	tableName    string
+	batchSize    int
+	maxRetries   int
+	schemas      *SchemaRegistry
+	dialect      Dialect
+	retry        RetryPolicy
+	strictTx     bool
+	transformers []PayloadTransformer
 }
 
 // PublisherOption defines a function that configures a Publisher
@@ -71,7 +107,7 @@ func WithTableName(tableName string) PublisherOption {
 	}
 }
 
-// WithMaxRetries sets the maximum number of retry attempts
+// WithMaxRetries sets the maximum number of retry attempts of the default retry policy
 func WithMaxRetries(retries int) PublisherOption {
 	return func(c *publisherConfig) error {
 		if retries < 0 {
@@ -82,6 +118,42 @@ func WithMaxRetries(retries int) PublisherOption {
 	}
 }
 
+// WithRetryPolicy sets the policy deciding whether and when a failed batch is written again.
+// It replaces the default policy, so WithMaxRetries has no effect.
+func WithRetryPolicy(policy RetryPolicy) PublisherOption {
+	return func(c *publisherConfig) error {
+		if policy == nil {
+			return fmt.Errorf("retry policy cannot be nil")
+		}
+		c.retry = policy
+		return nil
+	}
+}
+
+// WithSchemaRegistry validates message payloads against the registry before publishing
+// and records the schema version used in their metadata
+func WithSchemaRegistry(registry *SchemaRegistry) PublisherOption {
+	return func(c *publisherConfig) error {
+		if registry == nil {
+			return fmt.Errorf("schema registry cannot be nil")
+		}
+		c.schemas = registry
+		return nil
+	}
+}
+
+// WithDialect sets the SQL dialect of the outbox table.
+// Without it the dialect is detected from an executor implementing DriverNamer, or Postgres.
+func WithDialect(dialect Dialect) PublisherOption {
+	return func(c *publisherConfig) error {
+		if err := dialect.validate(); err != nil {
+			return err
+		}
+		c.dialect = dialect
+		return nil
+	}
+}
+
 // NewPublisher creates a new Publisher with the given executor and options
 func NewPublisher(executor Executor, opts ...PublisherOption) (Publisher, error) {
 	if executor == nil {
@@ -101,13 +173,30 @@ func NewPublisher(executor Executor, opts ...PublisherOption) (Publisher, error)
 		}
 	}
 
-	const query = "INSERT INTO %s (event_id, event_topic, event_domain, event_type, object_type, producer, correlation_id, payload, metadata, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)"
+	if config.dialect == "" {
+		dialect, err := detectDialect(executor)
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect dialect: %w", err)
+		}
+		config.dialect = dialect
+	}
+
+	if config.retry == nil {
+		config.retry = DefaultRetryPolicy(config.maxRetries)
+	}
+
+	if config.batchSize*orderedInsertParameters > maxQueryParameters {
+		return nil, fmt.Errorf("batch size must not exceed %d, got %d", maxQueryParameters/orderedInsertParameters, config.batchSize)
+	}
 
-	return &publisher{
+	p := &publisher{
 		executor: executor,
 		config:   config,
-		query:    fmt.Sprintf(query, config.tableName),
-	}, nil
+		table:    config.dialect.QuoteIdentifier(config.tableName),
+		sequence: sequenceUpsertQuery(config.dialect, config.dialect.QuoteIdentifier(sequenceTableName(config.tableName))),
+	}
+	p.query = p.insertQuery(config.batchSize)
+	return p, nil
 }
 
 func (p *publisher) Publish(ctx context.Context, messages ...*Message) error {
@@ -125,13 +214,72 @@ func (p *publisher) Publish(ctx context.Context, messages ...*Message) error {
 		}
 	}
 
+	if p.config.schemas != nil {
+		validated, err := p.validateSchemas(messages)
+		if err != nil {
+			return err
+		}
+		messages = validated
+	}
+
+	if len(p.config.transformers) > 0 {
+		transformed, err := transform(ctx, messages, p.config.transformers)
+		if err != nil {
+			return err
+		}
+		messages = transformed
+	}
+
 	return p.publishBatch(ctx, messages)
 }
 
+// validateSchemas validates the payloads against the schema registry and records the
+// schema version used. Messages are copied before their metadata changes, so the
+// messages of the caller are left as they are.
+func (p *publisher) validateSchemas(messages []*Message) ([]*Message, error) {
+	validated := make([]*Message, len(messages))
+	for i, msg := range messages {
+		version, err := p.config.schemas.resolve(msg)
+		if err != nil {
+			return nil, fmt.Errorf("message at index %d is invalid: %w", i, err)
+		}
+		if version == 0 {
+			validated[i] = msg
+			continue
+		}
+		if validated[i], err = withSchemaVersion(msg, version); err != nil {
+			return nil, fmt.Errorf("message at index %d is invalid: %w", i, err)
+		}
+	}
+	return validated, nil
+}
+
+// Cancel deletes unpublished messages. A message being dispatched by the relay is
+// locked until it is marked as published, so it is not cancelled.
+func (p *publisher) Cancel(ctx context.Context, eventIDs ...string) (int, error) {
+	const query = "DELETE FROM %s WHERE event_id IN (%s) AND published_at IS NULL"
+
+	var cancelled int64
+	for i := 0; i < len(eventIDs); i += p.config.batchSize {
+		ids := eventIDs[i:min(i+p.config.batchSize, len(eventIDs))]
+		result, err := p.executor.ExecContext(ctx, fmt.Sprintf(query, p.table, p.config.dialect.placeholders(1, len(ids))), stringArgs(ids)...)
+		if err != nil {
+			return int(cancelled), fmt.Errorf("failed to cancel messages: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return int(cancelled), fmt.Errorf("failed to cancel messages: %w", err)
+		}
+		cancelled += n
+	}
+	return int(cancelled), nil
+}
+
 func (p *publisher) Close() error {
 	return nil
 }
 
+// publishBatch writes the messages with one multi-row INSERT per batch
 func (p *publisher) publishBatch(ctx context.Context, messages []*Message) error {
 	batchSize := p.config.batchSize
 	for i := 0; i < len(messages); i += batchSize {
@@ -141,52 +289,187 @@ func (p *publisher) publishBatch(ctx context.Context, messages []*Message) error
 		}
 
 		batch := messages[i:end]
-		if err := p.publishBatchInternal(ctx, batch); err != nil {
-			return fmt.Errorf("failed to publish batch %d-%d: %w", i, end-1, err)
+		if err := p.publishWithRetry(ctx, batch); err != nil {
+			return &BatchPublishError{MessageIDs: messageIDs(batch), Published: i, Err: err}
 		}
 	}
 
 	return nil
 }
 
-func (p *publisher) publishBatchInternal(ctx context.Context, messages []*Message) error {
-	for _, msg := range messages {
-		if err := p.publishWithRetry(ctx, msg); err != nil {
-			return fmt.Errorf("failed to publish message %s: %w", msg.EventID, err)
+// publishWithRetry writes the batch, retrying as allowed by the retry policy.
+// A batch written through a transaction of the caller is never retried, as a failed
+// statement may have aborted it. Waiting between attempts stops when ctx is done.
+func (p *publisher) publishWithRetry(ctx context.Context, batch []*Message) error {
+	start := time.Now()
+	inTx := p.inTx(ctx)
+
+	for attempt := 1; ; attempt++ {
+		err := p.executePublish(ctx, batch)
+		if err == nil {
+			return nil
+		}
+
+		backoff, retry := p.config.retry.NextBackoff(attempt, time.Since(start), err)
+		if !retry || inTx {
+			return &PublishError{MessageID: batch[0].EventID, Err: err, Retries: attempt - 1, Attempts: attempt}
+		}
+
+		if waitErr := wait(ctx, backoff); waitErr != nil {
+			return &PublishError{MessageID: batch[0].EventID, Err: fmt.Errorf("%w (last error: %v)", waitErr, err), Retries: attempt - 1, Attempts: attempt}
 		}
 	}
-	return nil
 }
 
-func (p *publisher) publishWithRetry(ctx context.Context, msg *Message) error {
-	var lastErr error
+// inTx reports whether the publisher writes through a transaction, such as a *sql.Tx
+// given to NewPublisher or the transaction carried by the context of a NewTxPublisher
+func (p *publisher) inTx(ctx context.Context) bool {
+	executor := p.executor
+	if _, ok := executor.(*contextExecutor); ok {
+		tx, ok := TxFromContext(ctx)
+		if !ok {
+			return false
+		}
+		executor = tx
+	}
+	_, ok := executor.(interface {
+		Commit() error
+		Rollback() error
+	})
+	return ok
+}
 
-	for retry := 0; retry <= p.config.maxRetries; retry++ {
-		if err := p.executePublish(ctx, msg); err == nil {
-			return nil
-		} else {
-			lastErr = err
-			if retry < p.config.maxRetries {
-				// Exponential backoff with jitter
-				backoff := time.Duration(retry+1) * time.Second
-				time.Sleep(backoff)
-			}
+func (p *publisher) executePublish(ctx context.Context, batch []*Message) error {
+	keys, counts := aggregateCounts(batch)
+	if len(keys) > 0 {
+		if p.inTx(ctx) {
+			return p.executeOrderedPublish(ctx, p.executor, batch, keys, counts)
 		}
+
+		db, ok := p.txBeginner()
+		if !ok {
+			return fmt.Errorf("%w: messages with an aggregate key must be written in a transaction", ErrNoTransaction)
+		}
+		return RunInTx(ctx, db, func(ctx context.Context) error {
+			tx, _ := TxFromContext(ctx)
+			return p.executeOrderedPublish(ctx, tx, batch, keys, counts)
+		})
 	}
 
-	return fmt.Errorf("failed to publish message %s after %d retries: %w", msg.EventID, p.config.maxRetries, lastErr)
+	args := make([]interface{}, 0, len(batch)*insertColumns)
+	for _, msg := range batch {
+		args = p.appendInsertArgs(args, msg)
+	}
+
+	_, err := p.executor.ExecContext(ctx, p.insertQuery(len(batch)), args...)
+	return err
 }
 
-func (p *publisher) executePublish(ctx context.Context, msg *Message) error {
-	_, err := p.executor.ExecContext(ctx, p.query,
+// appendInsertArgs appends the values of the insertColumnList columns of the message
+func (p *publisher) appendInsertArgs(args []interface{}, msg *Message) []interface{} {
+	return append(args,
 		msg.EventID, msg.EventTopic, msg.EventDomain, msg.EventType, msg.ObjectType,
-		msg.Producer, msg.CorrelationID, msg.Payload, msg.Metadata, msg.CreatedAt)
+		msg.Producer, msg.CorrelationID, p.config.dialect.jsonValue(msg.Payload), p.config.dialect.jsonValue(msg.Metadata), msg.CreatedAt,
+		sql.NullTime{Time: msg.DeliverAt.UTC(), Valid: !msg.DeliverAt.IsZero()})
+}
 
-	if err != nil {
-		return err
+// insertQuery returns the INSERT statement for the given number of messages
+func (p *publisher) insertQuery(rows int) string {
+	if rows == p.config.batchSize && p.query != "" {
+		return p.query
 	}
 
-	return nil
+	const query = "INSERT INTO %s (" + insertColumnList + ") VALUES %s"
+
+	values := make([]string, rows)
+	for i := range values {
+		values[i] = "(" + p.config.dialect.placeholders(i*insertColumns+1, insertColumns) + ")"
+	}
+	return fmt.Sprintf(query, p.table, strings.Join(values, ", "))
+}
+
+// txBeginner returns the database the publisher writes to when it can begin a
+// transaction, such as *sql.DB or the fallback of a non-strict NewTxPublisher
+func (p *publisher) txBeginner() (TxBeginner, bool) {
+	executor := p.executor
+	if e, ok := executor.(*contextExecutor); ok {
+		if e.strict {
+			return nil, false
+		}
+		executor = e.fallback
+	}
+	db, ok := executor.(TxBeginner)
+	return db, ok
+}
+
+// executeOrderedPublish reserves the sequences of each aggregate key in the batch,
+// then writes the messages with their sequence read back from the sequence table.
+// Both statements must run in the same transaction: reserving locks the key until the
+// transaction ends, so concurrent publishers of a key never read the same last sequence
+// and commit in sequence order.
+func (p *publisher) executeOrderedPublish(ctx context.Context, executor Executor, batch []*Message, keys []string, counts map[string]int) error {
+	for _, key := range keys {
+		if _, err := executor.ExecContext(ctx, p.sequence, key, counts[key]); err != nil {
+			return fmt.Errorf("failed to reserve sequence of aggregate %s: %w", key, err)
+		}
+	}
+
+	const query = "INSERT INTO %s (" + insertColumnList + ", aggregate_key, aggregate_sequence) VALUES %s"
+	const sequence = "(SELECT last_sequence FROM %s WHERE aggregate_key = %s) - %s"
+
+	sequenceTable := p.config.dialect.QuoteIdentifier(sequenceTableName(p.config.tableName))
+	remaining := make(map[string]int, len(counts))
+	for key, n := range counts {
+		remaining[key] = n
+	}
+
+	values := make([]string, len(batch))
+	args := make([]interface{}, 0, len(batch)*orderedInsertParameters)
+	for i, msg := range batch {
+		args = p.appendInsertArgs(args, msg)
+		row := p.config.dialect.placeholders(len(args)-insertColumns+1, insertColumns)
+
+		if msg.AggregateKey == "" {
+			values[i] = "(" + row + ", NULL, NULL)"
+			continue
+		}
+
+		// The last message of a key takes the reserved last sequence, earlier ones count down from it
+		remaining[msg.AggregateKey]--
+		args = append(args, msg.AggregateKey, msg.AggregateKey, remaining[msg.AggregateKey])
+		n := len(args)
+		values[i] = fmt.Sprintf("(%s, %s, %s)", row, p.config.dialect.placeholder(n-2),
+			fmt.Sprintf(sequence, sequenceTable, p.config.dialect.placeholder(n-1), p.config.dialect.placeholder(n)))
+	}
+
+	_, err := executor.ExecContext(ctx, fmt.Sprintf(query, p.table, strings.Join(values, ", ")), args...)
+	return err
+}
+
+// aggregateCounts returns the sorted aggregate keys of the messages and the number of
+// messages of each key. Sorting makes concurrent publishers lock keys in the same order.
+func aggregateCounts(messages []*Message) ([]string, map[string]int) {
+	var keys []string
+	counts := make(map[string]int)
+	for _, msg := range messages {
+		if msg.AggregateKey == "" {
+			continue
+		}
+		if counts[msg.AggregateKey] == 0 {
+			keys = append(keys, msg.AggregateKey)
+		}
+		counts[msg.AggregateKey]++
+	}
+	sort.Strings(keys)
+	return keys, counts
+}
+
+func messageIDs(messages []*Message) []string {
+	ids := make([]string, len(messages))
+	for i, msg := range messages {
+		ids[i] = msg.EventID
+	}
+	return ids
 }
 
 // GetTableName returns the current table name (for testing purposes)
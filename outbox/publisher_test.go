@@ -255,7 +255,7 @@ func TestPublisher_Publish_Batch(t *testing.T) {
 		},
 	}
 
-	executor.On("ExecContext", mock.Anything, mock.Anything, mock.Anything).Return(&MockResult{}, nil).Times(3)
+	executor.On("ExecContext", mock.Anything, mock.Anything, mock.Anything).Return(&MockResult{}, nil).Times(2)
 
 	// When
 	err = publisher.Publish(context.Background(), messages...)
@@ -276,7 +276,7 @@ func TestPublisherWithParameterizedQuery(t *testing.T) {
 		},
 	}
 
-	pub, err := NewPublisher(mockDB)
+	pub, err := NewPublisher(mockDB, WithDialect(DialectMySQL))
 	require.NoError(t, err)
 	defer pub.Close()
 
@@ -399,3 +399,125 @@ func TestPublisherClose(t *testing.T) {
 	err = pub.Close()
 	require.NoError(t, err)
 }
+
+func TestPublisher_Publish_MultiRowInsert(t *testing.T) {
+	// Given
+	executor := &namedExecutor{driver: "postgres"}
+	publisher, err := NewPublisher(executor, WithBatchSize(2))
+	require.NoError(t, err)
+
+	messages := make([]*Message, 3)
+	for i := range messages {
+		messages[i] = &Message{
+			EventID:     fmt.Sprintf("test-event-%d", i+1),
+			EventTopic:  "test-topic",
+			EventDomain: "test-domain",
+			EventType:   "test-event",
+			ObjectType:  "test-object",
+			CreatedAt:   time.Now(),
+		}
+	}
+
+	// When
+	err = publisher.Publish(context.Background(), messages...)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, executor.queries, 2)
+	assert.True(t, strings.HasSuffix(executor.queries[0], "VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11), ($12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)"))
+	assert.Len(t, executor.args[0], 22)
+	assert.Equal(t, "test-event-2", executor.args[0][11])
+	assert.True(t, strings.HasSuffix(executor.queries[1], "VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)"))
+	assert.Len(t, executor.args[1], 11)
+}
+
+func TestPublisher_Publish_BatchError(t *testing.T) {
+	// Given
+	calls := 0
+	executor := &MockExecutor{
+		execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+			calls++
+			if calls == 2 {
+				return nil, errors.New("duplicate key")
+			}
+			return &MockResult{}, nil
+		},
+	}
+	publisher, err := NewPublisher(executor, WithBatchSize(2), WithMaxRetries(0))
+	require.NoError(t, err)
+
+	messages := make([]*Message, 4)
+	for i := range messages {
+		messages[i] = &Message{
+			EventID:     fmt.Sprintf("test-event-%d", i+1),
+			EventTopic:  "test-topic",
+			EventDomain: "test-domain",
+			EventType:   "test-event",
+			ObjectType:  "test-object",
+			CreatedAt:   time.Now(),
+		}
+	}
+
+	// When
+	err = publisher.Publish(context.Background(), messages...)
+
+	// Then
+	var batchErr *BatchPublishError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Equal(t, []string{"test-event-3", "test-event-4"}, batchErr.MessageIDs)
+	assert.Equal(t, 2, batchErr.Published)
+	assert.ErrorContains(t, err, "duplicate key")
+}
+
+func TestNewPublisher_BatchSizeLimit(t *testing.T) {
+	_, err := NewPublisher(&MockExecutor{}, WithBatchSize(maxQueryParameters/orderedInsertParameters+1))
+	assert.Error(t, err)
+}
+
+func TestPublisher_Publish_Scheduled(t *testing.T) {
+	// Given
+	executor := &namedExecutor{driver: "postgres"}
+	publisher, err := NewPublisher(executor)
+	require.NoError(t, err)
+
+	deliverAt := time.Now().Add(7 * 24 * time.Hour)
+	msg, err := NewMessageBuilder().
+		SetEventTopic("invitations").
+		SetEventDomain("iam").
+		SetEventType("invitation.expired").
+		SetObjectType("invitation").
+		SetDeliverAt(deliverAt).
+		Build()
+	require.NoError(t, err)
+
+	// When
+	err = publisher.Publish(context.Background(), msg)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, executor.args, 1)
+	assert.Equal(t, sql.NullTime{Time: deliverAt.UTC(), Valid: true}, executor.args[0][10])
+}
+
+func TestPublisher_Cancel(t *testing.T) {
+	// Given
+	var query string
+	var args []interface{}
+	executor := &MockExecutor{execFunc: func(_ context.Context, q string, a ...interface{}) (sql.Result, error) {
+		query, args = q, a
+		return &MockResult{}, nil
+	}}
+	publisher, err := NewPublisher(executor)
+	require.NoError(t, err)
+	canceler, ok := publisher.(Canceler)
+	require.True(t, ok)
+
+	// When
+	cancelled, err := canceler.Cancel(context.Background(), "id-1", "id-2")
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, 1, cancelled)
+	assert.Equal(t, `DELETE FROM "outbox_message" WHERE event_id IN ($1, $2) AND published_at IS NULL`, query)
+	assert.Equal(t, []interface{}{"id-1", "id-2"}, args)
+}
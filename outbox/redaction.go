@@ -0,0 +1,140 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RedactedValue replaces the values redacted by a PayloadRedactor
+const RedactedValue = "[REDACTED]"
+
+// pathWildcard matches every field of an object or element of an array
+const pathWildcard = "*"
+
+// PayloadRedactor replaces the fields of payloads matching JSON paths with RedactedValue,
+// such as to keep personal data out of the outbox. Redaction cannot be reverted.
+type PayloadRedactor struct {
+	paths [][]string
+}
+
+var _ PayloadTransformer = (*PayloadRedactor)(nil)
+
+// NewPayloadRedactor creates a new PayloadRedactor for the JSON paths.
+// Paths select object fields and array elements, such as "$.customer.email",
+// "items[*].card.number" or "contacts[0]"; "*" matches every field or element.
+func NewPayloadRedactor(paths ...string) (*PayloadRedactor, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("at least one path is required")
+	}
+
+	r := &PayloadRedactor{paths: make([][]string, 0, len(paths))}
+	for _, path := range paths {
+		segments, err := parsePath(path)
+		if err != nil {
+			return nil, err
+		}
+		r.paths = append(r.paths, segments)
+	}
+	return r, nil
+}
+
+// Transform redacts the payload of the message; paths not found in the payload are ignored
+func (r *PayloadRedactor) Transform(_ context.Context, msg *Message) error {
+	if len(msg.Payload) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(msg.Payload))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return &MarshalError{Field: "payload", Err: err}
+	}
+
+	redacted := false
+	for _, path := range r.paths {
+		var ok bool
+		if document, ok = redact(document, path); ok {
+			redacted = true
+		}
+	}
+	if !redacted {
+		return nil
+	}
+
+	payload, err := json.Marshal(document)
+	if err != nil {
+		return &MarshalError{Field: "payload", Err: err}
+	}
+	msg.Payload = payload
+	return nil
+}
+
+// redact replaces the values of node matching path, reporting whether any value matched
+func redact(node any, path []string) (any, bool) {
+	if len(path) == 0 {
+		return RedactedValue, true
+	}
+
+	segment, rest := path[0], path[1:]
+	redacted := false
+	switch value := node.(type) {
+	case map[string]any:
+		for key, child := range value {
+			if segment != pathWildcard && segment != key {
+				continue
+			}
+			var ok bool
+			if value[key], ok = redact(child, rest); ok {
+				redacted = true
+			}
+		}
+	case []any:
+		for i, child := range value {
+			if segment != pathWildcard && segment != strconv.Itoa(i) {
+				continue
+			}
+			var ok bool
+			if value[i], ok = redact(child, rest); ok {
+				redacted = true
+			}
+		}
+	}
+	return node, redacted
+}
+
+// parsePath splits a JSON path into field names and array indexes
+func parsePath(path string) ([]string, error) {
+	trimmed := strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if trimmed == "" {
+		return nil, fmt.Errorf("invalid path %q: path is empty", path)
+	}
+
+	var segments []string
+	for _, part := range strings.Split(trimmed, ".") {
+		name, indexes, _ := strings.Cut(part, "[")
+		if name != "" {
+			segments = append(segments, name)
+		}
+		if indexes != "" {
+			for _, index := range strings.Split(strings.TrimSuffix(indexes, "]"), "][") {
+				if index != pathWildcard {
+					if n, err := strconv.Atoi(index); err != nil || n < 0 {
+						return nil, fmt.Errorf("invalid path %q: invalid index %q", path, index)
+					}
+				}
+				segments = append(segments, index)
+			}
+		} else if name == "" {
+			return nil, fmt.Errorf("invalid path %q: empty segment", path)
+		}
+		if indexes != "" && !strings.HasSuffix(indexes, "]") {
+			return nil, fmt.Errorf("invalid path %q: unterminated index", path)
+		}
+	}
+	return segments, nil
+}
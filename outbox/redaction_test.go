@@ -0,0 +1,66 @@
+package outbox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadRedactor(t *testing.T) {
+	tests := []struct {
+		name     string
+		paths    []string
+		payload  string
+		expected string
+	}{
+		{
+			name:     "nested field",
+			paths:    []string{"$.customer.email"},
+			payload:  `{"customer":{"email":"jane@example.com","name":"Jane"}}`,
+			expected: `{"customer":{"email":"[REDACTED]","name":"Jane"}}`,
+		},
+		{
+			name:     "array wildcard",
+			paths:    []string{"cards[*].number"},
+			payload:  `{"cards":[{"number":"4111","brand":"visa"},{"number":"5500"}]}`,
+			expected: `{"cards":[{"number":"[REDACTED]","brand":"visa"},{"number":"[REDACTED]"}]}`,
+		},
+		{
+			name:     "array index and field wildcard",
+			paths:    []string{"phones[1]", "address.*"},
+			payload:  `{"phones":["1","2"],"address":{"city":"Seoul","zip":"04524"}}`,
+			expected: `{"phones":["1","[REDACTED]"],"address":{"city":"[REDACTED]","zip":"[REDACTED]"}}`,
+		},
+		{
+			name:     "missing path",
+			paths:    []string{"customer.ssn"},
+			payload:  `{"customer":{"name":"Jane"},"amount":12345678901234567890}`,
+			expected: `{"customer":{"name":"Jane"},"amount":12345678901234567890}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			redactor, err := NewPayloadRedactor(tt.paths...)
+			require.NoError(t, err)
+			msg := newTransformTestMessage(t, tt.payload)
+
+			// When
+			err = redactor.Transform(context.Background(), msg)
+
+			// Then
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(msg.Payload))
+		})
+	}
+}
+
+func TestNewPayloadRedactor_InvalidPath(t *testing.T) {
+	for _, path := range []string{"", "$", "a..b", "items[x]", "items[-1]", "items[0"} {
+		_, err := NewPayloadRedactor(path)
+		assert.Error(t, err, path)
+	}
+}
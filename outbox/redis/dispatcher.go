@@ -0,0 +1,176 @@
+// Package redis dispatches outbox messages to Redis Streams.
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/carped99/gosdk/outbox"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Stream entry fields set on every dispatched message
+const (
+	FieldEventID       = outbox.AttributeEventID
+	FieldEventTopic    = "event_topic"
+	FieldEventDomain   = outbox.AttributeEventDomain
+	FieldEventType     = outbox.AttributeEventType
+	FieldObjectType    = outbox.AttributeObjectType
+	FieldProducer      = outbox.AttributeProducer
+	FieldCorrelationID = outbox.AttributeCorrelationID
+	FieldPayload       = "payload"
+	FieldMetadata      = outbox.AttributeMetadata
+	FieldCreatedAt     = outbox.AttributeCreatedAt
+	FieldAggregateKey  = outbox.AttributeAggregateKey
+	FieldSequence      = outbox.AttributeSequence
+)
+
+// Client is the part of goredis.UniversalClient used by the Dispatcher
+type Client interface {
+	XAdd(ctx context.Context, a *goredis.XAddArgs) *goredis.StringCmd
+}
+
+type config struct {
+	stream func(msg *outbox.Message) string
+	maxLen int64
+	approx bool
+}
+
+// Option configures a Dispatcher
+type Option func(*config) error
+
+// WithStreamFunc sets the stream of dispatched messages, EventTopic by default
+func WithStreamFunc(fn func(msg *outbox.Message) string) Option {
+	return func(c *config) error {
+		if fn == nil {
+			return fmt.Errorf("stream func cannot be nil")
+		}
+		c.stream = fn
+		return nil
+	}
+}
+
+// WithStreamPrefix prefixes the EventTopic with prefix and a colon, such as "events:orders"
+func WithStreamPrefix(prefix string) Option {
+	return func(c *config) error {
+		if prefix == "" {
+			return fmt.Errorf("stream prefix cannot be empty")
+		}
+		c.stream = func(msg *outbox.Message) string {
+			return prefix + ":" + msg.EventTopic
+		}
+		return nil
+	}
+}
+
+// WithMaxLen trims streams to about maxLen entries on every add.
+// Trimming is approximate unless exact is true, which is slower.
+func WithMaxLen(maxLen int64, exact bool) Option {
+	return func(c *config) error {
+		if maxLen <= 0 {
+			return fmt.Errorf("max length must be positive, got %d", maxLen)
+		}
+		c.maxLen = maxLen
+		c.approx = !exact
+		return nil
+	}
+}
+
+// Dispatcher appends outbox messages to Redis Streams.
+// It implements outbox.Dispatcher.
+//
+// Redis Streams do not deduplicate entries, so a message relayed again after a failed
+// acknowledgement is appended twice; consumers deduplicate by the event_id field.
+type Dispatcher struct {
+	client Client
+	config config
+}
+
+var _ outbox.Dispatcher = (*Dispatcher)(nil)
+
+// NewDispatcher creates a new Dispatcher adding entries with client, usually a *goredis.Client
+func NewDispatcher(client Client, opts ...Option) (*Dispatcher, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client cannot be nil")
+	}
+
+	c := config{
+		stream: func(msg *outbox.Message) string {
+			return msg.EventTopic
+		},
+	}
+	for _, opt := range opts {
+		if err := opt(&c); err != nil {
+			return nil, fmt.Errorf("failed to apply redis option: %w", err)
+		}
+	}
+
+	return &Dispatcher{client: client, config: c}, nil
+}
+
+// Dispatch appends the message to its stream
+func (d *Dispatcher) Dispatch(ctx context.Context, msg *outbox.Message) error {
+	args, err := d.XAddArgs(msg)
+	if err != nil {
+		return err
+	}
+
+	if err := d.client.XAdd(ctx, args).Err(); err != nil {
+		return fmt.Errorf("failed to add message %s to %s: %w", msg.EventID, args.Stream, err)
+	}
+	return nil
+}
+
+// XAddArgs converts the message to the arguments of an XADD command
+func (d *Dispatcher) XAddArgs(msg *outbox.Message) (*goredis.XAddArgs, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("message cannot be nil")
+	}
+
+	stream := d.config.stream(msg)
+	if stream == "" {
+		return nil, outbox.ErrInvalidEventTopic
+	}
+
+	attributes, err := outbox.MessageAttributes(msg)
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]any, 0, 2*len(attributes)+4)
+	fields = append(fields, FieldEventTopic, msg.EventTopic)
+	if len(msg.Payload) > 0 {
+		fields = append(fields, FieldPayload, string(msg.Payload))
+	}
+	for _, a := range attributes {
+		fields = append(fields, a.Key, a.Value)
+	}
+
+	return &goredis.XAddArgs{
+		Stream: stream,
+		MaxLen: d.config.maxLen,
+		Approx: d.config.approx,
+		Values: fields,
+	}, nil
+}
+
+// MessageFromEntry converts a stream entry added by a Dispatcher back to an outbox message
+func MessageFromEntry(entry goredis.XMessage) (*outbox.Message, error) {
+	field := func(key string) string {
+		s, _ := entry.Values[key].(string)
+		return s
+	}
+
+	msg, err := outbox.MessageFromAttributes(field)
+	if err != nil {
+		return nil, err
+	}
+	msg.EventTopic = field(FieldEventTopic)
+	if v := field(FieldPayload); v != "" {
+		msg.Payload = []byte(v)
+	}
+
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
@@ -0,0 +1,134 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/carped99/gosdk/outbox"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T) (*miniredis.Miniredis, *goredis.Client) {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return srv, client
+}
+
+func newTestMessage(t *testing.T) *outbox.Message {
+	msg, err := outbox.NewMessageBuilder().
+		SetEventTopic("orders").
+		SetEventDomain("shop").
+		SetEventType("order.created").
+		SetObjectType("order").
+		SetProducer("order-service").
+		SetCorrelationID("corr-1").
+		SetPayload(json.RawMessage(`{"order_id":"o-1"}`)).
+		Build()
+	require.NoError(t, err)
+	return msg
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	t.Run("adds to the event topic stream", func(t *testing.T) {
+		// Given
+		_, client := newTestClient(t)
+		dispatcher, err := NewDispatcher(client)
+		require.NoError(t, err)
+		msg := newTestMessage(t)
+
+		// When
+		err = dispatcher.Dispatch(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		entries, err := client.XRange(context.Background(), "orders", "-", "+").Result()
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, msg.EventID, entries[0].Values[FieldEventID])
+		assert.Equal(t, "order.created", entries[0].Values[FieldEventType])
+		assert.JSONEq(t, `{"order_id":"o-1"}`, entries[0].Values[FieldPayload].(string))
+		assert.NotContains(t, entries[0].Values, FieldMetadata)
+	})
+
+	t.Run("trims the stream", func(t *testing.T) {
+		// Given
+		_, client := newTestClient(t)
+		dispatcher, err := NewDispatcher(client, WithStreamPrefix("events"), WithMaxLen(2, true))
+		require.NoError(t, err)
+
+		// When
+		for i := 0; i < 5; i++ {
+			require.NoError(t, dispatcher.Dispatch(context.Background(), newTestMessage(t)))
+		}
+
+		// Then
+		length, err := client.XLen(context.Background(), "events:orders").Result()
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), length)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		// Given
+		srv, client := newTestClient(t)
+		srv.SetError("LOADING Redis is loading the dataset in memory")
+		dispatcher, err := NewDispatcher(client)
+		require.NoError(t, err)
+
+		// When
+		err = dispatcher.Dispatch(context.Background(), newTestMessage(t))
+
+		// Then
+		assert.ErrorContains(t, err, "LOADING")
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := NewDispatcher(nil)
+		assert.Error(t, err)
+
+		_, client := newTestClient(t)
+		_, err = NewDispatcher(client, WithMaxLen(0, false))
+		assert.Error(t, err)
+	})
+}
+
+func TestMessageFromEntry(t *testing.T) {
+	// Given
+	_, client := newTestClient(t)
+	dispatcher, err := NewDispatcher(client)
+	require.NoError(t, err)
+	msg := newTestMessage(t)
+	msg.Metadata = json.RawMessage(`{"trace_id":"t-1"}`)
+	msg.AggregateKey = "order-1"
+	msg.Sequence = 3
+	require.NoError(t, dispatcher.Dispatch(context.Background(), msg))
+
+	entries, err := client.XRange(context.Background(), "orders", "-", "+").Result()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// When
+	decoded, err := MessageFromEntry(entries[0])
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, msg.EventID, decoded.EventID)
+	assert.Equal(t, msg.EventTopic, decoded.EventTopic)
+	assert.Equal(t, msg.EventDomain, decoded.EventDomain)
+	assert.Equal(t, msg.EventType, decoded.EventType)
+	assert.Equal(t, msg.ObjectType, decoded.ObjectType)
+	assert.Equal(t, msg.Producer, decoded.Producer)
+	assert.Equal(t, msg.CorrelationID, decoded.CorrelationID)
+	assert.JSONEq(t, string(msg.Payload), string(decoded.Payload))
+	assert.JSONEq(t, string(msg.Metadata), string(decoded.Metadata))
+	assert.Equal(t, "order-1", decoded.AggregateKey)
+	assert.Equal(t, int64(3), decoded.Sequence)
+	assert.WithinDuration(t, msg.CreatedAt, decoded.CreatedAt, time.Microsecond)
+}
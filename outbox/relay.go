@@ -0,0 +1,453 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	defaultRelayBatchSize    = 100
+	defaultRelayConcurrency  = 1
+	defaultRelayPollInterval = time.Second
+	defaultRelayMaxAttempts  = 10
+)
+
+// Dispatcher delivers relayed messages to a message broker.
+// A message is marked as published only after Dispatch returns nil.
+type Dispatcher interface {
+	Dispatch(ctx context.Context, msg *Message) error
+}
+
+// DispatcherFunc adapts a function to the Dispatcher interface
+type DispatcherFunc func(ctx context.Context, msg *Message) error
+
+func (f DispatcherFunc) Dispatch(ctx context.Context, msg *Message) error {
+	return f(ctx, msg)
+}
+
+// TxBeginner starts database transactions, such as *sql.DB
+type TxBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+type relayConfig struct {
+	tableName     string
+	batchSize     int
+	concurrency   int
+	pollInterval  time.Duration
+	deleteOnRelay bool
+	onError       func(error)
+	dialect       Dialect
+	retry         RetryPolicy
+}
+
+// RelayOption defines a function that configures a Relay
+type RelayOption func(*relayConfig) error
+
+// WithRelayTableName sets the table the relay reads messages from
+func WithRelayTableName(tableName string) RelayOption {
+	return func(c *relayConfig) error {
+		if tableName == "" {
+			return fmt.Errorf("table name cannot be empty")
+		}
+
+		sanitizedTableName, err := sanitizeTableName(tableName)
+		if err != nil {
+			return fmt.Errorf("invalid table name: %w", err)
+		}
+
+		c.tableName = sanitizedTableName
+		return nil
+	}
+}
+
+// WithRelayBatchSize sets the maximum number of messages claimed per transaction
+func WithRelayBatchSize(size int) RelayOption {
+	return func(c *relayConfig) error {
+		if size <= 0 {
+			return fmt.Errorf("batch size must be positive, got %d", size)
+		}
+		c.batchSize = size
+		return nil
+	}
+}
+
+// WithRelayConcurrency sets the number of workers polling the table.
+// Workers claim disjoint batches, so ordering is only guaranteed with a single worker,
+// or between messages sharing an aggregate key.
+func WithRelayConcurrency(workers int) RelayOption {
+	return func(c *relayConfig) error {
+		if workers <= 0 {
+			return fmt.Errorf("concurrency must be positive, got %d", workers)
+		}
+		c.concurrency = workers
+		return nil
+	}
+}
+
+// WithPollInterval sets how long a worker waits after finding fewer messages than the batch size
+func WithPollInterval(interval time.Duration) RelayOption {
+	return func(c *relayConfig) error {
+		if interval <= 0 {
+			return fmt.Errorf("poll interval must be positive, got %s", interval)
+		}
+		c.pollInterval = interval
+		return nil
+	}
+}
+
+// WithDeleteOnRelay deletes dispatched messages instead of setting published_at
+func WithDeleteOnRelay() RelayOption {
+	return func(c *relayConfig) error {
+		c.deleteOnRelay = true
+		return nil
+	}
+}
+
+// WithRelayDialect sets the SQL dialect of the outbox table.
+// Without it the dialect is detected from a db implementing DriverNamer, or Postgres.
+// Timestamps are read whether the driver returns them as time.Time or text, so a MySQL
+// DSN does not need parseTime=true.
+func WithRelayDialect(dialect Dialect) RelayOption {
+	return func(c *relayConfig) error {
+		if err := dialect.validate(); err != nil {
+			return err
+		}
+		c.dialect = dialect
+		return nil
+	}
+}
+
+// WithRelayMaxAttempts sets the number of failed dispatches after which a message
+// is moved to the dead-letter table, 10 by default
+func WithRelayMaxAttempts(attempts int) RelayOption {
+	return func(c *relayConfig) error {
+		if attempts <= 0 {
+			return fmt.Errorf("max attempts must be positive, got %d", attempts)
+		}
+		c.retry = defaultRelayRetryPolicy(attempts)
+		return nil
+	}
+}
+
+// WithRelayRetryPolicy sets when a message is dispatched again after a failure.
+// A message the policy gives up on, or failing with a Permanent error, is moved to the dead-letter table.
+func WithRelayRetryPolicy(policy RetryPolicy) RelayOption {
+	return func(c *relayConfig) error {
+		if policy == nil {
+			return fmt.Errorf("retry policy cannot be nil")
+		}
+		c.retry = policy
+		return nil
+	}
+}
+
+// defaultRelayRetryPolicy backs off from one second up to five minutes between dispatches
+func defaultRelayRetryPolicy(maxAttempts int) *ExponentialBackoff {
+	return &ExponentialBackoff{
+		InitialInterval: time.Second,
+		MaxInterval:     5 * time.Minute,
+		Multiplier:      defaultRetryMultiplier,
+		Jitter:          defaultRetryJitter,
+		MaxAttempts:     maxAttempts,
+	}
+}
+
+// WithRelayErrorHandler sets a function called with errors that do not stop the relay
+func WithRelayErrorHandler(handler func(error)) RelayOption {
+	return func(c *relayConfig) error {
+		c.onError = handler
+		return nil
+	}
+}
+
+// Relay polls the outbox table for unpublished messages and hands them to a Dispatcher.
+// On Postgres and MySQL rows are claimed with SELECT ... FOR UPDATE SKIP LOCKED, so several
+// relays can share a table. SQLite has no row locks and supports a single worker only.
+//
+// Messages with an aggregate key are dispatched one at a time in sequence order:
+// a message is only claimed once every earlier message of its key was relayed.
+// Different keys are relayed concurrently. A message moved to the dead-letter table
+// no longer holds back its key.
+//
+// A message scheduled with MessageBuilder.SetDeliverAt is claimed once it is due.
+// A scheduled message with an aggregate key holds back the later messages of its key.
+//
+// A failed message is dispatched again after the backoff of the retry policy, and
+// moved to the dead-letter table once the policy gives up (see DeadLetterStore).
+type Relay struct {
+	db         TxBeginner
+	dispatcher Dispatcher
+	config     relayConfig
+
+	selectQuery     string
+	markQuery       string
+	deleteQuery     string
+	failQuery       string
+	quarantineQuery string
+}
+
+// NewRelay creates a new Relay reading from db and dispatching to dispatcher
+func NewRelay(db TxBeginner, dispatcher Dispatcher, opts ...RelayOption) (*Relay, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db cannot be nil")
+	}
+	if dispatcher == nil {
+		return nil, fmt.Errorf("dispatcher cannot be nil")
+	}
+
+	config := relayConfig{
+		tableName:    defaultTableName,
+		batchSize:    defaultRelayBatchSize,
+		concurrency:  defaultRelayConcurrency,
+		pollInterval: defaultRelayPollInterval,
+		retry:        defaultRelayRetryPolicy(defaultRelayMaxAttempts),
+	}
+
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return nil, fmt.Errorf("failed to apply relay option: %w", err)
+		}
+	}
+
+	if config.dialect == "" {
+		dialect, err := detectDialect(db)
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect dialect: %w", err)
+		}
+		config.dialect = dialect
+	}
+	if config.dialect == DialectSQLite && config.concurrency > 1 {
+		return nil, fmt.Errorf("sqlite relay supports a single worker, got concurrency %d", config.concurrency)
+	}
+
+	// Only the oldest unpublished message of an aggregate key is claimed, so a key is
+	// never dispatched in parallel or ahead of a message waiting for a retry or its schedule
+	const selectQuery = "SELECT o.event_id, o.event_topic, o.event_domain, o.event_type, o.object_type, o.producer, o.correlation_id, o.payload, o.metadata, o.created_at, o.attempts, o.aggregate_key, o.aggregate_sequence" +
+		" FROM %s o WHERE o.published_at IS NULL AND (o.next_attempt_at IS NULL OR o.next_attempt_at <= %s) AND (o.deliver_at IS NULL OR o.deliver_at <= %s)" +
+		" AND (o.aggregate_key IS NULL OR NOT EXISTS (SELECT 1 FROM %s p WHERE p.aggregate_key = o.aggregate_key AND p.published_at IS NULL AND p.aggregate_sequence < o.aggregate_sequence))" +
+		" ORDER BY o.created_at, o.event_id LIMIT %d%s"
+	const markQuery = "UPDATE %s SET status = '" + MessageStatusPublished + "', published_at = %s WHERE event_id IN (%%s)"
+	const deleteQuery = "DELETE FROM %s WHERE event_id IN (%%s)"
+	const failQuery = "UPDATE %s SET attempts = %s, last_error = %s, next_attempt_at = %s WHERE event_id = %s"
+
+	table := config.dialect.QuoteIdentifier(config.tableName)
+	p := config.dialect.placeholder
+	return &Relay{
+		db:              db,
+		dispatcher:      dispatcher,
+		config:          config,
+		selectQuery:     fmt.Sprintf(selectQuery, table, p(1), p(2), table, config.batchSize, config.dialect.lockClause()),
+		markQuery:       fmt.Sprintf(markQuery, table, p(1)),
+		deleteQuery:     fmt.Sprintf(deleteQuery, table),
+		failQuery:       fmt.Sprintf(failQuery, table, p(1), p(2), p(3), p(4)),
+		quarantineQuery: deadLetterInsertQuery(config.dialect, config.dialect.QuoteIdentifier(deadLetterTableName(config.tableName))),
+	}, nil
+}
+
+// Run starts the configured number of workers and blocks until ctx is done.
+// Errors are reported to the error handler and retried after the poll interval.
+func (r *Relay) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for i := 0; i < r.config.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.work(ctx)
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+func (r *Relay) work(ctx context.Context) {
+	for {
+		relayed, err := r.RelayBatch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil && r.config.onError != nil {
+			r.config.onError(err)
+		}
+
+		// A full batch suggests more messages are waiting, and relaying the head of an
+		// aggregate key makes its next message due
+		if err == nil && relayed > 0 {
+			continue
+		}
+
+		// A dispatcher may ask to back off longer, such as a webhook answering with Retry-After
+		delay := r.config.pollInterval
+		if after, ok := RetryAfter(err); ok && after > delay {
+			delay = after
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// RelayBatch claims one batch of due messages, dispatches them in order and marks
+// the dispatched ones as published. Dispatching stops at the first failure, which is
+// recorded on the message; a message moved to the dead-letter table does not stop it,
+// nor does a message with an aggregate key, as it only holds back its own key.
+// It returns the number of messages relayed.
+func (r *Relay) RelayBatch(ctx context.Context) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	messages, err := r.claim(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+
+	dispatched := make([]string, 0, len(messages))
+	var dispatchErr error
+	for _, claimed := range messages {
+		msg := claimed.message
+		err := r.dispatcher.Dispatch(ctx, msg)
+		if err == nil {
+			dispatched = append(dispatched, msg.EventID)
+			continue
+		}
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+
+		attempts := claimed.attempts + 1
+		quarantined, failErr := r.fail(ctx, tx, msg, attempts, err)
+		if failErr != nil {
+			return 0, failErr
+		}
+		if quarantined {
+			err = fmt.Errorf("%w: %w", ErrDeadLettered, err)
+		}
+		if !quarantined && msg.AggregateKey == "" {
+			dispatchErr = &PublishError{MessageID: msg.EventID, Err: err, Attempts: attempts}
+			break
+		}
+		if r.config.onError != nil {
+			r.config.onError(&PublishError{MessageID: msg.EventID, Err: err, Attempts: attempts})
+		}
+	}
+
+	if err := r.complete(ctx, tx, dispatched); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(dispatched), dispatchErr
+}
+
+// claimedMessage is a message read by the relay with its failed dispatches
+type claimedMessage struct {
+	message  *Message
+	attempts int
+}
+
+// claim locks and reads the next batch of due unpublished messages
+func (r *Relay) claim(ctx context.Context, tx *sql.Tx) ([]claimedMessage, error) {
+	now := time.Now().UTC()
+	rows, err := tx.QueryContext(ctx, r.selectQuery, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []claimedMessage
+	for rows.Next() {
+		var (
+			msg                     Message
+			attempts                int
+			producer, correlationID sql.NullString
+			payload, metadata       []byte
+			aggregateKey            sql.NullString
+			sequence                sql.NullInt64
+		)
+		if err := rows.Scan(&msg.EventID, &msg.EventTopic, &msg.EventDomain, &msg.EventType, &msg.ObjectType,
+			&producer, &correlationID, &payload, &metadata, scanTime{&msg.CreatedAt}, &attempts, &aggregateKey, &sequence); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+
+		msg.Producer = producer.String
+		msg.CorrelationID = correlationID.String
+		msg.AggregateKey = aggregateKey.String
+		msg.Sequence = sequence.Int64
+		if len(payload) > 0 {
+			msg.Payload = json.RawMessage(payload)
+		}
+		if len(metadata) > 0 {
+			msg.Metadata = json.RawMessage(metadata)
+		}
+		messages = append(messages, claimedMessage{message: &msg, attempts: attempts})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read messages: %w", err)
+	}
+	return messages, nil
+}
+
+// complete marks or deletes the dispatched messages
+func (r *Relay) complete(ctx context.Context, tx *sql.Tx, eventIDs []string) error {
+	if len(eventIDs) == 0 {
+		return nil
+	}
+
+	if r.config.deleteOnRelay {
+		args := make([]any, 0, len(eventIDs))
+		for _, id := range eventIDs {
+			args = append(args, id)
+		}
+		query := fmt.Sprintf(r.deleteQuery, r.config.dialect.placeholders(1, len(eventIDs)))
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to delete relayed messages: %w", err)
+		}
+		return nil
+	}
+
+	args := make([]any, 0, len(eventIDs)+1)
+	args = append(args, time.Now().UTC())
+	for _, id := range eventIDs {
+		args = append(args, id)
+	}
+	query := fmt.Sprintf(r.markQuery, r.config.dialect.placeholders(2, len(eventIDs)))
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to mark relayed messages: %w", err)
+	}
+	return nil
+}
+
+// fail records a failed dispatch, or moves the message to the dead-letter table when
+// the retry policy gives up on it. It reports whether the message was moved.
+func (r *Relay) fail(ctx context.Context, tx *sql.Tx, msg *Message, attempts int, cause error) (bool, error) {
+	now := time.Now().UTC()
+	lastError := errorText(cause)
+
+	if backoff, retry := r.config.retry.NextBackoff(attempts, now.Sub(msg.CreatedAt), cause); retry {
+		if _, err := tx.ExecContext(ctx, r.failQuery, attempts, lastError, now.Add(backoff), msg.EventID); err != nil {
+			return false, fmt.Errorf("failed to record failed dispatch of message %s: %w", msg.EventID, err)
+		}
+		return false, nil
+	}
+
+	dl := &DeadLetter{Message: msg, Attempts: attempts, LastError: lastError, FailedAt: now}
+	if _, err := tx.ExecContext(ctx, r.quarantineQuery, deadLetterArgs(r.config.dialect, dl)...); err != nil {
+		return false, fmt.Errorf("failed to quarantine message %s: %w", msg.EventID, err)
+	}
+	query := fmt.Sprintf(r.deleteQuery, r.config.dialect.placeholder(1))
+	if _, err := tx.ExecContext(ctx, query, msg.EventID); err != nil {
+		return false, fmt.Errorf("failed to remove quarantined message %s: %w", msg.EventID, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,301 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var relayColumns = []string{"event_id", "event_topic", "event_domain", "event_type", "object_type", "producer", "correlation_id", "payload", "metadata", "created_at", "attempts", "aggregate_key", "aggregate_sequence"}
+
+type recordingDispatcher struct {
+	mu       sync.Mutex
+	messages []*Message
+	failOn   string
+}
+
+func (d *recordingDispatcher) Dispatch(_ context.Context, msg *Message) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if msg.EventID == d.failOn {
+		return errors.New("broker unavailable")
+	}
+	d.messages = append(d.messages, msg)
+	return nil
+}
+
+func relayRows(ids ...string) *sqlmock.Rows {
+	return relayRowsWithAttempts(0, ids...)
+}
+
+func relayRowsWithAttempts(attempts int, ids ...string) *sqlmock.Rows {
+	rows := sqlmock.NewRows(relayColumns)
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, id := range ids {
+		rows.AddRow(id, "test.topic", "test.domain", "test.type", "test.object", nil, "corr", []byte(`{"n":1}`), nil, createdAt.Add(time.Duration(i)*time.Second), attempts, nil, nil)
+	}
+	return rows
+}
+
+func TestNewRelay(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	t.Run("valid", func(t *testing.T) {
+		relay, err := NewRelay(db, &recordingDispatcher{}, WithRelayBatchSize(10), WithRelayConcurrency(2), WithPollInterval(time.Millisecond))
+		require.NoError(t, err)
+		assert.Contains(t, relay.selectQuery, `FROM "outbox_message"`)
+		assert.Contains(t, relay.selectQuery, "(o.next_attempt_at IS NULL OR o.next_attempt_at <= $1)")
+		assert.Contains(t, relay.selectQuery, "p.aggregate_sequence < o.aggregate_sequence")
+		assert.Contains(t, relay.selectQuery, "(o.deliver_at IS NULL OR o.deliver_at <= $2)")
+		assert.Contains(t, relay.selectQuery, "LIMIT 10 FOR UPDATE SKIP LOCKED")
+	})
+
+	t.Run("nil dispatcher", func(t *testing.T) {
+		_, err := NewRelay(db, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := NewRelay(db, &recordingDispatcher{}, WithRelayBatchSize(0))
+		assert.Error(t, err)
+
+		_, err = NewRelay(db, &recordingDispatcher{}, WithRelayTableName("users; DROP TABLE users"))
+		assert.Error(t, err)
+
+		_, err = NewRelay(db, &recordingDispatcher{}, WithRelayDialect(DialectSQLite), WithRelayConcurrency(2))
+		assert.Error(t, err)
+
+		_, err = NewRelay(db, &recordingDispatcher{}, WithRelayMaxAttempts(0))
+		assert.Error(t, err)
+	})
+
+	t.Run("dialects", func(t *testing.T) {
+		mysql, err := NewRelay(db, &recordingDispatcher{}, WithRelayDialect(DialectMySQL))
+		require.NoError(t, err)
+		assert.Contains(t, mysql.selectQuery, "FROM `outbox_message`")
+		assert.Contains(t, mysql.selectQuery, "FOR UPDATE SKIP LOCKED")
+		assert.Contains(t, mysql.markQuery, "published_at = ?")
+
+		sqlite, err := NewRelay(db, &recordingDispatcher{}, WithRelayDialect(DialectSQLite))
+		require.NoError(t, err)
+		assert.NotContains(t, sqlite.selectQuery, "FOR UPDATE")
+	})
+}
+
+func TestRelayBatch(t *testing.T) {
+	t.Run("marks dispatched messages as published", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		dispatcher := &recordingDispatcher{}
+		relay, err := NewRelay(db, dispatcher)
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).WillReturnRows(relayRows("id-1", "id-2"))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "outbox_message" SET status = 'published', published_at = $1 WHERE event_id IN ($2, $3)`)).
+			WithArgs(sqlmock.AnyArg(), "id-1", "id-2").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		// When
+		relayed, err := relay.RelayBatch(context.Background())
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, 2, relayed)
+		require.Len(t, dispatcher.messages, 2)
+		assert.Equal(t, "id-1", dispatcher.messages[0].EventID)
+		assert.Equal(t, "corr", dispatcher.messages[0].CorrelationID)
+		assert.JSONEq(t, `{"n":1}`, string(dispatcher.messages[0].Payload))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("deletes dispatched messages", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		relay, err := NewRelay(db, &recordingDispatcher{}, WithDeleteOnRelay())
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).WillReturnRows(relayRows("id-1"))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "outbox_message" WHERE event_id IN ($1)`)).
+			WithArgs("id-1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// When
+		relayed, err := relay.RelayBatch(context.Background())
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, 1, relayed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("stops at the first failed dispatch", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		dispatcher := &recordingDispatcher{failOn: "id-2"}
+		relay, err := NewRelay(db, dispatcher)
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).WillReturnRows(relayRows("id-1", "id-2", "id-3"))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "outbox_message" SET attempts = $1, last_error = $2, next_attempt_at = $3 WHERE event_id = $4`)).
+			WithArgs(1, "broker unavailable", sqlmock.AnyArg(), "id-2").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("WHERE event_id IN ($2)")).
+			WithArgs(sqlmock.AnyArg(), "id-1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// When
+		relayed, err := relay.RelayBatch(context.Background())
+
+		// Then
+		var publishErr *PublishError
+		require.ErrorAs(t, err, &publishErr)
+		assert.Equal(t, "id-2", publishErr.MessageID)
+		assert.Equal(t, 1, publishErr.Attempts)
+		assert.Equal(t, 1, relayed)
+		assert.Len(t, dispatcher.messages, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("quarantines a message after the last attempt", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		var reported []error
+		dispatcher := &recordingDispatcher{failOn: "id-1"}
+		relay, err := NewRelay(db, dispatcher, WithRelayMaxAttempts(3), WithRelayErrorHandler(func(err error) {
+			reported = append(reported, err)
+		}))
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).WillReturnRows(relayRowsWithAttempts(2, "id-1", "id-2"))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_message_dead_letter" (`+deadLetterColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`)).
+			WithArgs("id-1", "test.topic", "test.domain", "test.type", "test.object", "", "corr", []byte(`{"n":1}`), nil, sqlmock.AnyArg(), nil, nil, 3, "broker unavailable", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "outbox_message" WHERE event_id IN ($1)`)).
+			WithArgs("id-1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("WHERE event_id IN ($2)")).
+			WithArgs(sqlmock.AnyArg(), "id-2").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// When
+		relayed, err := relay.RelayBatch(context.Background())
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, 1, relayed)
+		require.Len(t, reported, 1)
+		assert.ErrorIs(t, reported[0], ErrDeadLettered)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("quarantines a permanent failure at once", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		relay, err := NewRelay(db, DispatcherFunc(func(context.Context, *Message) error {
+			return Permanent(errors.New("payload rejected"))
+		}))
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).WillReturnRows(relayRows("id-1"))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_message_dead_letter"`)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "outbox_message" WHERE event_id IN ($1)`)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// When
+		relayed, err := relay.RelayBatch(context.Background())
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, 0, relayed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("empty table", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		relay, err := NewRelay(db, &recordingDispatcher{})
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).WillReturnRows(relayRows())
+		mock.ExpectCommit()
+
+		// When
+		relayed, err := relay.RelayBatch(context.Background())
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, 0, relayed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRelayRun(t *testing.T) {
+	// Given
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dispatcher := &recordingDispatcher{}
+	var relayErr error
+	relay, err := NewRelay(db, dispatcher, WithPollInterval(time.Millisecond), WithRelayErrorHandler(func(err error) {
+		relayErr = err
+		cancel()
+	}))
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).WillReturnRows(relayRows("id-1"))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "outbox_message"`)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin().WillReturnError(errors.New("connection refused"))
+
+	// When
+	err = relay.Run(ctx)
+
+	// Then
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorContains(t, relayErr, "connection refused")
+	assert.Len(t, dispatcher.messages, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,432 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+var (
+	defaultRetention          = 7 * 24 * time.Hour
+	defaultRetentionBatchSize = 1000
+	defaultRetentionInterval  = time.Minute
+)
+
+// archiveColumns are the columns of the archive table in scan order
+const archiveColumns = "event_id, event_topic, event_domain, event_type, object_type, producer, correlation_id, payload, metadata, created_at, aggregate_key, aggregate_sequence, published_at"
+
+// archiveTableName returns the archive table of an outbox table
+func archiveTableName(tableName string) string {
+	return tableName + "_archive"
+}
+
+// ArchivedMessage is a published message removed from the outbox table
+type ArchivedMessage struct {
+	*Message
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// Archiver keeps published messages before the Cleaner deletes them.
+// Archive runs in the deleting transaction, which is rolled back when it fails.
+type Archiver interface {
+	Archive(ctx context.Context, tx *sql.Tx, messages []*ArchivedMessage) error
+}
+
+// ArchiverFunc adapts a function to the Archiver interface
+type ArchiverFunc func(ctx context.Context, tx *sql.Tx, messages []*ArchivedMessage) error
+
+func (f ArchiverFunc) Archive(ctx context.Context, tx *sql.Tx, messages []*ArchivedMessage) error {
+	return f(ctx, tx, messages)
+}
+
+// Stats describes the size of the outbox tables and how far the relay lags behind
+type Stats struct {
+	Pending     int64         // Messages not published yet, including scheduled ones
+	Published   int64         // Published messages not removed yet
+	DeadLetters int64         // Messages in the dead-letter table
+	Lag         time.Duration // How long the oldest due message has been waiting, zero when none is
+}
+
+// RetentionReport is the outcome of a cleanup cycle of Cleaner.Run
+type RetentionReport struct {
+	Removed int           // Published messages deleted, and archived when configured
+	Elapsed time.Duration // Time spent removing messages
+	Stats   Stats
+}
+
+// RetentionDB queries the outbox tables and starts transactions, such as *sql.DB
+type RetentionDB interface {
+	MigrationDB
+	TxBeginner
+}
+
+type retentionConfig struct {
+	tableName    string
+	dialect      Dialect
+	retention    time.Duration
+	batchSize    int
+	interval     time.Duration
+	archiver     Archiver
+	archiveTable bool
+	onReport     func(RetentionReport)
+	onError      func(error)
+}
+
+// RetentionOption defines a function that configures a Cleaner
+type RetentionOption func(*retentionConfig) error
+
+// WithRetentionTableName sets the outbox table the cleaner removes published messages from
+func WithRetentionTableName(tableName string) RetentionOption {
+	return func(c *retentionConfig) error {
+		sanitizedTableName, err := sanitizeTableName(tableName)
+		if err != nil {
+			return fmt.Errorf("invalid table name: %w", err)
+		}
+		c.tableName = sanitizedTableName
+		return nil
+	}
+}
+
+// WithRetentionDialect sets the SQL dialect of the tables.
+// Without it the dialect is detected from a db implementing DriverNamer, or Postgres.
+func WithRetentionDialect(dialect Dialect) RetentionOption {
+	return func(c *retentionConfig) error {
+		if err := dialect.validate(); err != nil {
+			return err
+		}
+		c.dialect = dialect
+		return nil
+	}
+}
+
+// WithRetention sets how long published messages are kept, 7 days by default
+func WithRetention(retention time.Duration) RetentionOption {
+	return func(c *retentionConfig) error {
+		if retention <= 0 {
+			return fmt.Errorf("retention must be positive, got %s", retention)
+		}
+		c.retention = retention
+		return nil
+	}
+}
+
+// WithRetentionBatchSize sets the maximum number of messages removed per transaction,
+// which bounds how long rows stay locked, 1000 by default
+func WithRetentionBatchSize(size int) RetentionOption {
+	return func(c *retentionConfig) error {
+		if size <= 0 {
+			return fmt.Errorf("batch size must be positive, got %d", size)
+		}
+		if size > maxQueryParameters {
+			return fmt.Errorf("batch size must not exceed %d, got %d", maxQueryParameters, size)
+		}
+		c.batchSize = size
+		return nil
+	}
+}
+
+// WithRetentionInterval sets how often Run removes expired messages, every minute by default
+func WithRetentionInterval(interval time.Duration) RetentionOption {
+	return func(c *retentionConfig) error {
+		if interval <= 0 {
+			return fmt.Errorf("interval must be positive, got %s", interval)
+		}
+		c.interval = interval
+		return nil
+	}
+}
+
+// WithArchiver hands expired messages to archiver before they are deleted, such as a JSONLArchiver
+func WithArchiver(archiver Archiver) RetentionOption {
+	return func(c *retentionConfig) error {
+		if archiver == nil {
+			return fmt.Errorf("archiver cannot be nil")
+		}
+		c.archiver = archiver
+		return nil
+	}
+}
+
+// WithArchiveTable moves expired messages to <table>_archive, created by Migrate,
+// instead of deleting them
+func WithArchiveTable() RetentionOption {
+	return func(c *retentionConfig) error {
+		c.archiveTable = true
+		return nil
+	}
+}
+
+// WithRetentionReporter sets a function called after each cycle of Run, such as to export metrics
+func WithRetentionReporter(reporter func(RetentionReport)) RetentionOption {
+	return func(c *retentionConfig) error {
+		c.onReport = reporter
+		return nil
+	}
+}
+
+// WithRetentionErrorHandler sets a function called with errors that do not stop Run
+func WithRetentionErrorHandler(handler func(error)) RetentionOption {
+	return func(c *retentionConfig) error {
+		c.onError = handler
+		return nil
+	}
+}
+
+// Cleaner removes published messages once they are older than the retention,
+// optionally archiving them, and reports the size of the outbox tables.
+//
+// Messages are removed in batches of one transaction each. On Postgres and MySQL
+// they are claimed with FOR UPDATE SKIP LOCKED, so several cleaners can share a table.
+type Cleaner struct {
+	db     RetentionDB
+	config retentionConfig
+
+	selectQuery  string
+	deleteQuery  string
+	archiveQuery string
+	table        string
+	dlTable      string
+}
+
+// NewCleaner creates a new Cleaner of the outbox table in db
+func NewCleaner(db RetentionDB, opts ...RetentionOption) (*Cleaner, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db cannot be nil")
+	}
+
+	config := retentionConfig{
+		tableName: defaultTableName,
+		retention: defaultRetention,
+		batchSize: defaultRetentionBatchSize,
+		interval:  defaultRetentionInterval,
+	}
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return nil, fmt.Errorf("failed to apply retention option: %w", err)
+		}
+	}
+	if config.archiver != nil && config.archiveTable {
+		return nil, fmt.Errorf("archiver and archive table cannot be used together")
+	}
+	if config.dialect == "" {
+		dialect, err := detectDialect(db)
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect dialect: %w", err)
+		}
+		config.dialect = dialect
+	}
+
+	const selectQuery = "SELECT %s FROM %s WHERE published_at IS NOT NULL AND published_at < %s ORDER BY published_at, event_id LIMIT %d%s"
+	table := config.dialect.QuoteIdentifier(config.tableName)
+	return &Cleaner{
+		db:           db,
+		config:       config,
+		selectQuery:  fmt.Sprintf(selectQuery, archiveColumns, table, config.dialect.placeholder(1), config.batchSize, config.dialect.lockClause()),
+		deleteQuery:  "DELETE FROM " + table + " WHERE event_id IN (%s)",
+		archiveQuery: archiveInsertQuery(config.dialect, config.dialect.QuoteIdentifier(archiveTableName(config.tableName)), table),
+		table:        table,
+		dlTable:      config.dialect.QuoteIdentifier(deadLetterTableName(config.tableName)),
+	}, nil
+}
+
+// archiveInsertQuery returns the statement copying messages to the archive table, with a
+// %s for the event ID placeholders. Messages archived before, by a cycle that failed to
+// delete them, are skipped.
+func archiveInsertQuery(dialect Dialect, archiveTable, table string) string {
+	const query = "INSERT %sINTO %s (%s) SELECT %s FROM %s WHERE event_id IN (%%s)%s"
+
+	if dialect == DialectMySQL {
+		return fmt.Sprintf(query, "IGNORE ", archiveTable, archiveColumns, archiveColumns, table, "")
+	}
+	return fmt.Sprintf(query, "", archiveTable, archiveColumns, archiveColumns, table, " ON CONFLICT DO NOTHING")
+}
+
+// Run removes expired messages every interval and blocks until ctx is done.
+// Errors are reported to the error handler and retried at the next interval.
+func (c *Cleaner) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.config.interval)
+	defer ticker.Stop()
+
+	for {
+		c.cycle(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// cycle removes expired messages and reports the outcome
+func (c *Cleaner) cycle(ctx context.Context) {
+	start := time.Now()
+	removed, err := c.Cleanup(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil && c.config.onError != nil {
+		c.config.onError(err)
+	}
+
+	if c.config.onReport == nil {
+		return
+	}
+	report := RetentionReport{Removed: removed, Elapsed: time.Since(start)}
+	stats, err := c.Stats(ctx)
+	if err != nil {
+		if c.config.onError != nil && ctx.Err() == nil {
+			c.config.onError(err)
+		}
+		return
+	}
+	report.Stats = *stats
+	c.config.onReport(report)
+}
+
+// Cleanup removes the messages published before the retention, one batch at a time,
+// and returns the number of messages removed
+func (c *Cleaner) Cleanup(ctx context.Context) (int, error) {
+	cutoff := time.Now().UTC().Add(-c.config.retention)
+
+	var removed int
+	for {
+		n, err := c.cleanupBatch(ctx, cutoff)
+		removed += n
+		if err != nil {
+			return removed, err
+		}
+		if n < c.config.batchSize {
+			return removed, nil
+		}
+	}
+}
+
+// cleanupBatch removes one batch of messages published before cutoff
+func (c *Cleaner) cleanupBatch(ctx context.Context, cutoff time.Time) (int, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	messages, err := c.expired(ctx, tx, cutoff)
+	if err != nil || len(messages) == 0 {
+		return 0, err
+	}
+
+	ids := make([]any, len(messages))
+	for i, msg := range messages {
+		ids[i] = msg.EventID
+	}
+	in := c.config.dialect.placeholders(1, len(ids))
+
+	switch {
+	case c.config.archiveTable:
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(c.archiveQuery, in), ids...); err != nil {
+			return 0, fmt.Errorf("failed to archive messages: %w", err)
+		}
+	case c.config.archiver != nil:
+		if err := c.config.archiver.Archive(ctx, tx, messages); err != nil {
+			return 0, fmt.Errorf("failed to archive messages: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(c.deleteQuery, in), ids...); err != nil {
+		return 0, fmt.Errorf("failed to delete expired messages: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(messages), nil
+}
+
+// expired locks and reads the next batch of messages published before cutoff
+func (c *Cleaner) expired(ctx context.Context, tx *sql.Tx, cutoff time.Time) ([]*ArchivedMessage, error) {
+	rows, err := tx.QueryContext(ctx, c.selectQuery, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select expired messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*ArchivedMessage
+	for rows.Next() {
+		var (
+			msg                     Message
+			archived                = ArchivedMessage{Message: &msg}
+			producer, correlationID sql.NullString
+			payload, metadata       []byte
+			aggregateKey            sql.NullString
+			sequence                sql.NullInt64
+		)
+		if err := rows.Scan(&msg.EventID, &msg.EventTopic, &msg.EventDomain, &msg.EventType, &msg.ObjectType,
+			&producer, &correlationID, &payload, &metadata, scanTime{&msg.CreatedAt}, &aggregateKey, &sequence, scanTime{&archived.PublishedAt}); err != nil {
+			return nil, fmt.Errorf("failed to scan expired message: %w", err)
+		}
+
+		msg.Producer = producer.String
+		msg.CorrelationID = correlationID.String
+		msg.AggregateKey = aggregateKey.String
+		msg.Sequence = sequence.Int64
+		if len(payload) > 0 {
+			msg.Payload = json.RawMessage(payload)
+		}
+		if len(metadata) > 0 {
+			msg.Metadata = json.RawMessage(metadata)
+		}
+		messages = append(messages, &archived)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read expired messages: %w", err)
+	}
+	return messages, nil
+}
+
+// Stats counts the messages of the outbox and dead-letter tables and measures the relay lag
+func (c *Cleaner) Stats(ctx context.Context) (*Stats, error) {
+	var stats Stats
+
+	countQuery := "SELECT COUNT(*) - COUNT(published_at), COUNT(published_at) FROM " + c.table
+	if err := c.queryRow(ctx, countQuery, nil, &stats.Pending, &stats.Published); err != nil {
+		return nil, fmt.Errorf("failed to count messages: %w", err)
+	}
+
+	if err := c.queryRow(ctx, "SELECT COUNT(*) FROM "+c.dlTable, nil, &stats.DeadLetters); err != nil {
+		return nil, fmt.Errorf("failed to count dead letters: %w", err)
+	}
+
+	// A scheduled message is waiting from the time it is due
+	now := time.Now().UTC()
+	lagQuery := fmt.Sprintf("SELECT MIN(COALESCE(deliver_at, created_at)) FROM %s WHERE published_at IS NULL AND (deliver_at IS NULL OR deliver_at <= %s)",
+		c.table, c.config.dialect.placeholder(1))
+	var oldest sql.NullTime
+	if err := c.queryRow(ctx, lagQuery, []any{now}, &oldest); err != nil {
+		return nil, fmt.Errorf("failed to measure lag: %w", err)
+	}
+	if oldest.Valid && oldest.Time.Before(now) {
+		stats.Lag = now.Sub(oldest.Time)
+	}
+	return &stats, nil
+}
+
+// queryRow scans the single row returned by query
+func (c *Cleaner) queryRow(ctx context.Context, query string, args []any, dest ...any) error {
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	return rows.Close()
+}
@@ -0,0 +1,251 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var archiveRowColumns = []string{"event_id", "event_topic", "event_domain", "event_type", "object_type", "producer", "correlation_id", "payload", "metadata", "created_at", "aggregate_key", "aggregate_sequence", "published_at"}
+
+func archiveRows(ids ...string) *sqlmock.Rows {
+	rows := sqlmock.NewRows(archiveRowColumns)
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, id := range ids {
+		rows.AddRow(id, "test.topic", "test.domain", "test.type", "test.object", nil, "corr", []byte(`{"n":1}`), nil, createdAt, nil, nil, createdAt.Add(time.Second))
+	}
+	return rows
+}
+
+func TestNewCleaner(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	cleaner, err := NewCleaner(db, WithRetentionBatchSize(10))
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT `+archiveColumns+` FROM "outbox_message" WHERE published_at IS NOT NULL AND published_at < $1 ORDER BY published_at, event_id LIMIT 10 FOR UPDATE SKIP LOCKED`, cleaner.selectQuery)
+
+	_, err = NewCleaner(nil)
+	assert.Error(t, err)
+
+	_, err = NewCleaner(db, WithRetention(0))
+	assert.Error(t, err)
+
+	_, err = NewCleaner(db, WithArchiveTable(), WithArchiver(ArchiverFunc(func(context.Context, *sql.Tx, []*ArchivedMessage) error { return nil })))
+	assert.Error(t, err)
+}
+
+func TestCleaner_Cleanup(t *testing.T) {
+	t.Run("deletes expired messages in batches", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		cleaner, err := NewCleaner(db, WithRetention(time.Hour), WithRetentionBatchSize(2))
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("LIMIT 2 FOR UPDATE SKIP LOCKED")).
+			WithArgs(sqlmock.AnyArg()).
+			WillReturnRows(archiveRows("id-1", "id-2"))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "outbox_message" WHERE event_id IN ($1, $2)`)).
+			WithArgs("id-1", "id-2").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("LIMIT 2 FOR UPDATE SKIP LOCKED")).WillReturnRows(archiveRows("id-3"))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "outbox_message" WHERE event_id IN ($1)`)).
+			WithArgs("id-3").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// When
+		removed, err := cleaner.Cleanup(context.Background())
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, 3, removed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("moves expired messages to the archive table", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		cleaner, err := NewCleaner(db, WithArchiveTable())
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).WillReturnRows(archiveRows("id-1"))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_message_archive" (` + archiveColumns + `) SELECT ` + archiveColumns + ` FROM "outbox_message" WHERE event_id IN ($1) ON CONFLICT DO NOTHING`)).
+			WithArgs("id-1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "outbox_message"`)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// When
+		removed, err := cleaner.Cleanup(context.Background())
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, 1, removed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("keeps messages the archiver fails on", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		var archived []*ArchivedMessage
+		cleaner, err := NewCleaner(db, WithArchiver(ArchiverFunc(func(_ context.Context, _ *sql.Tx, messages []*ArchivedMessage) error {
+			archived = messages
+			return errors.New("disk full")
+		})))
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).WillReturnRows(archiveRows("id-1"))
+		mock.ExpectRollback()
+
+		// When
+		removed, err := cleaner.Cleanup(context.Background())
+
+		// Then
+		assert.ErrorContains(t, err, "disk full")
+		assert.Equal(t, 0, removed)
+		require.Len(t, archived, 1)
+		assert.Equal(t, "id-1", archived[0].EventID)
+		assert.Equal(t, "corr", archived[0].CorrelationID)
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC), archived[0].PublishedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestArchiveInsertQuery(t *testing.T) {
+	assert.Equal(t,
+		"INSERT IGNORE INTO `archive` ("+archiveColumns+") SELECT "+archiveColumns+" FROM `outbox` WHERE event_id IN (%s)",
+		archiveInsertQuery(DialectMySQL, "`archive`", "`outbox`"))
+	assert.Equal(t,
+		`INSERT INTO "archive" (`+archiveColumns+`) SELECT `+archiveColumns+` FROM "outbox" WHERE event_id IN (%s) ON CONFLICT DO NOTHING`,
+		archiveInsertQuery(DialectSQLite, `"archive"`, `"outbox"`))
+}
+
+func TestCleaner_Cleanup_AlreadyArchived(t *testing.T) {
+	// Given
+	db := openSQLite(t)
+	ctx := context.Background()
+	require.NoError(t, Migrate(ctx, db, WithMigrationDialect(DialectSQLite)))
+
+	publisher, err := NewPublisher(db, WithDialect(DialectSQLite))
+	require.NoError(t, err)
+	var ids []string
+	for n := 0; n < 2; n++ {
+		msg, err := NewMessageBuilder().
+			SetEventTopic("orders").
+			SetEventDomain("shop").
+			SetEventType("order.created").
+			SetObjectType("order").
+			Build()
+		require.NoError(t, err)
+		require.NoError(t, publisher.Publish(ctx, msg))
+		ids = append(ids, msg.EventID)
+	}
+	publishedAt := time.Now().UTC().Add(-48 * time.Hour)
+	_, err = db.ExecContext(ctx, `UPDATE "outbox_message" SET published_at = ?`, publishedAt)
+	require.NoError(t, err)
+	// A previous cycle archived the first message but failed to delete it
+	_, err = db.ExecContext(ctx, `INSERT INTO "outbox_message_archive" (`+archiveColumns+`) SELECT `+archiveColumns+` FROM "outbox_message" WHERE event_id = ?`, ids[0])
+	require.NoError(t, err)
+
+	cleaner, err := NewCleaner(db, WithRetentionDialect(DialectSQLite), WithRetention(time.Hour), WithArchiveTable())
+	require.NoError(t, err)
+
+	// When
+	removed, err := cleaner.Cleanup(ctx)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	var archived, remaining int
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM "outbox_message_archive"`).Scan(&archived))
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM "outbox_message"`).Scan(&remaining))
+	assert.Equal(t, 2, archived)
+	assert.Zero(t, remaining)
+}
+
+func TestCleaner_Stats(t *testing.T) {
+	// Given
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	cleaner, err := NewCleaner(db, WithRetentionDialect(DialectMySQL))
+	require.NoError(t, err)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) - COUNT(published_at), COUNT(published_at) FROM `outbox_message`")).
+		WillReturnRows(sqlmock.NewRows([]string{"pending", "published"}).AddRow(3, 40))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `outbox_message_dead_letter`")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT MIN(COALESCE(deliver_at, created_at)) FROM `outbox_message` WHERE published_at IS NULL AND (deliver_at IS NULL OR deliver_at <= ?)")).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"oldest"}).AddRow(time.Now().Add(-time.Minute)))
+
+	// When
+	stats, err := cleaner.Stats(context.Background())
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.Pending)
+	assert.Equal(t, int64(40), stats.Published)
+	assert.Equal(t, int64(2), stats.DeadLetters)
+	assert.InDelta(t, time.Minute, stats.Lag, float64(time.Second))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCleaner_Run(t *testing.T) {
+	// Given
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var report RetentionReport
+	cleaner, err := NewCleaner(db, WithRetentionReporter(func(r RetentionReport) {
+		report = r
+		cancel()
+	}))
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).WillReturnRows(archiveRows("id-1"))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "outbox_message"`)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta("COUNT(published_at)")).WillReturnRows(sqlmock.NewRows([]string{"pending", "published"}).AddRow(0, 5))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM")).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT MIN(")).WillReturnRows(sqlmock.NewRows([]string{"oldest"}).AddRow(nil))
+
+	// When
+	err = cleaner.Run(ctx)
+
+	// Then
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, report.Removed)
+	assert.Equal(t, int64(5), report.Stats.Published)
+	assert.Zero(t, report.Stats.Lag)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
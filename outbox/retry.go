@@ -0,0 +1,226 @@
+package outbox
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	defaultRetryInitialInterval = 100 * time.Millisecond
+	defaultRetryMaxInterval     = 5 * time.Second
+	defaultRetryMultiplier      = 2.0
+	defaultRetryJitter          = 0.2
+	defaultRetryMaxElapsedTime  = 30 * time.Second
+
+	// MySQL errors carry the SQLSTATE in their message, e.g. "Error 1213 (40001): Deadlock found"
+	mysqlSQLStateRegex = regexp.MustCompile(`^Error (\d+) \(([0-9A-Z]{5})\)`)
+)
+
+// RetryPolicy decides whether and when a failed write is attempted again
+type RetryPolicy interface {
+	// NextBackoff returns how long to wait before the next attempt, or false to stop.
+	// attempt is the number of attempts made so far and elapsed the time since the first one.
+	NextBackoff(attempt int, elapsed time.Duration, err error) (time.Duration, bool)
+}
+
+// ExponentialBackoff is a RetryPolicy doubling the wait between attempts
+type ExponentialBackoff struct {
+	InitialInterval time.Duration    // Wait before the second attempt
+	MaxInterval     time.Duration    // Upper bound of a single wait
+	Multiplier      float64          // Growth factor of the wait
+	Jitter          float64          // Randomization factor in [0, 1]; 0.2 waits 80% to 120% of the interval
+	MaxAttempts     int              // Total attempts including the first; 0 means unlimited
+	MaxElapsedTime  time.Duration    // Stop once this much time has passed; 0 means unlimited
+	Retryable       func(error) bool // Classifies errors; IsRetryableError when nil
+}
+
+// DefaultRetryPolicy returns an ExponentialBackoff making at most maxRetries+1 attempts
+func DefaultRetryPolicy(maxRetries int) *ExponentialBackoff {
+	return &ExponentialBackoff{
+		InitialInterval: defaultRetryInitialInterval,
+		MaxInterval:     defaultRetryMaxInterval,
+		Multiplier:      defaultRetryMultiplier,
+		Jitter:          defaultRetryJitter,
+		MaxAttempts:     maxRetries + 1,
+		MaxElapsedTime:  defaultRetryMaxElapsedTime,
+	}
+}
+
+func (b *ExponentialBackoff) NextBackoff(attempt int, elapsed time.Duration, err error) (time.Duration, bool) {
+	retryable := b.Retryable
+	if retryable == nil {
+		retryable = IsRetryableError
+	}
+	if !retryable(err) {
+		return 0, false
+	}
+	if b.MaxAttempts > 0 && attempt >= b.MaxAttempts {
+		return 0, false
+	}
+
+	interval := float64(b.InitialInterval)
+	for i := 1; i < attempt; i++ {
+		interval *= b.Multiplier
+		if b.MaxInterval > 0 && interval >= float64(b.MaxInterval) {
+			interval = float64(b.MaxInterval)
+			break
+		}
+	}
+	if b.Jitter > 0 {
+		interval *= 1 + b.Jitter*(2*rand.Float64()-1)
+	}
+
+	backoff := time.Duration(interval)
+	if after, ok := RetryAfter(err); ok && after > backoff {
+		backoff = after
+	}
+	if b.MaxElapsedTime > 0 && elapsed+backoff > b.MaxElapsedTime {
+		return 0, false
+	}
+	return backoff, true
+}
+
+// NoRetry returns a RetryPolicy making a single attempt
+func NoRetry() RetryPolicy {
+	return noRetry{}
+}
+
+type noRetry struct{}
+
+func (noRetry) NextBackoff(int, time.Duration, error) (time.Duration, bool) {
+	return 0, false
+}
+
+// IsRetryableError reports whether a failed write may succeed when attempted again.
+// Serialization failures, deadlocks, lock timeouts and connection errors are retryable;
+// constraint violations, invalid data, syntax errors, invalid transaction states, other
+// SQLSTATE codes and errors marked with Permanent are not. Unknown errors without an
+// SQLSTATE are retryable.
+func IsRetryableError(err error) bool {
+	if err == nil || IsPermanent(err) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrNoTransaction) {
+		return false
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	if state, code := sqlState(err); state != "" {
+		if state == "HY000" {
+			// MySQL general error; only a lock wait timeout may succeed when attempted again
+			return code == mysqlLockWaitTimeout
+		}
+		return isRetryableSQLState(state)
+	}
+
+	// SQLite reports errors by message only; "database is locked" is retryable
+	return !strings.Contains(err.Error(), "constraint failed")
+}
+
+// permanentError marks an error that will not succeed when attempted again
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks err as not retryable, such as a message rejected by the broker.
+// It returns nil when err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err or an error it wraps was marked with Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// RetryAfter returns the wait requested by err or an error it wraps through a
+// RetryAfter() time.Duration method, such as a webhook answering with Retry-After.
+func RetryAfter(err error) (time.Duration, bool) {
+	var after interface{ RetryAfter() time.Duration }
+	if errors.As(err, &after) && after.RetryAfter() > 0 {
+		return after.RetryAfter(), true
+	}
+	return 0, false
+}
+
+// mysqlLockWaitTimeout is the MySQL error number of ER_LOCK_WAIT_TIMEOUT
+const mysqlLockWaitTimeout = "1205"
+
+// sqlState extracts the SQLSTATE code of a database error, if any, and the MySQL error number
+func sqlState(err error) (state string, code string) {
+	// pgx and lib/pq errors
+	var stater interface{ SQLState() string }
+	if errors.As(err, &stater) {
+		return stater.SQLState(), ""
+	}
+
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if m := mysqlSQLStateRegex.FindStringSubmatch(e.Error()); m != nil {
+			return m[2], m[1]
+		}
+	}
+	return "", ""
+}
+
+// isRetryableSQLState classifies an SQLSTATE code; codes not known to be transient are not retryable,
+// including class 25 (invalid transaction state) such as 25P02, a statement in an aborted transaction
+func isRetryableSQLState(state string) bool {
+	if len(state) != 5 {
+		return false
+	}
+
+	switch state {
+	case "40001", // serialization_failure, MySQL deadlock
+		"40P01", // deadlock_detected
+		"55P03": // lock_not_available
+		return true
+	}
+
+	switch state[:2] {
+	case "08", // connection exception
+		"53", // insufficient resources
+		"57", // operator intervention, such as admin shutdown
+		"40": // transaction rollback
+		return true
+	}
+	return false
+}
+
+// wait sleeps for d or until ctx is done
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,225 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pgError mimics the SQLState method of pgx and lib/pq errors
+type pgError struct {
+	code string
+}
+
+func (e *pgError) Error() string {
+	return "pg error " + e.code
+}
+
+func (e *pgError) SQLState() string {
+	return e.code
+}
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"nil", nil, false},
+		{"context canceled", context.Canceled, false},
+		{"bad connection", driver.ErrBadConn, true},
+		{"connection reset", fmt.Errorf("write: %w", syscall.ECONNRESET), true},
+		{"postgres serialization failure", &pgError{code: "40001"}, true},
+		{"postgres deadlock", fmt.Errorf("insert: %w", &pgError{code: "40P01"}), true},
+		{"postgres unique violation", &pgError{code: "23505"}, false},
+		{"postgres syntax error", &pgError{code: "42601"}, false},
+		{"postgres admin shutdown", &pgError{code: "57P01"}, true},
+		{"postgres aborted transaction", &pgError{code: "25P02"}, false},
+		{"postgres unknown state", &pgError{code: "XX000"}, false},
+		{"mysql deadlock", errors.New("Error 1213 (40001): Deadlock found when trying to get lock"), true},
+		{"mysql lock wait timeout", errors.New("Error 1205 (HY000): Lock wait timeout exceeded; try restarting transaction"), true},
+		{"mysql general error", errors.New("Error 1105 (HY000): Unknown error"), false},
+		{"mysql duplicate entry", errors.New("Error 1062 (23000): Duplicate entry 'x' for key 'PRIMARY'"), false},
+		{"sqlite constraint", errors.New("UNIQUE constraint failed: outbox_message.event_id"), false},
+		{"sqlite busy", errors.New("database is locked"), true},
+		{"unknown", errors.New("db error"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsRetryableError(tt.err))
+		})
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	policy := &ExponentialBackoff{
+		InitialInterval: 100 * time.Millisecond,
+		MaxInterval:     300 * time.Millisecond,
+		Multiplier:      2,
+		MaxAttempts:     4,
+		MaxElapsedTime:  time.Second,
+	}
+	err := errors.New("db error")
+
+	backoff, ok := policy.NextBackoff(1, 0, err)
+	assert.True(t, ok)
+	assert.Equal(t, 100*time.Millisecond, backoff)
+
+	backoff, ok = policy.NextBackoff(2, 0, err)
+	assert.True(t, ok)
+	assert.Equal(t, 200*time.Millisecond, backoff)
+
+	backoff, ok = policy.NextBackoff(3, 0, err)
+	assert.True(t, ok)
+	assert.Equal(t, 300*time.Millisecond, backoff)
+
+	_, ok = policy.NextBackoff(4, 0, err)
+	assert.False(t, ok, "max attempts")
+
+	_, ok = policy.NextBackoff(1, 950*time.Millisecond, err)
+	assert.False(t, ok, "max elapsed time")
+
+	_, ok = policy.NextBackoff(1, 0, &pgError{code: "23505"})
+	assert.False(t, ok, "non-retryable error")
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		backoff, _ = policy.NextBackoff(1, 0, err)
+		assert.GreaterOrEqual(t, backoff, 50*time.Millisecond)
+		assert.LessOrEqual(t, backoff, 150*time.Millisecond)
+	}
+}
+
+func TestPublisher_RetryPolicy(t *testing.T) {
+	msg := &Message{
+		EventID:     "test-event-1",
+		EventTopic:  "test-topic",
+		EventDomain: "test-domain",
+		EventType:   "test-event",
+		ObjectType:  "test-object",
+		Payload:     json.RawMessage(`{"key": "value"}`),
+		CreatedAt:   time.Now(),
+	}
+
+	t.Run("does not retry constraint violations", func(t *testing.T) {
+		// Given
+		calls := 0
+		executor := &MockExecutor{
+			execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+				calls++
+				return nil, &pgError{code: "23505"}
+			},
+		}
+		publisher, err := NewPublisher(executor)
+		require.NoError(t, err)
+
+		// When
+		err = publisher.Publish(context.Background(), msg)
+
+		// Then
+		var publishErr *PublishError
+		require.ErrorAs(t, err, &publishErr)
+		assert.Equal(t, 1, publishErr.Attempts)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("stops waiting when the context is canceled", func(t *testing.T) {
+		// Given
+		ctx, cancel := context.WithCancel(context.Background())
+		executor := &MockExecutor{
+			execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+				cancel()
+				return nil, errors.New("connection reset by peer")
+			},
+		}
+		policy := &ExponentialBackoff{InitialInterval: time.Hour, Multiplier: 2}
+		publisher, err := NewPublisher(executor, WithRetryPolicy(policy))
+		require.NoError(t, err)
+
+		// When
+		start := time.Now()
+		err = publisher.Publish(ctx, msg)
+
+		// Then
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.ErrorContains(t, err, "connection reset by peer")
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("no retry", func(t *testing.T) {
+		// Given
+		calls := 0
+		executor := &MockExecutor{
+			execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+				calls++
+				return nil, errors.New("db error")
+			},
+		}
+		publisher, err := NewPublisher(executor, WithRetryPolicy(NoRetry()))
+		require.NoError(t, err)
+
+		// When
+		err = publisher.Publish(context.Background(), msg)
+
+		// Then
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+// retryAfterError asks for a wait like a webhook answering with Retry-After
+type retryAfterError struct {
+	after time.Duration
+}
+
+func (e *retryAfterError) Error() string {
+	return "service unavailable"
+}
+
+func (e *retryAfterError) RetryAfter() time.Duration {
+	return e.after
+}
+
+func TestPermanent(t *testing.T) {
+	// Given
+	cause := errors.New("rejected")
+
+	// When
+	err := fmt.Errorf("dispatch: %w", Permanent(cause))
+
+	// Then
+	assert.True(t, IsPermanent(err))
+	assert.ErrorIs(t, err, cause)
+	assert.False(t, IsRetryableError(err))
+	assert.False(t, IsPermanent(cause))
+	assert.Nil(t, Permanent(nil))
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Run("wrapped", func(t *testing.T) {
+		after, ok := RetryAfter(fmt.Errorf("dispatch: %w", &retryAfterError{after: 3 * time.Second}))
+		assert.True(t, ok)
+		assert.Equal(t, 3*time.Second, after)
+
+		_, ok = RetryAfter(errors.New("db error"))
+		assert.False(t, ok)
+	})
+
+	t.Run("extends the backoff", func(t *testing.T) {
+		policy := &ExponentialBackoff{InitialInterval: 100 * time.Millisecond, Multiplier: 2, MaxAttempts: 3}
+
+		backoff, ok := policy.NextBackoff(1, 0, &retryAfterError{after: 2 * time.Second})
+		assert.True(t, ok)
+		assert.Equal(t, 2*time.Second, backoff)
+	})
+}
@@ -0,0 +1,299 @@
+package outbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// MetadataSchemaVersion records the schema version a payload was validated against
+const MetadataSchemaVersion = "schema_version"
+
+var (
+	// ErrSchemaNotFound is returned by a strict registry for messages without a registered schema,
+	// and for messages requiring a schema version that is not registered.
+	ErrSchemaNotFound = errors.New("schema not found")
+
+	// ErrSchemaViolation is returned when a payload does not match its schema.
+	ErrSchemaViolation = errors.New("payload does not match schema")
+)
+
+// SchemaKey identifies the schema of a message payload.
+type SchemaKey struct {
+	EventDomain string
+	ObjectType  string
+	EventType   string
+}
+
+// SchemaKeyOf returns the schema key of the message.
+func SchemaKeyOf(msg *Message) SchemaKey {
+	return SchemaKey{
+		EventDomain: msg.EventDomain,
+		ObjectType:  msg.ObjectType,
+		EventType:   msg.EventType,
+	}
+}
+
+func (k SchemaKey) String() string {
+	return k.EventDomain + "/" + k.ObjectType + "/" + k.EventType
+}
+
+// Schema validates message payloads.
+type Schema interface {
+	Validate(payload json.RawMessage) error
+}
+
+// SchemaError describes a payload that failed schema validation.
+type SchemaError struct {
+	Key     SchemaKey
+	Version int
+	Err     error
+}
+
+func (e *SchemaError) Error() string {
+	if e.Version == 0 {
+		return fmt.Sprintf("schema %s: %v", e.Key, e.Err)
+	}
+	return fmt.Sprintf("schema %s v%d: %v", e.Key, e.Version, e.Err)
+}
+
+func (e *SchemaError) Unwrap() error {
+	return e.Err
+}
+
+// SchemaRegistry holds payload schemas keyed by (EventDomain, ObjectType, EventType)
+// and version. Messages are validated against the version recorded in their metadata,
+// or the latest version of their key.
+type SchemaRegistry struct {
+	mu      sync.RWMutex
+	strict  bool
+	schemas map[SchemaKey]map[int]Schema
+	latest  map[SchemaKey]int
+}
+
+// SchemaRegistryOption configures a SchemaRegistry.
+type SchemaRegistryOption func(*SchemaRegistry)
+
+// WithStrictSchemas rejects messages whose key has no registered schema.
+func WithStrictSchemas() SchemaRegistryOption {
+	return func(r *SchemaRegistry) {
+		r.strict = true
+	}
+}
+
+// NewSchemaRegistry creates an empty SchemaRegistry.
+func NewSchemaRegistry(opts ...SchemaRegistryOption) *SchemaRegistry {
+	r := &SchemaRegistry{
+		schemas: make(map[SchemaKey]map[int]Schema),
+		latest:  make(map[SchemaKey]int),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Register adds a schema version for the key. Versions start at 1 and cannot be replaced.
+func (r *SchemaRegistry) Register(key SchemaKey, version int, schema Schema) error {
+	if key.EventDomain == "" || key.ObjectType == "" || key.EventType == "" {
+		return fmt.Errorf("schema key must have event domain, object type and event type: %s", key)
+	}
+	if version <= 0 {
+		return fmt.Errorf("schema version must be positive, got %d", version)
+	}
+	if schema == nil {
+		return fmt.Errorf("schema cannot be nil")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions, ok := r.schemas[key]
+	if !ok {
+		versions = make(map[int]Schema)
+		r.schemas[key] = versions
+	}
+	if _, exists := versions[version]; exists {
+		return fmt.Errorf("schema %s v%d is already registered", key, version)
+	}
+
+	versions[version] = schema
+	if version > r.latest[key] {
+		r.latest[key] = version
+	}
+	return nil
+}
+
+// Lookup returns the given schema version of the key.
+func (r *SchemaRegistry) Lookup(key SchemaKey, version int) (Schema, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schema, ok := r.schemas[key][version]
+	return schema, ok
+}
+
+// Latest returns the latest schema version of the key.
+func (r *SchemaRegistry) Latest(key SchemaKey) (Schema, int, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	version, ok := r.latest[key]
+	if !ok {
+		return nil, 0, false
+	}
+	return r.schemas[key][version], version, true
+}
+
+// Versions returns the registered versions of the key in ascending order.
+func (r *SchemaRegistry) Versions(key SchemaKey) []int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions := make([]int, 0, len(r.schemas[key]))
+	for v := range r.schemas[key] {
+		versions = append(versions, v)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// Validate checks the message payload against the schema version recorded in its
+// metadata, or else the latest schema of its key. The message is not modified;
+// the Publisher and MessageBuilder record the version used on their own copies.
+// Messages without a registered schema pass unless the registry is strict.
+func (r *SchemaRegistry) Validate(msg *Message) error {
+	_, err := r.resolve(msg)
+	return err
+}
+
+// ValidateVersion checks the message payload against the given schema version of its key.
+// The version must be registered.
+func (r *SchemaRegistry) ValidateVersion(msg *Message, version int) error {
+	key := SchemaKeyOf(msg)
+	schema, ok := r.Lookup(key, version)
+	if !ok {
+		return &SchemaError{Key: key, Version: version, Err: ErrSchemaNotFound}
+	}
+	return validateSchema(msg, key, version, schema)
+}
+
+// resolve validates the message like Validate and returns the schema version used,
+// or 0 when no schema is registered for its key
+func (r *SchemaRegistry) resolve(msg *Message) (int, error) {
+	if version, ok := SchemaVersionOf(msg); ok {
+		return version, r.ValidateVersion(msg, version)
+	}
+
+	key := SchemaKeyOf(msg)
+	schema, version, ok := r.Latest(key)
+	if !ok {
+		if r.strict {
+			return 0, &SchemaError{Key: key, Err: ErrSchemaNotFound}
+		}
+		return 0, nil
+	}
+	return version, validateSchema(msg, key, version, schema)
+}
+
+func validateSchema(msg *Message, key SchemaKey, version int, schema Schema) error {
+	if err := schema.Validate(msg.Payload); err != nil {
+		return &SchemaError{Key: key, Version: version, Err: fmt.Errorf("%w: %v", ErrSchemaViolation, err)}
+	}
+	return nil
+}
+
+// withSchemaVersion records the schema version in the metadata of the message.
+// It returns the message itself when the version is already recorded, or else a copy.
+func withSchemaVersion(msg *Message, version int) (*Message, error) {
+	if recorded, ok := SchemaVersionOf(msg); ok && recorded == version {
+		return msg, nil
+	}
+	clone := *msg
+	if err := setMetadata(&clone, map[string]any{MetadataSchemaVersion: version}); err != nil {
+		return nil, err
+	}
+	return &clone, nil
+}
+
+// SchemaVersionOf returns the schema version recorded in the metadata of the message
+func SchemaVersionOf(msg *Message) (int, bool) {
+	fields, err := metadataFields(msg)
+	if err != nil {
+		return 0, false
+	}
+	var version int
+	if raw, ok := fields[MetadataSchemaVersion]; !ok || json.Unmarshal(raw, &version) != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+// jsonSchema implements Schema with a compiled JSON Schema document
+type jsonSchema struct {
+	schema *jsonschema.Schema
+}
+
+// NewJSONSchema compiles a JSON Schema document (draft 4 to 2020-12).
+func NewJSONSchema(document string) (Schema, error) {
+	const url = "outbox://schema.json"
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(url, strings.NewReader(document)); err != nil {
+		return nil, fmt.Errorf("invalid json schema: %w", err)
+	}
+
+	schema, err := compiler.Compile(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid json schema: %w", err)
+	}
+	return &jsonSchema{schema: schema}, nil
+}
+
+func (s *jsonSchema) Validate(payload json.RawMessage) error {
+	decoder := json.NewDecoder(bytes.NewReader(nullIfEmpty(payload)))
+	decoder.UseNumber()
+
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return err
+	}
+	return s.schema.Validate(v)
+}
+
+// structSchema implements Schema by strictly decoding into a Go type
+type structSchema[T any] struct{}
+
+// NewStructSchema returns a Schema derived from the Go type T. Payloads must decode
+// into T without unknown fields; if *T implements Validate() error it is called too.
+func NewStructSchema[T any]() Schema {
+	return structSchema[T]{}
+}
+
+func (structSchema[T]) Validate(payload json.RawMessage) error {
+	decoder := json.NewDecoder(bytes.NewReader(nullIfEmpty(payload)))
+	decoder.DisallowUnknownFields()
+
+	var v T
+	if err := decoder.Decode(&v); err != nil {
+		return err
+	}
+
+	if validator, ok := any(&v).(interface{ Validate() error }); ok {
+		return validator.Validate()
+	}
+	return nil
+}
+
+func nullIfEmpty(payload json.RawMessage) []byte {
+	if len(payload) == 0 {
+		return []byte("null")
+	}
+	return payload
+}
@@ -0,0 +1,258 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testSchemaKey = SchemaKey{EventDomain: "test.domain", ObjectType: "test.object", EventType: "test.type"}
+
+type testSchemaPayload struct {
+	Name string `json:"name"`
+}
+
+func (p *testSchemaPayload) Validate() error {
+	if p.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func newSchemaTestBuilder(payload string) *MessageBuilder {
+	return NewMessageBuilder().
+		SetEventTopic("test.topic").
+		SetEventDomain("test.domain").
+		SetEventType("test.type").
+		SetObjectType("test.object").
+		SetPayload(json.RawMessage(payload))
+}
+
+func TestSchemaRegistry_JSONSchema(t *testing.T) {
+	// Given
+	schema, err := NewJSONSchema(`{
+		"type": "object",
+		"required": ["name"],
+		"properties": {"name": {"type": "string", "minLength": 1}},
+		"additionalProperties": false
+	}`)
+	require.NoError(t, err)
+
+	registry := NewSchemaRegistry()
+	require.NoError(t, registry.Register(testSchemaKey, 1, schema))
+
+	tests := []struct {
+		name    string
+		payload string
+		wantErr bool
+	}{
+		{name: "valid payload", payload: `{"name":"value"}`},
+		{name: "missing required field", payload: `{}`, wantErr: true},
+		{name: "unknown field", payload: `{"name":"value","extra":1}`, wantErr: true},
+		{name: "wrong type", payload: `{"name":1}`, wantErr: true},
+		{name: "empty payload", payload: ``, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			msg, err := newSchemaTestBuilder(tt.payload).SetSchemaRegistry(registry).Build()
+
+			// Then
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrSchemaViolation)
+				var schemaErr *SchemaError
+				require.ErrorAs(t, err, &schemaErr)
+				assert.Equal(t, testSchemaKey, schemaErr.Key)
+				assert.Equal(t, 1, schemaErr.Version)
+				assert.Nil(t, msg)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, msg)
+			}
+		})
+	}
+}
+
+func TestSchemaRegistry_StructSchema(t *testing.T) {
+	// Given
+	registry := NewSchemaRegistry()
+	require.NoError(t, registry.Register(testSchemaKey, 1, NewStructSchema[testSchemaPayload]()))
+
+	// When
+	_, validErr := newSchemaTestBuilder(`{"name":"value"}`).SetSchemaRegistry(registry).Build()
+	_, unknownErr := newSchemaTestBuilder(`{"name":"value","extra":1}`).SetSchemaRegistry(registry).Build()
+	_, invalidErr := newSchemaTestBuilder(`{"name":""}`).SetSchemaRegistry(registry).Build()
+
+	// Then
+	assert.NoError(t, validErr)
+	assert.ErrorIs(t, unknownErr, ErrSchemaViolation)
+	assert.ErrorIs(t, invalidErr, ErrSchemaViolation)
+}
+
+func TestSchemaRegistry_Versions(t *testing.T) {
+	// Given
+	registry := NewSchemaRegistry()
+	v1, err := NewJSONSchema(`{"type":"object","required":["name"]}`)
+	require.NoError(t, err)
+	v2, err := NewJSONSchema(`{"type":"object","required":["name","age"]}`)
+	require.NoError(t, err)
+
+	// When
+	require.NoError(t, registry.Register(testSchemaKey, 2, v2))
+	require.NoError(t, registry.Register(testSchemaKey, 1, v1))
+	duplicateErr := registry.Register(testSchemaKey, 1, v1)
+
+	// Then
+	assert.Error(t, duplicateErr)
+	assert.Equal(t, []int{1, 2}, registry.Versions(testSchemaKey))
+	_, latest, ok := registry.Latest(testSchemaKey)
+	assert.True(t, ok)
+	assert.Equal(t, 2, latest)
+	_, err = newSchemaTestBuilder(`{"name":"value"}`).SetSchemaRegistry(registry).Build()
+	assert.ErrorIs(t, err, ErrSchemaViolation)
+}
+
+func TestSchemaRegistry_ExplicitVersion(t *testing.T) {
+	// Given
+	registry := NewSchemaRegistry()
+	v1, err := NewJSONSchema(`{"type":"object","required":["name"]}`)
+	require.NoError(t, err)
+	v2, err := NewJSONSchema(`{"type":"object","required":["name","age"]}`)
+	require.NoError(t, err)
+	require.NoError(t, registry.Register(testSchemaKey, 1, v1))
+	require.NoError(t, registry.Register(testSchemaKey, 2, v2))
+
+	t.Run("latest version is recorded", func(t *testing.T) {
+		// When
+		msg, err := newSchemaTestBuilder(`{"name":"value","age":1}`).
+			SetMetadata(json.RawMessage(`{"trace":"t-1"}`)).
+			SetSchemaRegistry(registry).
+			Build()
+
+		// Then
+		require.NoError(t, err)
+		version, ok := SchemaVersionOf(msg)
+		assert.True(t, ok)
+		assert.Equal(t, 2, version)
+		assert.JSONEq(t, `{"trace":"t-1","schema_version":2}`, string(msg.Metadata))
+	})
+
+	t.Run("builder version is validated", func(t *testing.T) {
+		// When
+		msg, err := newSchemaTestBuilder(`{"name":"value"}`).SetSchemaVersion(1).SetSchemaRegistry(registry).Build()
+
+		// Then
+		require.NoError(t, err)
+		version, ok := SchemaVersionOf(msg)
+		assert.True(t, ok)
+		assert.Equal(t, 1, version)
+	})
+
+	t.Run("recorded version is validated", func(t *testing.T) {
+		// Given
+		msg, err := newSchemaTestBuilder(`{"age":1}`).SetMetadata(json.RawMessage(`{"schema_version":1}`)).Build()
+		require.NoError(t, err)
+
+		// When
+		err = registry.Validate(msg)
+
+		// Then
+		var schemaErr *SchemaError
+		require.ErrorAs(t, err, &schemaErr)
+		assert.Equal(t, 1, schemaErr.Version)
+		assert.ErrorIs(t, err, ErrSchemaViolation)
+	})
+
+	t.Run("unregistered version", func(t *testing.T) {
+		// Given
+		msg, err := newSchemaTestBuilder(`{"name":"value"}`).Build()
+		require.NoError(t, err)
+
+		// When
+		err = registry.ValidateVersion(msg, 3)
+
+		// Then
+		assert.ErrorIs(t, err, ErrSchemaNotFound)
+		_, ok := SchemaVersionOf(msg)
+		assert.False(t, ok)
+	})
+
+	t.Run("invalid builder version", func(t *testing.T) {
+		// When
+		_, err := newSchemaTestBuilder(`{"name":"value"}`).SetSchemaVersion(-1).Build()
+
+		// Then
+		assert.Error(t, err)
+	})
+}
+
+func TestSchemaRegistry_Unregistered(t *testing.T) {
+	t.Run("lenient registry accepts unknown keys", func(t *testing.T) {
+		// When
+		_, err := newSchemaTestBuilder(`{}`).SetSchemaRegistry(NewSchemaRegistry()).Build()
+
+		// Then
+		assert.NoError(t, err)
+	})
+
+	t.Run("strict registry rejects unknown keys", func(t *testing.T) {
+		// When
+		_, err := newSchemaTestBuilder(`{}`).SetSchemaRegistry(NewSchemaRegistry(WithStrictSchemas())).Build()
+
+		// Then
+		assert.ErrorIs(t, err, ErrSchemaNotFound)
+	})
+}
+
+func TestPublisher_WithSchemaRegistry(t *testing.T) {
+	// Given
+	registry := NewSchemaRegistry()
+	require.NoError(t, registry.Register(testSchemaKey, 1, NewStructSchema[testSchemaPayload]()))
+
+	mockExecutor := &MockExecutor{}
+	mockExecutor.On("ExecContext", mock.Anything, mock.Anything, mock.Anything).Return(&MockResult{}, nil)
+
+	publisher, err := NewPublisher(mockExecutor, WithSchemaRegistry(registry))
+	require.NoError(t, err)
+
+	valid, err := newSchemaTestBuilder(`{"name":"value"}`).Build()
+	require.NoError(t, err)
+	invalid, err := newSchemaTestBuilder(`{"name":""}`).Build()
+	require.NoError(t, err)
+
+	// When
+	err = publisher.Publish(context.Background(), valid, invalid)
+
+	// Then
+	assert.ErrorIs(t, err, ErrSchemaViolation)
+	mockExecutor.AssertNotCalled(t, "ExecContext", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPublisher_WithSchemaRegistry_RecordsVersionOnCopy(t *testing.T) {
+	// Given
+	registry := NewSchemaRegistry()
+	require.NoError(t, registry.Register(testSchemaKey, 1, NewStructSchema[testSchemaPayload]()))
+	require.NoError(t, registry.Register(testSchemaKey, 2, NewStructSchema[testSchemaPayload]()))
+
+	executor := &namedExecutor{driver: "sqlite3"}
+	publisher, err := NewPublisher(executor, WithSchemaRegistry(registry))
+	require.NoError(t, err)
+
+	msg, err := newSchemaTestBuilder(`{"name":"value"}`).SetMetadata(json.RawMessage(`{"trace":"t-1"}`)).Build()
+	require.NoError(t, err)
+
+	// When
+	err = publisher.Publish(context.Background(), msg)
+
+	// Then
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"trace":"t-1"}`, string(msg.Metadata))
+	require.Len(t, executor.args, 1)
+	assert.Contains(t, executor.args[0], `{"schema_version":2,"trace":"t-1"}`)
+}
@@ -0,0 +1,128 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// PayloadTransformer rewrites a message before it is stored, such as to encrypt or redact its payload.
+// It is given a copy of the published message, so replacing Payload or Metadata does not affect the caller.
+type PayloadTransformer interface {
+	Transform(ctx context.Context, msg *Message) error
+}
+
+// PayloadTransformerFunc adapts a function to the PayloadTransformer interface
+type PayloadTransformerFunc func(ctx context.Context, msg *Message) error
+
+func (f PayloadTransformerFunc) Transform(ctx context.Context, msg *Message) error {
+	return f(ctx, msg)
+}
+
+// PayloadRestorer reverts a PayloadTransformer on the consumer side, such as to decrypt a payload.
+// Messages the transformer did not change are left as is.
+type PayloadRestorer interface {
+	Restore(ctx context.Context, msg *Message) error
+}
+
+// PayloadRestorerFunc adapts a function to the PayloadRestorer interface
+type PayloadRestorerFunc func(ctx context.Context, msg *Message) error
+
+func (f PayloadRestorerFunc) Restore(ctx context.Context, msg *Message) error {
+	return f(ctx, msg)
+}
+
+// WithPayloadTransformers transforms every message before it is stored, in the given order.
+// Payloads are validated against the schema registry before they are transformed.
+func WithPayloadTransformers(transformers ...PayloadTransformer) PublisherOption {
+	return func(c *publisherConfig) error {
+		for i, t := range transformers {
+			if t == nil {
+				return fmt.Errorf("payload transformer at index %d is nil", i)
+			}
+		}
+		c.transformers = append(c.transformers, transformers...)
+		return nil
+	}
+}
+
+// RestorePayload reverts the transformers of a consumed message.
+// Restorers are given in the order of the transformers and applied in reverse order.
+func RestorePayload(ctx context.Context, msg *Message, restorers ...PayloadRestorer) error {
+	for i := len(restorers) - 1; i >= 0; i-- {
+		if err := restorers[i].Restore(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transform applies the transformers to copies of the messages
+func transform(ctx context.Context, messages []*Message, transformers []PayloadTransformer) ([]*Message, error) {
+	transformed := make([]*Message, len(messages))
+	for i, msg := range messages {
+		clone := *msg
+		for _, t := range transformers {
+			if err := t.Transform(ctx, &clone); err != nil {
+				return nil, fmt.Errorf("failed to transform message at index %d: %w", i, err)
+			}
+		}
+		transformed[i] = &clone
+	}
+	return transformed, nil
+}
+
+// metadataFields decodes the metadata of the message, which must be a JSON object
+func metadataFields(msg *Message) (map[string]json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if len(msg.Metadata) == 0 {
+		return fields, nil
+	}
+	if err := json.Unmarshal(msg.Metadata, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("metadata of message %s is not a JSON object", msg.EventID)
+	}
+	return fields, nil
+}
+
+// MetadataString returns a string field of the metadata of the message
+func MetadataString(msg *Message, key string) (string, bool) {
+	fields, err := metadataFields(msg)
+	if err != nil {
+		return "", false
+	}
+	var value string
+	if raw, ok := fields[key]; !ok || json.Unmarshal(raw, &value) != nil {
+		return "", false
+	}
+	return value, true
+}
+
+// setMetadata sets fields of the metadata of the message; a nil value removes the field
+func setMetadata(msg *Message, values map[string]any) error {
+	fields, err := metadataFields(msg)
+	if err != nil {
+		return err
+	}
+	for key, value := range values {
+		if value == nil {
+			delete(fields, key)
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return &MarshalError{Field: "metadata", Err: err}
+		}
+		fields[key] = raw
+	}
+
+	if len(fields) == 0 {
+		msg.Metadata = nil
+		return nil
+	}
+	metadata, err := json.Marshal(fields)
+	if err != nil {
+		return &MarshalError{Field: "metadata", Err: err}
+	}
+	msg.Metadata = metadata
+	return nil
+}
@@ -0,0 +1,112 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTransformTestMessage(t *testing.T, payload string) *Message {
+	msg, err := NewMessageBuilder().
+		SetEventTopic("customers").
+		SetEventDomain("crm").
+		SetEventType("customer.registered").
+		SetObjectType("customer").
+		SetPayload(json.RawMessage(payload)).
+		SetMetadata(json.RawMessage(`{"tenant":"acme"}`)).
+		Build()
+	require.NoError(t, err)
+	return msg
+}
+
+func TestPublisher_Publish_Transformers(t *testing.T) {
+	t.Run("stores transformed copies", func(t *testing.T) {
+		// Given
+		executor := &namedExecutor{driver: "postgres"}
+		var order []string
+		first := PayloadTransformerFunc(func(_ context.Context, msg *Message) error {
+			order = append(order, "first")
+			msg.Payload = json.RawMessage(`{"step":1}`)
+			return nil
+		})
+		second := PayloadTransformerFunc(func(_ context.Context, msg *Message) error {
+			order = append(order, "second")
+			return setMetadata(msg, map[string]any{"transformed": "yes"})
+		})
+		publisher, err := NewPublisher(executor, WithPayloadTransformers(first, second))
+		require.NoError(t, err)
+		msg := newTransformTestMessage(t, `{"name":"Jane"}`)
+
+		// When
+		err = publisher.Publish(context.Background(), msg)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"first", "second"}, order)
+		require.Len(t, executor.args, 1)
+		assert.JSONEq(t, `{"step":1}`, string(executor.args[0][7].([]byte)))
+		assert.JSONEq(t, `{"tenant":"acme","transformed":"yes"}`, string(executor.args[0][8].([]byte)))
+		assert.JSONEq(t, `{"name":"Jane"}`, string(msg.Payload))
+		assert.JSONEq(t, `{"tenant":"acme"}`, string(msg.Metadata))
+	})
+
+	t.Run("transformer error stops the publish", func(t *testing.T) {
+		// Given
+		executor := &namedExecutor{driver: "postgres"}
+		failing := PayloadTransformerFunc(func(context.Context, *Message) error {
+			return errors.New("key unavailable")
+		})
+		publisher, err := NewPublisher(executor, WithPayloadTransformers(failing))
+		require.NoError(t, err)
+
+		// When
+		err = publisher.Publish(context.Background(), newTransformTestMessage(t, `{}`))
+
+		// Then
+		assert.ErrorContains(t, err, "key unavailable")
+		assert.Empty(t, executor.queries)
+	})
+}
+
+func TestWithPayloadTransformers_Nil(t *testing.T) {
+	_, err := NewPublisher(&MockExecutor{}, WithPayloadTransformers(nil))
+
+	assert.Error(t, err)
+}
+
+func TestRestorePayload(t *testing.T) {
+	// Given
+	var order []string
+	restorer := func(name string) PayloadRestorer {
+		return PayloadRestorerFunc(func(context.Context, *Message) error {
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	// When
+	err := RestorePayload(context.Background(), &Message{}, restorer("first"), restorer("second"))
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"second", "first"}, order)
+}
+
+func TestSetMetadata(t *testing.T) {
+	msg := &Message{Metadata: json.RawMessage(`{"tenant":"acme"}`)}
+
+	require.NoError(t, setMetadata(msg, map[string]any{"key": "k1"}))
+	value, ok := MetadataString(msg, "key")
+	assert.True(t, ok)
+	assert.Equal(t, "k1", value)
+
+	require.NoError(t, setMetadata(msg, map[string]any{"key": nil, "tenant": nil}))
+	assert.Nil(t, msg.Metadata)
+
+	msg.Metadata = json.RawMessage(`[1]`)
+	assert.Error(t, setMetadata(msg, map[string]any{"key": "k1"}))
+}
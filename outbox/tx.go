@@ -0,0 +1,111 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrNoTransaction is returned by a strict transaction publisher when the context carries no
+// transaction, and for messages with an aggregate key when the publisher cannot begin one
+var ErrNoTransaction = errors.New("no transaction in context")
+
+// txContextKey is the context key of the active transaction
+type txContextKey struct{}
+
+// ContextWithTx returns a context carrying the executor of the active transaction,
+// such as *sql.Tx or the executor of an ent transaction.
+func ContextWithTx(ctx context.Context, tx Executor) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// TxFromContext returns the transaction executor carried by ctx
+func TxFromContext(ctx context.Context) (Executor, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(Executor)
+	return tx, ok && tx != nil
+}
+
+// RunInTx runs fn in a transaction of db, such as *sql.DB or entx.DB.
+// The transaction is carried by the context passed to fn, so publishers created
+// with NewTxPublisher write through it. It commits when fn returns nil and rolls back otherwise.
+func RunInTx(ctx context.Context, db TxBeginner, fn func(ctx context.Context) error) (err error) {
+	if db == nil {
+		return fmt.Errorf("db cannot be nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		switch r := recover(); {
+		case r != nil:
+			_ = tx.Rollback()
+			panic(r) // rethrow
+		case err != nil:
+			_ = tx.Rollback()
+		default:
+			if commitErr := tx.Commit(); commitErr != nil {
+				err = fmt.Errorf("failed to commit transaction: %w", commitErr)
+			}
+		}
+	}()
+
+	return fn(ContextWithTx(ctx, tx))
+}
+
+// contextExecutor executes statements through the transaction carried by the context
+type contextExecutor struct {
+	fallback Executor
+	strict   bool
+}
+
+func (e *contextExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.ExecContext(ctx, query, args...)
+	}
+	if e.strict || e.fallback == nil {
+		return nil, ErrNoTransaction
+	}
+	return e.fallback.ExecContext(ctx, query, args...)
+}
+
+func (e *contextExecutor) DriverName() string {
+	if namer, ok := e.fallback.(DriverNamer); ok {
+		return namer.DriverName()
+	}
+	return string(DialectPostgres)
+}
+
+// WithStrictTx makes a transaction publisher fail with ErrNoTransaction when the
+// context carries no transaction, instead of writing through the fallback executor.
+func WithStrictTx() PublisherOption {
+	return func(c *publisherConfig) error {
+		c.strictTx = true
+		return nil
+	}
+}
+
+// NewTxPublisher creates a Publisher writing through the transaction carried by the
+// context of each Publish call (see ContextWithTx and RunInTx).
+//
+// Without a transaction it writes through fallback, which may be nil when WithStrictTx is set.
+// The dialect is detected from fallback. A failed statement aborts a Postgres transaction,
+// so batches written through a transaction are never retried, and batches written through
+// fallback are retried only when WithRetryPolicy is given.
+func NewTxPublisher(fallback Executor, opts ...PublisherOption) (Publisher, error) {
+	executor := &contextExecutor{fallback: fallback}
+
+	pub, err := NewPublisher(executor, append([]PublisherOption{WithRetryPolicy(NoRetry())}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	executor.strict = pub.(*publisher).config.strictTx
+	if fallback == nil && !executor.strict {
+		return nil, fmt.Errorf("fallback executor cannot be nil without strict mode")
+	}
+	return pub, nil
+}
@@ -0,0 +1,158 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTxTestMessage() *Message {
+	return &Message{
+		EventID:     "test-event-1",
+		EventTopic:  "test-topic",
+		EventDomain: "test-domain",
+		EventType:   "test-event",
+		ObjectType:  "test-object",
+		CreatedAt:   time.Now(),
+	}
+}
+
+func TestRunInTx(t *testing.T) {
+	t.Run("publishes in the transaction and commits", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		publisher, err := NewTxPublisher(nil, WithStrictTx())
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_message"`)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// When
+		err = RunInTx(context.Background(), db, func(ctx context.Context) error {
+			return publisher.Publish(ctx, newTxTestMessage())
+		})
+
+		// Then
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rolls back when fn fails", func(t *testing.T) {
+		// Given
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		// When
+		err = RunInTx(context.Background(), db, func(ctx context.Context) error {
+			_, ok := TxFromContext(ctx)
+			assert.True(t, ok)
+			return errors.New("domain error")
+		})
+
+		// Then
+		assert.EqualError(t, err, "domain error")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestNewTxPublisher(t *testing.T) {
+	t.Run("strict mode requires a transaction", func(t *testing.T) {
+		// Given
+		fallback := &namedExecutor{driver: "mysql"}
+		publisher, err := NewTxPublisher(fallback, WithStrictTx())
+		require.NoError(t, err)
+
+		// When
+		err = publisher.Publish(context.Background(), newTxTestMessage())
+
+		// Then
+		assert.ErrorIs(t, err, ErrNoTransaction)
+		assert.Empty(t, fallback.queries)
+	})
+
+	t.Run("falls back outside a transaction", func(t *testing.T) {
+		// Given
+		fallback := &namedExecutor{driver: "mysql"}
+		publisher, err := NewTxPublisher(fallback)
+		require.NoError(t, err)
+
+		// When
+		err = publisher.Publish(context.Background(), newTxTestMessage())
+
+		// Then
+		require.NoError(t, err)
+		require.Len(t, fallback.queries, 1)
+		assert.Contains(t, fallback.queries[0], "INSERT INTO `outbox_message`")
+	})
+
+	t.Run("uses the transaction in context", func(t *testing.T) {
+		// Given
+		fallback := &namedExecutor{driver: "postgres"}
+		tx := &namedExecutor{driver: "postgres"}
+		publisher, err := NewTxPublisher(fallback)
+		require.NoError(t, err)
+
+		// When
+		err = publisher.Publish(ContextWithTx(context.Background(), tx), newTxTestMessage())
+
+		// Then
+		require.NoError(t, err)
+		assert.Len(t, tx.queries, 1)
+		assert.Empty(t, fallback.queries)
+	})
+
+	t.Run("nil fallback without strict mode", func(t *testing.T) {
+		_, err := NewTxPublisher(nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestPublisher_Publish_NoRetryInCallerTx(t *testing.T) {
+	for name, newPublisher := range map[string]func(tx *sql.Tx) (Publisher, error){
+		"tx executor": func(tx *sql.Tx) (Publisher, error) {
+			return NewPublisher(tx, WithMaxRetries(3))
+		},
+		"tx in context": func(*sql.Tx) (Publisher, error) {
+			return NewTxPublisher(nil, WithStrictTx(), WithRetryPolicy(DefaultRetryPolicy(3)))
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Given
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_message"`)).WillReturnError(driver.ErrBadConn)
+			tx, err := db.Begin()
+			require.NoError(t, err)
+			publisher, err := newPublisher(tx)
+			require.NoError(t, err)
+
+			// When
+			err = publisher.Publish(ContextWithTx(context.Background(), tx), newTxTestMessage())
+
+			// Then
+			var publishErr *PublishError
+			require.ErrorAs(t, err, &publishErr)
+			assert.Equal(t, 1, publishErr.Attempts)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
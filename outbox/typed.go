@@ -0,0 +1,122 @@
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	// ErrEventTypeMismatch is returned when a message does not carry the event type declared by the payload type.
+	ErrEventTypeMismatch = errors.New("event type mismatch")
+
+	// ErrObjectTypeMismatch is returned when a message does not carry the object type declared by the payload type.
+	ErrObjectTypeMismatch = errors.New("object type mismatch")
+
+	// ErrEmptyPayload is returned when decoding a message without payload.
+	ErrEmptyPayload = errors.New("payload is empty")
+)
+
+// EventTyper is implemented by payload types that declare their event type.
+type EventTyper interface {
+	EventType() string
+}
+
+// ObjectTyper is implemented by payload types that declare their object type.
+type ObjectTyper interface {
+	ObjectType() string
+}
+
+// NewTypedMessage creates a message carrying payload marshaled as JSON.
+// An empty eventType falls back to the EventType declared by the payload type.
+// The object type is the ObjectType declared by the payload type, or its Go type name.
+func NewTypedMessage[T any](topic, domain, eventType string, payload T) (*Message, error) {
+	builder, err := NewTypedMessageBuilder(topic, domain, eventType, payload)
+	if err != nil {
+		return nil, err
+	}
+	return builder.Build()
+}
+
+// NewTypedMessageBuilder is like NewTypedMessage but returns the builder so
+// that optional fields such as producer or correlation ID can be set.
+func NewTypedMessageBuilder[T any](topic, domain, eventType string, payload T) (*MessageBuilder, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, &MarshalError{Field: "payload", Err: err}
+	}
+
+	declaredEventType, objectType := declaredTypes(&payload)
+	if eventType == "" {
+		eventType = declaredEventType
+	}
+	if objectType == "" {
+		objectType = typeName[T]()
+	}
+
+	return NewMessageBuilder().
+		SetEventTopic(topic).
+		SetEventDomain(domain).
+		SetEventType(eventType).
+		SetObjectType(objectType).
+		SetPayload(data), nil
+}
+
+// DecodePayload unmarshals the message payload into T.
+// If T declares its event type or object type, the message must match them.
+func DecodePayload[T any](msg *Message) (T, error) {
+	var payload T
+
+	if msg == nil {
+		return payload, fmt.Errorf("message cannot be nil")
+	}
+
+	eventType, objectType := declaredTypes(&payload)
+	if eventType != "" && eventType != msg.EventType {
+		return payload, fmt.Errorf("%w: expected '%s', got '%s'", ErrEventTypeMismatch, eventType, msg.EventType)
+	}
+
+	if objectType != "" && objectType != msg.ObjectType {
+		return payload, fmt.Errorf("%w: expected '%s', got '%s'", ErrObjectTypeMismatch, objectType, msg.ObjectType)
+	}
+
+	if len(msg.Payload) == 0 {
+		return payload, ErrEmptyPayload
+	}
+
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		return payload, &MarshalError{Field: "payload", Err: err}
+	}
+	return payload, nil
+}
+
+// declaredTypes returns the event type and object type declared by the payload type.
+// For pointer types a nil payload is replaced by a zero value before calling the methods.
+func declaredTypes[T any](payload *T) (eventType, objectType string) {
+	var v any = payload
+	if t := reflect.TypeOf((*T)(nil)).Elem(); t.Kind() == reflect.Pointer {
+		if rv := reflect.ValueOf(*payload); rv.IsNil() {
+			v = reflect.New(t.Elem()).Interface()
+		} else {
+			v = *payload
+		}
+	}
+
+	if typer, ok := v.(EventTyper); ok {
+		eventType = typer.EventType()
+	}
+	if typer, ok := v.(ObjectTyper); ok {
+		objectType = typer.ObjectType()
+	}
+	return eventType, objectType
+}
+
+// typeName returns the name of T, dereferencing pointer types
+func typeName[T any]() string {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
@@ -0,0 +1,107 @@
+package outbox
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderCreated struct {
+	OrderID string `json:"order_id"`
+	Amount  int    `json:"amount"`
+}
+
+func (o *orderCreated) EventType() string {
+	return "order.created"
+}
+
+func (o *orderCreated) ObjectType() string {
+	return "order"
+}
+
+type untypedPayload struct {
+	Value string `json:"value"`
+}
+
+func TestNewTypedMessage(t *testing.T) {
+	t.Run("declared types", func(t *testing.T) {
+		// When
+		msg, err := NewTypedMessage("test.topic", "test.domain", "", orderCreated{OrderID: "o-1", Amount: 3})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, "order.created", msg.EventType)
+		assert.Equal(t, "order", msg.ObjectType)
+		assert.JSONEq(t, `{"order_id":"o-1","amount":3}`, string(msg.Payload))
+	})
+
+	t.Run("pointer payload", func(t *testing.T) {
+		// When
+		msg, err := NewTypedMessage("test.topic", "test.domain", "", &orderCreated{OrderID: "o-1"})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, "order.created", msg.EventType)
+		assert.Equal(t, "order", msg.ObjectType)
+	})
+
+	t.Run("explicit event type and type name fallback", func(t *testing.T) {
+		// When
+		msg, err := NewTypedMessage("test.topic", "test.domain", "test.type", untypedPayload{Value: "v"})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, "test.type", msg.EventType)
+		assert.Equal(t, "untypedPayload", msg.ObjectType)
+	})
+
+	t.Run("missing event type", func(t *testing.T) {
+		// When
+		_, err := NewTypedMessage("test.topic", "test.domain", "", untypedPayload{})
+
+		// Then
+		assert.ErrorIs(t, err, ErrInvalidEventType)
+	})
+}
+
+func TestDecodePayload(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		// Given
+		msg, err := NewTypedMessage("test.topic", "test.domain", "", orderCreated{OrderID: "o-1", Amount: 3})
+		require.NoError(t, err)
+
+		// When
+		value, valueErr := DecodePayload[orderCreated](msg)
+		pointer, pointerErr := DecodePayload[*orderCreated](msg)
+
+		// Then
+		require.NoError(t, valueErr)
+		require.NoError(t, pointerErr)
+		assert.Equal(t, orderCreated{OrderID: "o-1", Amount: 3}, value)
+		assert.Equal(t, &orderCreated{OrderID: "o-1", Amount: 3}, pointer)
+	})
+
+	t.Run("event type mismatch", func(t *testing.T) {
+		// Given
+		msg, err := NewTypedMessage("test.topic", "test.domain", "order.cancelled", orderCreated{OrderID: "o-1"})
+		require.NoError(t, err)
+
+		// When
+		_, err = DecodePayload[orderCreated](msg)
+
+		// Then
+		assert.ErrorIs(t, err, ErrEventTypeMismatch)
+	})
+
+	t.Run("empty payload", func(t *testing.T) {
+		// Given
+		msg := &Message{EventType: "test.type"}
+
+		// When
+		_, err := DecodePayload[untypedPayload](msg)
+
+		// Then
+		assert.ErrorIs(t, err, ErrEmptyPayload)
+	})
+}
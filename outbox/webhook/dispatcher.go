@@ -0,0 +1,296 @@
+// Package webhook dispatches outbox messages as signed HTTP requests and verifies them on receipt.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/carped99/gosdk/outbox"
+)
+
+// Request headers set on every webhook
+const (
+	HeaderSignature = "Webhook-Signature"
+	HeaderID        = "Webhook-Id"
+)
+
+var (
+	defaultTimeout = 10 * time.Second
+
+	// maxErrorBody bounds the part of an error response kept in StatusError
+	maxErrorBody int64 = 1024
+)
+
+// Format is the body format of a webhook
+type Format int
+
+const (
+	// FormatJSON sends the message as its JSON encoding
+	FormatJSON Format = iota
+	// FormatCloudEvents sends the message as a structured mode CloudEvent
+	FormatCloudEvents
+	// FormatCloudEventsBinary sends the payload with the attributes in Ce- headers
+	FormatCloudEventsBinary
+)
+
+// Doer sends HTTP requests, such as *http.Client
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Endpoint is the receiver of the messages of a topic
+type Endpoint struct {
+	URL    string
+	Secret []byte      // Signs requests with HMAC-SHA256; requests are unsigned when empty, for a Handler without secrets
+	Header http.Header // Extra headers, such as an API key
+}
+
+// StatusError is returned when an endpoint answers with a non-2xx status.
+// 4xx responses other than 408 and 429 are wrapped with outbox.Permanent.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Body       string
+	After      time.Duration // Parsed from the Retry-After header
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("webhook %s answered %d", e.URL, e.StatusCode)
+	}
+	return fmt.Sprintf("webhook %s answered %d: %s", e.URL, e.StatusCode, e.Body)
+}
+
+// RetryAfter returns the wait requested by the endpoint, honored by outbox.RetryAfter
+func (e *StatusError) RetryAfter() time.Duration {
+	return e.After
+}
+
+type config struct {
+	client   Doer
+	format   Format
+	fallback *Endpoint
+}
+
+// Option configures a Dispatcher
+type Option func(*config) error
+
+// WithHTTPClient sets the client sending requests, an *http.Client with a 10s timeout by default
+func WithHTTPClient(client Doer) Option {
+	return func(c *config) error {
+		if client == nil {
+			return fmt.Errorf("http client cannot be nil")
+		}
+		c.client = client
+		return nil
+	}
+}
+
+// WithFormat sets the body format, FormatJSON by default
+func WithFormat(format Format) Option {
+	return func(c *config) error {
+		if format < FormatJSON || format > FormatCloudEventsBinary {
+			return fmt.Errorf("unknown webhook format %d", format)
+		}
+		c.format = format
+		return nil
+	}
+}
+
+// WithDefaultEndpoint sets the receiver of topics without an endpoint
+func WithDefaultEndpoint(endpoint Endpoint) Option {
+	return func(c *config) error {
+		if endpoint.URL == "" {
+			return fmt.Errorf("endpoint url cannot be empty")
+		}
+		c.fallback = &endpoint
+		return nil
+	}
+}
+
+// Dispatcher POSTs outbox messages to the endpoint of their EventTopic.
+// It implements outbox.Dispatcher.
+//
+// Receivers deduplicate by the Webhook-Id header, which carries the EventID.
+type Dispatcher struct {
+	endpoints map[string]Endpoint
+	config    config
+	now       func() time.Time
+}
+
+var _ outbox.Dispatcher = (*Dispatcher)(nil)
+
+// NewDispatcher creates a new Dispatcher sending the messages of each topic to its endpoint
+func NewDispatcher(endpoints map[string]Endpoint, opts ...Option) (*Dispatcher, error) {
+	c := config{
+		client: &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		if err := opt(&c); err != nil {
+			return nil, fmt.Errorf("failed to apply webhook option: %w", err)
+		}
+	}
+
+	copied := make(map[string]Endpoint, len(endpoints))
+	for topic, endpoint := range endpoints {
+		if endpoint.URL == "" {
+			return nil, fmt.Errorf("endpoint url of topic %s cannot be empty", topic)
+		}
+		copied[topic] = endpoint
+	}
+	if len(copied) == 0 && c.fallback == nil {
+		return nil, fmt.Errorf("at least one endpoint is required")
+	}
+
+	return &Dispatcher{endpoints: copied, config: c, now: time.Now}, nil
+}
+
+// Dispatch sends the message and classifies failures:
+// 2xx succeeds, 408, 429 and 5xx are retryable and other 4xx are permanent.
+func (d *Dispatcher) Dispatch(ctx context.Context, msg *outbox.Message) error {
+	req, err := d.Request(ctx, msg)
+	if err != nil {
+		return err
+	}
+
+	resp, err := d.config.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send message %s to %s: %w", msg.EventID, req.URL.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	statusErr := &StatusError{
+		URL:        req.URL.Redacted(),
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(body)),
+		After:      parseRetryAfter(resp.Header.Get("Retry-After"), d.now()),
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return statusErr
+	default:
+		return outbox.Permanent(statusErr)
+	}
+}
+
+// Request builds the signed webhook request of the message
+func (d *Dispatcher) Request(ctx context.Context, msg *outbox.Message) (*http.Request, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("message cannot be nil")
+	}
+
+	endpoint, ok := d.endpoints[msg.EventTopic]
+	if !ok {
+		if d.config.fallback == nil {
+			return nil, outbox.Permanent(fmt.Errorf("no webhook endpoint for topic %s", msg.EventTopic))
+		}
+		endpoint = *d.config.fallback
+	}
+
+	header := make(http.Header)
+	var body []byte
+	var err error
+	switch d.config.format {
+	case FormatCloudEvents:
+		body, err = outbox.MarshalCloudEvent(msg)
+		header.Set("Content-Type", outbox.CloudEventsContentType)
+	case FormatCloudEventsBinary:
+		body, err = outbox.WriteCloudEventHTTP(msg, header)
+	default:
+		body, err = json.Marshal(msg)
+		header.Set("Content-Type", "application/json")
+	}
+	if err != nil {
+		return nil, outbox.Permanent(fmt.Errorf("failed to encode message %s: %w", msg.EventID, err))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, outbox.Permanent(fmt.Errorf("invalid webhook endpoint of topic %s: %w", msg.EventTopic, err))
+	}
+
+	for key, values := range endpoint.Header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set(HeaderID, msg.EventID)
+	if len(endpoint.Secret) > 0 {
+		req.Header.Set(HeaderSignature, Sign(endpoint.Secret, d.now(), body))
+	}
+	return req, nil
+}
+
+// ReadMessage decodes a webhook request body sent in any Format
+func ReadMessage(header http.Header, body []byte) (*outbox.Message, error) {
+	if header.Get("Content-Type") == "application/json" && header.Get("Ce-Specversion") == "" {
+		return outbox.UnmarshalMessage(body)
+	}
+	return outbox.ReadCloudEventHTTP(header, body)
+}
+
+// Handler returns an http.Handler verifying webhooks with secrets and passing the decoded
+// messages to fn. It answers 401 to invalid signatures, 413 to bodies larger than
+// DefaultMaxBodySize, 400 to undecodable bodies and 500 when fn fails, so the sender retries.
+// Without secrets, requests are not verified, as sent to an Endpoint without a Secret.
+func Handler(fn func(ctx context.Context, msg *outbox.Message) error, secrets ...[]byte) http.Handler {
+	return HandlerLimit(fn, DefaultMaxBodySize, secrets...)
+}
+
+// HandlerLimit is like Handler but accepts bodies of at most maxBytes
+func HandlerLimit(fn func(ctx context.Context, msg *outbox.Message) error, maxBytes int64, secrets ...[]byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := VerifyRequestLimit(w, r, maxBytes, secrets...)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		msg, err := ReadMessage(r.Header, body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := fn(r.Context(), msg); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
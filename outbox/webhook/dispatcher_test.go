@@ -0,0 +1,223 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/carped99/gosdk/outbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSecret = []byte("whsec_test")
+
+func newTestMessage(t *testing.T) *outbox.Message {
+	msg, err := outbox.NewMessageBuilder().
+		SetEventTopic("orders").
+		SetEventDomain("shop").
+		SetEventType("order.created").
+		SetObjectType("order").
+		SetProducer("order-service").
+		SetCorrelationID("corr-1").
+		SetPayload(json.RawMessage(`{"order_id":"o-1"}`)).
+		Build()
+	require.NoError(t, err)
+	return msg
+}
+
+// receiver records the messages accepted by Handler
+type receiver struct {
+	mu       sync.Mutex
+	messages []*outbox.Message
+}
+
+func (r *receiver) handle(_ context.Context, msg *outbox.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	for name, format := range map[string]Format{
+		"json":                FormatJSON,
+		"cloud events":        FormatCloudEvents,
+		"binary cloud events": FormatCloudEventsBinary,
+	} {
+		t.Run("delivers "+name+" to a verifying handler", func(t *testing.T) {
+			// Given
+			recv := &receiver{}
+			server := httptest.NewServer(Handler(recv.handle, testSecret))
+			defer server.Close()
+
+			dispatcher, err := NewDispatcher(map[string]Endpoint{
+				"orders": {URL: server.URL, Secret: testSecret},
+			}, WithFormat(format))
+			require.NoError(t, err)
+			msg := newTestMessage(t)
+
+			// When
+			err = dispatcher.Dispatch(context.Background(), msg)
+
+			// Then
+			require.NoError(t, err)
+			require.Len(t, recv.messages, 1)
+			assert.Equal(t, msg.EventID, recv.messages[0].EventID)
+			assert.Equal(t, msg.EventType, recv.messages[0].EventType)
+			assert.Equal(t, msg.CorrelationID, recv.messages[0].CorrelationID)
+			assert.JSONEq(t, string(msg.Payload), string(recv.messages[0].Payload))
+		})
+	}
+
+	t.Run("rejected signature is permanent", func(t *testing.T) {
+		// Given
+		server := httptest.NewServer(Handler((&receiver{}).handle, []byte("other")))
+		defer server.Close()
+
+		dispatcher, err := NewDispatcher(nil, WithDefaultEndpoint(Endpoint{URL: server.URL, Secret: testSecret}))
+		require.NoError(t, err)
+
+		// When
+		err = dispatcher.Dispatch(context.Background(), newTestMessage(t))
+
+		// Then
+		var statusErr *StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusUnauthorized, statusErr.StatusCode)
+		assert.True(t, outbox.IsPermanent(err))
+		assert.False(t, outbox.IsRetryableError(err))
+	})
+
+	t.Run("unsigned request to a handler without secrets", func(t *testing.T) {
+		// Given
+		recv := &receiver{}
+		server := httptest.NewServer(Handler(recv.handle))
+		defer server.Close()
+
+		dispatcher, err := NewDispatcher(map[string]Endpoint{"orders": {URL: server.URL}})
+		require.NoError(t, err)
+
+		// When
+		err = dispatcher.Dispatch(context.Background(), newTestMessage(t))
+
+		// Then
+		require.NoError(t, err)
+		assert.Len(t, recv.messages, 1)
+	})
+
+	t.Run("body over the handler limit is permanent", func(t *testing.T) {
+		// Given
+		server := httptest.NewServer(HandlerLimit((&receiver{}).handle, 64, testSecret))
+		defer server.Close()
+
+		dispatcher, err := NewDispatcher(map[string]Endpoint{"orders": {URL: server.URL, Secret: testSecret}})
+		require.NoError(t, err)
+
+		// When
+		err = dispatcher.Dispatch(context.Background(), newTestMessage(t))
+
+		// Then
+		var statusErr *StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, statusErr.StatusCode)
+		assert.True(t, outbox.IsPermanent(err))
+	})
+
+	t.Run("server error honors Retry-After", func(t *testing.T) {
+		// Given
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "30")
+			http.Error(w, "maintenance", http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		dispatcher, err := NewDispatcher(map[string]Endpoint{"orders": {URL: server.URL}})
+		require.NoError(t, err)
+
+		// When
+		err = dispatcher.Dispatch(context.Background(), newTestMessage(t))
+
+		// Then
+		assert.ErrorContains(t, err, "maintenance")
+		assert.False(t, outbox.IsPermanent(err))
+		after, ok := outbox.RetryAfter(err)
+		assert.True(t, ok)
+		assert.Equal(t, 30*time.Second, after)
+	})
+
+	t.Run("too many requests is retryable", func(t *testing.T) {
+		// Given
+		retryAt := time.Date(2024, 1, 1, 0, 1, 0, 0, time.UTC)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", retryAt.Format(http.TimeFormat))
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		dispatcher, err := NewDispatcher(map[string]Endpoint{"orders": {URL: server.URL}})
+		require.NoError(t, err)
+		dispatcher.now = func() time.Time { return retryAt.Add(-10 * time.Second) }
+
+		// When
+		err = dispatcher.Dispatch(context.Background(), newTestMessage(t))
+
+		// Then
+		assert.True(t, outbox.IsRetryableError(err))
+		after, ok := outbox.RetryAfter(err)
+		assert.True(t, ok)
+		assert.Equal(t, 10*time.Second, after)
+	})
+
+	t.Run("unknown topic", func(t *testing.T) {
+		// Given
+		dispatcher, err := NewDispatcher(map[string]Endpoint{"users": {URL: "http://localhost"}})
+		require.NoError(t, err)
+
+		// When
+		err = dispatcher.Dispatch(context.Background(), newTestMessage(t))
+
+		// Then
+		assert.True(t, outbox.IsPermanent(err))
+	})
+}
+
+func TestDispatcher_Request(t *testing.T) {
+	// Given
+	dispatcher, err := NewDispatcher(map[string]Endpoint{
+		"orders": {URL: "https://example.com/hook", Secret: testSecret, Header: http.Header{"X-Api-Key": {"key"}}},
+	})
+	require.NoError(t, err)
+	msg := newTestMessage(t)
+
+	// When
+	req, err := dispatcher.Request(context.Background(), msg)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodPost, req.Method)
+	assert.Equal(t, msg.EventID, req.Header.Get(HeaderID))
+	assert.Equal(t, "key", req.Header.Get("X-Api-Key"))
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+	body, err := VerifyRequest(req, testSecret)
+	require.NoError(t, err)
+	decoded, err := ReadMessage(req.Header, body)
+	require.NoError(t, err)
+	assert.Equal(t, msg.EventID, decoded.EventID)
+}
+
+func TestNewDispatcher(t *testing.T) {
+	_, err := NewDispatcher(nil)
+	assert.Error(t, err)
+
+	_, err = NewDispatcher(map[string]Endpoint{"orders": {}})
+	assert.Error(t, err)
+
+	_, err = NewDispatcher(map[string]Endpoint{"orders": {URL: "http://localhost"}}, WithFormat(Format(9)))
+	assert.Error(t, err)
+}
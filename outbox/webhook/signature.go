@@ -0,0 +1,132 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTolerance is the maximum age of a signature accepted by VerifyRequest
+const DefaultTolerance = 5 * time.Minute
+
+// DefaultMaxBodySize is the largest body read by VerifyRequest and Handler
+const DefaultMaxBodySize int64 = 1 << 20
+
+// signatureVersion prefixes HMAC-SHA256 signatures in the signature header
+const signatureVersion = "v1"
+
+var (
+	// ErrMissingSignature is returned when a request carries no signature header
+	ErrMissingSignature = errors.New("missing webhook signature")
+
+	// ErrInvalidSignature is returned when no signature matches the body and secrets
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+
+	// ErrSignatureExpired is returned when the signature timestamp is outside the tolerance
+	ErrSignatureExpired = errors.New("webhook signature expired")
+)
+
+// Sign returns the signature header value of body sent at timestamp:
+//
+//	t=<unix seconds>,v1=<hex HMAC-SHA256 of "<unix seconds>.<body>">
+func Sign(secret []byte, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + ts + "," + signatureVersion + "=" + hex.EncodeToString(computeSignature(secret, ts, body))
+}
+
+// Verify checks a signature header value against body.
+// Any of secrets may match, so receivers can rotate secrets without downtime.
+// A tolerance of zero skips the timestamp check.
+func Verify(signature string, body []byte, tolerance time.Duration, secrets ...[]byte) error {
+	if signature == "" {
+		return ErrMissingSignature
+	}
+
+	var (
+		ts         string
+		signatures [][]byte
+	)
+	for _, part := range strings.Split(signature, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			ts = value
+		case signatureVersion:
+			if sig, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, sig)
+			}
+		}
+	}
+	if ts == "" || len(signatures) == 0 {
+		return fmt.Errorf("%w: malformed header", ErrInvalidSignature)
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid timestamp", ErrInvalidSignature)
+	}
+	if tolerance > 0 {
+		if age := time.Since(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+			return ErrSignatureExpired
+		}
+	}
+
+	for _, secret := range secrets {
+		expected := computeSignature(secret, ts, body)
+		for _, sig := range signatures {
+			if hmac.Equal(expected, sig) {
+				return nil
+			}
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// VerifyRequest reads and verifies the body of a webhook request within DefaultTolerance,
+// reading at most DefaultMaxBodySize. It returns the body, which is also restored on the request.
+//
+// Without secrets the request is not verified, matching a Dispatcher sending unsigned
+// requests to an Endpoint without a Secret.
+func VerifyRequest(r *http.Request, secrets ...[]byte) ([]byte, error) {
+	return VerifyRequestLimit(nil, r, DefaultMaxBodySize, secrets...)
+}
+
+// VerifyRequestLimit is like VerifyRequest but reads at most maxBytes, failing with
+// *http.MaxBytesError beyond. The server of w, which may be nil, closes the connection.
+func VerifyRequestLimit(w http.ResponseWriter, r *http.Request, maxBytes int64, secrets ...[]byte) ([]byte, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBodySize
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook body: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(secrets) == 0 {
+		return body, nil
+	}
+	if err := Verify(r.Header.Get(HeaderSignature), body, DefaultTolerance, secrets...); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func computeSignature(secret []byte, ts string, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	secret := []byte("secret")
+	body := []byte(`{"event_id":"e-1"}`)
+
+	tests := []struct {
+		name      string
+		signature string
+		secrets   [][]byte
+		expected  error
+	}{
+		{"valid", Sign(secret, time.Now(), body), [][]byte{secret}, nil},
+		{"rotated secret", Sign(secret, time.Now(), body), [][]byte{[]byte("new"), secret}, nil},
+		{"wrong secret", Sign([]byte("other"), time.Now(), body), [][]byte{secret}, ErrInvalidSignature},
+		{"tampered body", Sign(secret, time.Now(), []byte(`{}`)), [][]byte{secret}, ErrInvalidSignature},
+		{"expired", Sign(secret, time.Now().Add(-time.Hour), body), [][]byte{secret}, ErrSignatureExpired},
+		{"missing", "", [][]byte{secret}, ErrMissingSignature},
+		{"malformed", "v1=zz", [][]byte{secret}, ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.signature, body, DefaultTolerance, tt.secrets...)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	signature := Sign([]byte("secret"), time.Unix(1700000000, 0), []byte("body"))
+
+	assert.True(t, strings.HasPrefix(signature, "t=1700000000,v1="))
+	assert.Len(t, strings.TrimPrefix(signature, "t=1700000000,v1="), 64)
+}
+
+func TestVerifyRequest(t *testing.T) {
+	// Given
+	body := []byte(`{"event_id":"e-1"}`)
+	req := httptest.NewRequest("POST", "/hook", bytes.NewReader(body))
+	req.Header.Set(HeaderSignature, Sign([]byte("secret"), time.Now(), body))
+
+	// When
+	read, err := VerifyRequest(req, []byte("secret"))
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, body, read)
+
+	again := new(bytes.Buffer)
+	_, err = again.ReadFrom(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body, again.Bytes())
+}
+
+func TestVerifyRequestLimit(t *testing.T) {
+	t.Run("body over the limit", func(t *testing.T) {
+		// Given
+		body := []byte(strings.Repeat("x", 64))
+		req := httptest.NewRequest("POST", "/hook", bytes.NewReader(body))
+		req.Header.Set(HeaderSignature, Sign([]byte("secret"), time.Now(), body))
+
+		// When
+		_, err := VerifyRequestLimit(nil, req, 32, []byte("secret"))
+
+		// Then
+		var tooLarge *http.MaxBytesError
+		assert.ErrorAs(t, err, &tooLarge)
+	})
+
+	t.Run("unsigned request without secrets", func(t *testing.T) {
+		// Given
+		body := []byte(`{"event_id":"e-1"}`)
+		req := httptest.NewRequest("POST", "/hook", bytes.NewReader(body))
+
+		// When
+		read, err := VerifyRequestLimit(nil, req, 0)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, body, read)
+	})
+}